@@ -0,0 +1,170 @@
+package RESP
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GedisCaching/Gedis/acl"
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// ------------------------------ ACL Commands ------------------------------
+
+// PerformACL manages the users allowed to connect and their permissions.
+// ACL SETUSER username [rule ...] | ACL GETUSER username | ACL DELUSER username [username ...] |
+// ACL LIST | ACL USERS | ACL WHOAMI | ACL CAT [category] | ACL LOG [count | RESET]
+func PerformACL(c *Client, args []string) string {
+	users := c.handler.acl
+
+	switch strings.ToUpper(args[0]) {
+	case "SETUSER":
+		if len(args) < 2 {
+			return responses.ErrorMsg("wrong number of arguments for 'acl|setuser' command")
+		}
+		if err := users.SetUser(args[1], args[2:]...); err != nil {
+			return responses.ErrorMsg(err.Error())
+		}
+		return responses.StringMsg("OK")
+
+	case "GETUSER":
+		if len(args) != 2 {
+			return responses.ErrorMsg("wrong number of arguments for 'acl|getuser' command")
+		}
+		user := users.GetUser(args[1])
+		if user == nil {
+			return responses.NullMsg(c.protocol)
+		}
+		return responses.MapMsg(c.protocol, []string{
+			responses.BulkStringMsg("flags"), responses.SetMsg(c.protocol, user.Flags),
+			responses.BulkStringMsg("passwords"), responses.ArrayMsg(user.Passwords),
+			responses.BulkStringMsg("commands"), responses.BulkStringMsg(user.Commands),
+			responses.BulkStringMsg("keys"), responses.BulkStringMsg(user.Keys),
+			responses.BulkStringMsg("channels"), responses.BulkStringMsg(user.Channels),
+			responses.BulkStringMsg("selectors"), responses.RawArrayMsg(nil),
+		})
+
+	case "DELUSER":
+		if len(args) < 2 {
+			return responses.ErrorMsg("wrong number of arguments for 'acl|deluser' command")
+		}
+		deleted, err := users.DeleteUsers(args[1:]...)
+		if err != nil {
+			return responses.ErrorMsg(err.Error())
+		}
+		return responses.IntegerMsg(deleted)
+
+	case "LIST":
+		if len(args) != 1 {
+			return responses.ErrorMsg("wrong number of arguments for 'acl|list' command")
+		}
+		return responses.ArrayMsg(users.List())
+
+	case "USERS":
+		if len(args) != 1 {
+			return responses.ErrorMsg("wrong number of arguments for 'acl|users' command")
+		}
+		return responses.ArrayMsg(users.Users())
+
+	case "WHOAMI":
+		if len(args) != 1 {
+			return responses.ErrorMsg("wrong number of arguments for 'acl|whoami' command")
+		}
+		return responses.BulkStringMsg(c.user)
+
+	case "CAT":
+		switch len(args) {
+		case 1:
+			return responses.ArrayMsg(acl.Categories)
+		case 2:
+			return aclCategoryMsg(args[1])
+		default:
+			return responses.ErrorMsg("wrong number of arguments for 'acl|cat' command")
+		}
+
+	case "LOG":
+		if len(args) > 2 {
+			return responses.ErrorMsg("wrong number of arguments for 'acl|log' command")
+		}
+		count := 10
+		if len(args) == 2 {
+			if strings.ToUpper(args[1]) == "RESET" {
+				users.ResetLog()
+				return responses.StringMsg("OK")
+			}
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 0 {
+				return responses.ErrorMsg("value is out of range, must be positive")
+			}
+			count = n
+		}
+		return aclLogMsg(c, users.LogEntries(count))
+
+	default:
+		return responses.ErrorMsg(fmt.Sprintf("unknown subcommand '%s'. Try ACL HELP.", args[0]))
+	}
+}
+
+// aclCategoryMsg formats the ACL CAT reply listing the commands of a category
+func aclCategoryMsg(category string) string {
+	category = strings.ToLower(category)
+
+	if !acl.IsCategory(category) {
+		return responses.ErrorMsg(fmt.Sprintf("Unknown category '%s'", category))
+	}
+
+	inCategory := func(categories []string) bool {
+		for _, c := range categories {
+			if category == "all" || c == category {
+				return true
+			}
+		}
+		return false
+	}
+
+	var names []string
+	for _, cmd := range Commands() {
+		name := strings.ToLower(cmd.Name)
+		if inCategory(cmd.Categories()) {
+			names = append(names, name)
+			continue
+		}
+
+		// The subcommands with their own flags are listed on their own, like client|kill
+		subcommands := make([]string, 0, len(cmd.SubcommandFlags))
+		for subcommand := range cmd.SubcommandFlags {
+			subcommands = append(subcommands, subcommand)
+		}
+		sort.Strings(subcommands)
+		for _, subcommand := range subcommands {
+			if inCategory(cmd.CategoriesOf(subcommand)) {
+				names = append(names, name+"|"+subcommand)
+			}
+		}
+	}
+	return responses.ArrayMsg(names)
+}
+
+// aclLogMsg formats the ACL LOG reply, one map per denied request, newest first
+func aclLogMsg(c *Client, entries []acl.LogEntry) string {
+	now := time.Now()
+
+	frames := make([]string, len(entries))
+	for i, entry := range entries {
+		frames[i] = responses.MapMsg(c.protocol, []string{
+			responses.BulkStringMsg("count"), responses.IntegerMsg(entry.Count),
+			responses.BulkStringMsg("reason"), responses.BulkStringMsg(entry.Reason),
+			responses.BulkStringMsg("context"), responses.BulkStringMsg(entry.Context),
+			responses.BulkStringMsg("object"), responses.BulkStringMsg(entry.Object),
+			responses.BulkStringMsg("username"), responses.BulkStringMsg(entry.Username),
+			responses.BulkStringMsg("age-seconds"), responses.DoubleMsg(c.protocol, now.Sub(entry.Created).Seconds()),
+			responses.BulkStringMsg("client-info"), responses.BulkStringMsg(entry.ClientInfo),
+			responses.BulkStringMsg("entry-id"), responses.IntegerMsg(entry.EntryID),
+			responses.BulkStringMsg("timestamp-created"), responses.IntegerMsg(int(entry.Created.UnixMilli())),
+			responses.BulkStringMsg("timestamp-last-updated"), responses.IntegerMsg(int(entry.LastUpdated.UnixMilli())),
+		})
+	}
+	return responses.RawArrayMsg(frames)
+}
@@ -0,0 +1,259 @@
+package RESP
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/GedisCaching/Gedis/acl"
+	responses "github.com/GedisCaching/Gedis/responses"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// nextClientID hands out a unique id to every connection
+var nextClientID atomic.Int64
+
+// outputQueueSize is the number of replies and push messages queued for a client.
+// A subscriber falling that far behind its messages is disconnected.
+const outputQueueSize = 1024
+
+// Client holds the state of a single network connection.
+// Every command received on the connection runs against its selected database,
+// which is shared with the embedded API of the same server.
+type Client struct {
+	handler *Handler
+
+	// db is the database selected with SELECT out of dbs, dbIndex its number.
+	// Inside EXEC they are the locked views of the databases.
+	dbs     *storage.Databases
+	db      *storage.Database
+	dbIndex int
+
+	// id uniquely identifies the connection for the lifetime of the process
+	id int64
+
+	// protocol is the RESP version negotiated with HELLO, RESP2 until then
+	protocol int
+
+	// name is set with HELLO SETNAME
+	name string
+
+	// authenticated is set once the client sent the right password,
+	// clients connecting while authentication is disabled start authenticated
+	authenticated bool
+
+	// user is the ACL user the client authenticated as
+	user string
+
+	// tx holds the commands queued since MULTI, nil outside a transaction
+	tx *transaction
+
+	// watches holds the keys watched with WATCH, by index of the database they were watched in
+	watches map[int]*storage.Watch
+
+	// monitor is set once the client ran MONITOR
+	monitor atomic.Bool
+
+	// channels and patterns the client subscribed to, guarded by the Pub/Sub lock
+	channels map[string]struct{}
+	patterns map[string]struct{}
+
+	// output queues the frames to write to the connection, replies and push messages
+	// alike, so they are sent in the order they were produced
+	output chan string
+
+	// done is closed once the connection must end, killed tells whether it was forced
+	done      chan struct{}
+	closeOnce sync.Once
+	killed    atomic.Bool
+
+	// infoMu guards what other connections read about this one with CLIENT LIST:
+	// the fields below along with protocol, name, user and dbIndex, which are only written under it
+	infoMu sync.Mutex
+
+	// addr and laddr are the remote and local addresses of the connection, see SetAddr
+	addr  string
+	laddr string
+
+	// created is when the connection was accepted,
+	// lastInteraction and lastCommand tell when it last sent a command and which one
+	created         time.Time
+	lastInteraction time.Time
+	lastCommand     string
+}
+
+// newClient creates the state of a new connection served by handler
+func newClient(handler *Handler) *Client {
+	now := time.Now()
+	c := &Client{
+		handler:         handler,
+		dbs:             handler.dbs,
+		db:              handler.dbs.DB(0),
+		id:              nextClientID.Add(1),
+		protocol:        responses.RESP2,
+		authenticated:   !handler.requiresAuth(),
+		user:            acl.DefaultUser,
+		channels:        make(map[string]struct{}),
+		patterns:        make(map[string]struct{}),
+		output:          make(chan string, outputQueueSize),
+		done:            make(chan struct{}),
+		created:         now,
+		lastInteraction: now,
+	}
+	handler.addClient(c)
+	return c
+}
+
+// DB returns the database the client's commands run against
+func (c *Client) DB() *storage.Database {
+	return c.db
+}
+
+// selectDB switches the client to the database at index, reporting false when there is none
+func (c *Client) selectDB(index int) bool {
+	db := c.dbs.DB(index)
+	if db == nil {
+		return false
+	}
+
+	c.infoMu.Lock()
+	defer c.infoMu.Unlock()
+	c.db, c.dbIndex = db, index
+	return true
+}
+
+// ID returns the unique id of the connection
+func (c *Client) ID() int64 {
+	return c.id
+}
+
+// Protocol returns the RESP version used for the replies sent to the client
+func (c *Client) Protocol() int {
+	return c.protocol
+}
+
+// Name returns the name the client gave to the connection
+func (c *Client) Name() string {
+	return c.name
+}
+
+// SetAddr records the remote and local addresses of the connection, shown by CLIENT LIST
+func (c *Client) SetAddr(addr, laddr string) {
+	c.infoMu.Lock()
+	defer c.infoMu.Unlock()
+	c.addr, c.laddr = addr, laddr
+}
+
+// Addr returns the remote address of the connection
+func (c *Client) Addr() string {
+	c.infoMu.Lock()
+	defer c.infoMu.Unlock()
+	return c.addr
+}
+
+// Close releases the state of the connection once it is closed:
+// its watched keys, subscriptions and monitor feed, and its entry in the client list
+func (c *Client) Close() {
+	c.unwatch()
+	c.handler.pubsub.unsubscribeAll(c)
+	c.handler.monitors.remove(c)
+	c.handler.removeClient(c)
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// Kill asks for the connection to be closed, without sending the queued output.
+// It can be called from any goroutine.
+func (c *Client) Kill() {
+	c.killed.Store(true)
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+// Killed reports whether Kill was called
+func (c *Client) Killed() bool {
+	return c.killed.Load()
+}
+
+// Done is closed when the connection must end, after Close or Kill
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Output delivers the frames to write to the connection, in order:
+// the replies given to Send and the messages published to the client's subscriptions
+func (c *Client) Output() <-chan string {
+	return c.output
+}
+
+// Send queues a reply for the connection, waiting while the queue is full.
+// It reports false once the connection is done.
+func (c *Client) Send(reply string) bool {
+	select {
+	case c.output <- reply:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+// push queues a push message without waiting, a client that cannot keep up is killed
+func (c *Client) push(msg string) {
+	select {
+	case c.output <- msg:
+	default:
+		c.Kill()
+	}
+}
+
+// User returns the name of the ACL user the client is authenticated as
+func (c *Client) User() string {
+	return c.user
+}
+
+// setName names the connection
+func (c *Client) setName(name string) {
+	c.infoMu.Lock()
+	defer c.infoMu.Unlock()
+	c.name = name
+}
+
+// recordCommand records the command the client is running, shown by CLIENT LIST
+func (c *Client) recordCommand(command string, args []string) {
+	name := strings.ToLower(command)
+	if cmd := LookupCommand(command); cmd != nil && cmd.Subcommands && len(args) > 0 {
+		name += "|" + strings.ToLower(args[0])
+	}
+
+	c.infoMu.Lock()
+	defer c.infoMu.Unlock()
+	c.lastInteraction = time.Now()
+	c.lastCommand = name
+}
+
+// subscriptionCounts returns the number of channels and patterns the client subscribed to,
+// it can be called from any goroutine
+func (c *Client) subscriptionCounts() (channels int, patterns int) {
+	c.handler.pubsub.mu.RLock()
+	defer c.handler.pubsub.mu.RUnlock()
+	return len(c.channels), len(c.patterns)
+}
+
+// info describes the connection as CLIENT INFO does, it is also used in the ACL LOG
+func (c *Client) info() string {
+	channels, patterns := c.subscriptionCounts()
+	flags := "N"
+	if channels+patterns > 0 {
+		flags = "P"
+	} else if c.monitor.Load() {
+		flags = "O"
+	}
+
+	c.infoMu.Lock()
+	defer c.infoMu.Unlock()
+
+	now := time.Now()
+	return fmt.Sprintf("id=%d addr=%s laddr=%s name=%s age=%d idle=%d flags=%s db=%d sub=%d psub=%d oll=%d cmd=%s user=%s resp=%d",
+		c.id, c.addr, c.laddr, c.name, int(now.Sub(c.created).Seconds()), int(now.Sub(c.lastInteraction).Seconds()),
+		flags, c.dbIndex, channels, patterns, len(c.output), c.lastCommand, c.user, c.protocol)
+}
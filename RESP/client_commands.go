@@ -0,0 +1,196 @@
+package RESP
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// ------------------------------ Client Commands ------------------------------
+
+// PerformClient inspects and manages the client connections.
+// CLIENT ID | INFO | LIST [TYPE normal|pubsub] [ID id ...] | SETNAME name | GETNAME |
+// KILL addr | KILL [ID id] [ADDR addr] [LADDR addr] [USER username] [SKIPME yes|no]
+func PerformClient(c *Client, args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "ID":
+		if len(args) != 1 {
+			return responses.ErrorMsg("wrong number of arguments for 'client|id' command")
+		}
+		return responses.IntegerMsg(int(c.id))
+
+	case "INFO":
+		if len(args) != 1 {
+			return responses.ErrorMsg("wrong number of arguments for 'client|info' command")
+		}
+		return responses.BulkStringMsg(c.info() + "\n")
+
+	case "LIST":
+		return clientList(c, args[1:])
+
+	case "SETNAME":
+		if len(args) != 2 {
+			return responses.ErrorMsg("wrong number of arguments for 'client|setname' command")
+		}
+		if strings.ContainsAny(args[1], " \n") {
+			return responses.ErrorMsg("Client names cannot contain spaces, newlines or special characters.")
+		}
+		c.setName(args[1])
+		return responses.StringMsg("OK")
+
+	case "GETNAME":
+		if len(args) != 1 {
+			return responses.ErrorMsg("wrong number of arguments for 'client|getname' command")
+		}
+		if c.name == "" {
+			return responses.NullMsg(c.protocol)
+		}
+		return responses.BulkStringMsg(c.name)
+
+	case "KILL":
+		return clientKill(c, args[1:])
+
+	default:
+		return responses.ErrorMsg(fmt.Sprintf("unknown subcommand '%s'. Try CLIENT HELP.", args[0]))
+	}
+}
+
+// clientList replies with one CLIENT INFO line per connection matching the filters
+func clientList(c *Client, args []string) string {
+	kind := ""
+	var ids map[int64]bool
+	for len(args) > 0 {
+		switch strings.ToUpper(args[0]) {
+		case "TYPE":
+			if len(args) < 2 {
+				return responses.ErrorMsg("syntax error")
+			}
+			kind = strings.ToLower(args[1])
+			if kind != "normal" && kind != "pubsub" {
+				return responses.ErrorMsg(fmt.Sprintf("Unknown client type '%s'", args[1]))
+			}
+			args = args[2:]
+		case "ID":
+			if len(args) < 2 {
+				return responses.ErrorMsg("syntax error")
+			}
+			ids = make(map[int64]bool)
+			for args = args[1:]; len(args) > 0; args = args[1:] {
+				id, err := strconv.ParseInt(args[0], 10, 64)
+				if err != nil || id <= 0 {
+					return responses.ErrorMsg("Invalid client ID")
+				}
+				ids[id] = true
+			}
+		default:
+			return responses.ErrorMsg("syntax error")
+		}
+	}
+
+	var sb strings.Builder
+	for _, client := range c.handler.Clients() {
+		if ids != nil && !ids[client.id] {
+			continue
+		}
+		if kind != "" {
+			channels, patterns := client.subscriptionCounts()
+			if (kind == "pubsub") != (channels+patterns > 0) {
+				continue
+			}
+		}
+		sb.WriteString(client.info())
+		sb.WriteString("\n")
+	}
+	return responses.BulkStringMsg(sb.String())
+}
+
+// clientFilter selects the connections closed by CLIENT KILL, empty fields match any connection
+type clientFilter struct {
+	id     int64
+	addr   string
+	laddr  string
+	user   string
+	skipMe bool
+}
+
+func (f *clientFilter) matches(self, client *Client) bool {
+	if f.skipMe && client == self {
+		return false
+	}
+	if f.id != 0 && client.id != f.id {
+		return false
+	}
+
+	client.infoMu.Lock()
+	defer client.infoMu.Unlock()
+	return (f.addr == "" || client.addr == f.addr) &&
+		(f.laddr == "" || client.laddr == f.laddr) &&
+		(f.user == "" || client.user == f.user)
+}
+
+// clientKill closes the connections selected by the arguments of CLIENT KILL.
+// The old form with a single address replies OK, the filter form the number of connections closed.
+func clientKill(c *Client, args []string) string {
+	if len(args) == 0 {
+		return responses.ErrorMsg("wrong number of arguments for 'client|kill' command")
+	}
+
+	if len(args) == 1 {
+		filter := &clientFilter{addr: args[0]}
+		if killClients(c, filter) == 0 {
+			return responses.ErrorMsg("No such client")
+		}
+		return responses.StringMsg("OK")
+	}
+
+	if len(args)%2 != 0 {
+		return responses.ErrorMsg("syntax error")
+	}
+	filter := &clientFilter{skipMe: true}
+	for i := 0; i < len(args); i += 2 {
+		value := args[i+1]
+		switch strings.ToUpper(args[i]) {
+		case "ID":
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || id <= 0 {
+				return responses.ErrorMsg("client-id should be greater than 0")
+			}
+			filter.id = id
+		case "ADDR":
+			filter.addr = value
+		case "LADDR":
+			filter.laddr = value
+		case "USER":
+			if c.handler.acl.GetUser(value) == nil {
+				return responses.ErrorMsg(fmt.Sprintf("No such user '%s'", value))
+			}
+			filter.user = value
+		case "SKIPME":
+			switch strings.ToLower(value) {
+			case "yes":
+				filter.skipMe = true
+			case "no":
+				filter.skipMe = false
+			default:
+				return responses.ErrorMsg("syntax error")
+			}
+		default:
+			return responses.ErrorMsg("syntax error")
+		}
+	}
+	return responses.IntegerMsg(killClients(c, filter))
+}
+
+// killClients closes the connections matching filter and returns how many there were
+func killClients(c *Client, filter *clientFilter) int {
+	killed := 0
+	for _, client := range c.handler.Clients() {
+		if !client.Killed() && filter.matches(c, client) {
+			client.Kill()
+			killed++
+		}
+	}
+	return killed
+}
@@ -0,0 +1,326 @@
+package RESP
+
+import (
+	"sort"
+	"strings"
+)
+
+// CommandHandler executes a command for a client, args exclude the command name.
+// Handlers are only called once the number of arguments matches the command arity.
+type CommandHandler func(c *Client, args []string) string
+
+// Command flags describing how a command behaves
+const (
+	FlagWrite    = "write"    // may modify the dataset
+	FlagReadonly = "readonly" // only reads the dataset
+	FlagAdmin    = "admin"    // administrative command
+	FlagFast     = "fast"     // runs in constant or logarithmic time
+	FlagNoAuth   = "no_auth"  // can run before the client authenticated
+	FlagNoMulti  = "no_multi" // cannot be queued in a transaction
+	FlagPubSub   = "pubsub"   // Pub/Sub related command
+	FlagBlocking = "blocking" // may block the client until data is available
+	FlagDenyOOM  = "denyoom"  // may add data, refused once the memory limit is reached
+)
+
+// Command describes a command the server understands
+type Command struct {
+	// Name of the command, in upper case
+	Name string
+
+	// Arity is the number of arguments including the command name.
+	// A negative arity -N means at least N arguments.
+	Arity int
+
+	// Flags describing the command, see the Flag constants
+	Flags []string
+
+	// Positions of the key arguments, the command name being position 0:
+	// the first key, the last key (negative counts from the end) and the step between keys.
+	// A FirstKey of 0 means the command takes no key.
+	FirstKey int
+	LastKey  int
+	Step     int
+
+	// Subcommands is set when the first argument selects a subcommand, like ACL SETUSER.
+	// ACL rules can allow or deny each subcommand separately.
+	Subcommands bool
+
+	// SubcommandFlags replaces Flags for the subcommands that behave differently from the rest
+	// of the command, by lower case name, like CLIENT KILL being an administrative command
+	SubcommandFlags map[string][]string
+
+	// Group the command belongs to, like "string" or "list"
+	Group string
+
+	// Summary is a one line description of the command
+	Summary string
+
+	// Handler executes the command
+	Handler CommandHandler
+}
+
+// HasFlag reports whether the command carries the given flag
+func (cmd *Command) HasFlag(flag string) bool {
+	return hasFlag(cmd.Flags, flag)
+}
+
+// FlagsOf returns the flags of a call of the command with the given lower case subcommand,
+// those of the command itself unless SubcommandFlags overrides them
+func (cmd *Command) FlagsOf(subcommand string) []string {
+	if flags, overridden := cmd.SubcommandFlags[subcommand]; overridden {
+		return flags
+	}
+	return cmd.Flags
+}
+
+func hasFlag(flags []string, flag string) bool {
+	for _, f := range flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckArity reports whether argc arguments, including the command name,
+// are accepted by the command
+func (cmd *Command) CheckArity(argc int) bool {
+	if cmd.Arity >= 0 {
+		return argc == cmd.Arity
+	}
+	return argc >= -cmd.Arity
+}
+
+// Keys returns the key arguments of a call, args excluding the command name
+func (cmd *Command) Keys(args []string) []string {
+	if cmd.FirstKey == 0 {
+		return nil
+	}
+
+	last := cmd.LastKey
+	if last < 0 {
+		last = len(args) + 1 + last
+	}
+
+	var keys []string
+	for i := cmd.FirstKey; i <= last && i <= len(args); i += cmd.Step {
+		keys = append(keys, args[i-1])
+	}
+	return keys
+}
+
+// groupCategories maps command groups to the ACL category of the same commands
+var groupCategories = map[string]string{
+	"string":       "string",
+	"list":         "list",
+	"hash":         "hash",
+	"sorted-set":   "sortedset",
+	"generic":      "keyspace",
+	"connection":   "connection",
+	"transactions": "transaction",
+	"pubsub":       "pubsub",
+	"scripting":    "scripting",
+}
+
+// Categories returns the ACL categories of the command, derived from its flags and group
+func (cmd *Command) Categories() []string {
+	return cmd.CategoriesOf("")
+}
+
+// CategoriesOf returns the ACL categories of a call of the command with the given lower case subcommand,
+// derived from the flags of the subcommand and the group of the command
+func (cmd *Command) CategoriesOf(subcommand string) []string {
+	flags := cmd.FlagsOf(subcommand)
+
+	var categories []string
+	if hasFlag(flags, FlagWrite) {
+		categories = append(categories, "write")
+	}
+	if hasFlag(flags, FlagReadonly) {
+		categories = append(categories, "read")
+	}
+	if hasFlag(flags, FlagAdmin) {
+		categories = append(categories, "admin", "dangerous")
+	}
+	if hasFlag(flags, FlagFast) {
+		categories = append(categories, "fast")
+	} else {
+		categories = append(categories, "slow")
+	}
+	if hasFlag(flags, FlagBlocking) {
+		categories = append(categories, "blocking")
+	}
+	if category, ok := groupCategories[cmd.Group]; ok {
+		categories = append(categories, category)
+	}
+	return categories
+}
+
+// commandTable holds every command, by upper case name
+var commandTable = map[string]*Command{}
+
+func init() {
+	for _, cmd := range []*Command{
+		// Connection commands
+		{Name: "PING", Arity: -1, Flags: []string{FlagFast, FlagNoAuth}, Group: "connection",
+			Summary: "Returns the server's liveliness response.", Handler: PerformPong},
+		{Name: "HELLO", Arity: -1, Flags: []string{FlagFast, FlagNoAuth}, Group: "connection",
+			Summary: "Handshakes with the server, negotiating the protocol version.", Handler: PerformHello},
+		{Name: "AUTH", Arity: -2, Flags: []string{FlagFast, FlagNoAuth}, Group: "connection",
+			Summary: "Authenticates the connection.", Handler: PerformAuth},
+		{Name: "CLIENT", Arity: -2, Subcommands: true, SubcommandFlags: map[string][]string{"kill": {FlagAdmin}, "list": {FlagAdmin}}, Group: "connection",
+			Summary: "Inspects and manages the client connections.", Handler: PerformClient},
+		{Name: "SELECT", Arity: 2, Flags: []string{FlagFast}, Group: "connection",
+			Summary: "Changes the selected database.", Handler: PerformSelect},
+
+		// String commands
+		{Name: "SET", Arity: -3, Flags: []string{FlagWrite, FlagDenyOOM}, FirstKey: 1, LastKey: 1, Step: 1, Group: "string",
+			Summary: "Sets the string value of a key, with an optional expiry.", Handler: PerformSet},
+		{Name: "GET", Arity: 2, Flags: []string{FlagReadonly, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "string",
+			Summary: "Returns the string value of a key.", Handler: PerformGet},
+		{Name: "GETDEL", Arity: 2, Flags: []string{FlagWrite, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "string",
+			Summary: "Returns the string value of a key after deleting the key.", Handler: PerformGETDEL},
+		{Name: "INCR", Arity: 2, Flags: []string{FlagWrite, FlagDenyOOM, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "string",
+			Summary: "Increments the integer value of a key by one.", Handler: PerformIncr},
+		{Name: "DECR", Arity: 2, Flags: []string{FlagWrite, FlagDenyOOM, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "string",
+			Summary: "Decrements the integer value of a key by one.", Handler: PerformDecr},
+
+		// Generic key commands
+		{Name: "DEL", Arity: -2, Flags: []string{FlagWrite}, FirstKey: 1, LastKey: -1, Step: 1, Group: "generic",
+			Summary: "Deletes one or more keys.", Handler: PerformDel},
+		{Name: "EXISTS", Arity: -2, Flags: []string{FlagReadonly, FlagFast}, FirstKey: 1, LastKey: -1, Step: 1, Group: "generic",
+			Summary: "Determines whether one or more keys exist.", Handler: PerformExists},
+		{Name: "TTL", Arity: 2, Flags: []string{FlagReadonly, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "generic",
+			Summary: "Returns the expiration time in seconds of a key.", Handler: PerformTTL},
+		{Name: "EXPIRE", Arity: 3, Flags: []string{FlagWrite, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "generic",
+			Summary: "Sets the expiration time of a key in seconds.", Handler: PerformExpire},
+		{Name: "RENAME", Arity: 3, Flags: []string{FlagWrite}, FirstKey: 1, LastKey: 2, Step: 1, Group: "generic",
+			Summary: "Renames a key.", Handler: PerformRename},
+		{Name: "MOVE", Arity: 3, Flags: []string{FlagWrite, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "generic",
+			Summary: "Moves a key to another database.", Handler: PerformMove},
+
+		// List commands
+		{Name: "LPUSH", Arity: -3, Flags: []string{FlagWrite, FlagDenyOOM, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "list",
+			Summary: "Prepends one or more elements to a list.", Handler: PerformLPush},
+		{Name: "RPUSH", Arity: -3, Flags: []string{FlagWrite, FlagDenyOOM, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "list",
+			Summary: "Appends one or more elements to a list.", Handler: PerformRPush},
+		{Name: "LPOP", Arity: 2, Flags: []string{FlagWrite, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "list",
+			Summary: "Removes and returns the first element of a list.", Handler: PerformLPop},
+		{Name: "RPOP", Arity: 2, Flags: []string{FlagWrite, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "list",
+			Summary: "Removes and returns the last element of a list.", Handler: PerformRPop},
+		{Name: "LLEN", Arity: 2, Flags: []string{FlagReadonly, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "list",
+			Summary: "Returns the length of a list.", Handler: PerformLLen},
+		{Name: "LRANGE", Arity: 4, Flags: []string{FlagReadonly}, FirstKey: 1, LastKey: 1, Step: 1, Group: "list",
+			Summary: "Returns a range of elements from a list.", Handler: PerformLRange},
+		{Name: "LSET", Arity: 4, Flags: []string{FlagWrite, FlagDenyOOM}, FirstKey: 1, LastKey: 1, Step: 1, Group: "list",
+			Summary: "Sets the value of an element in a list by its index.", Handler: PerformLSet},
+		{Name: "BLPOP", Arity: -3, Flags: []string{FlagWrite, FlagBlocking}, FirstKey: 1, LastKey: -2, Step: 1, Group: "list",
+			Summary: "Removes and returns the first element of the first non-empty list, blocking until one is available.", Handler: PerformBLPop},
+		{Name: "BRPOP", Arity: -3, Flags: []string{FlagWrite, FlagBlocking}, FirstKey: 1, LastKey: -2, Step: 1, Group: "list",
+			Summary: "Removes and returns the last element of the first non-empty list, blocking until one is available.", Handler: PerformBRPop},
+		{Name: "BLMOVE", Arity: 6, Flags: []string{FlagWrite, FlagDenyOOM, FlagBlocking}, FirstKey: 1, LastKey: 2, Step: 1, Group: "list",
+			Summary: "Pops an element from a list, pushes it to another list and returns it, blocking until one is available.", Handler: PerformBLMove},
+
+		// Hash commands
+		{Name: "HSET", Arity: -4, Flags: []string{FlagWrite, FlagDenyOOM, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "hash",
+			Summary: "Creates or modifies the value of fields in a hash.", Handler: PerformHSet},
+		{Name: "HGET", Arity: 3, Flags: []string{FlagReadonly, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "hash",
+			Summary: "Returns the value of a field in a hash.", Handler: PerformHGet},
+		{Name: "HDEL", Arity: -3, Flags: []string{FlagWrite, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "hash",
+			Summary: "Deletes one or more fields from a hash.", Handler: PerformHDel},
+		{Name: "HGETALL", Arity: 2, Flags: []string{FlagReadonly}, FirstKey: 1, LastKey: 1, Step: 1, Group: "hash",
+			Summary: "Returns all fields and values in a hash.", Handler: PerformHGetAll},
+		{Name: "HKEYS", Arity: 2, Flags: []string{FlagReadonly}, FirstKey: 1, LastKey: 1, Step: 1, Group: "hash",
+			Summary: "Returns all fields in a hash.", Handler: PerformHKeys},
+		{Name: "HVALS", Arity: 2, Flags: []string{FlagReadonly}, FirstKey: 1, LastKey: 1, Step: 1, Group: "hash",
+			Summary: "Returns all values in a hash.", Handler: PerformHVals},
+		{Name: "HLEN", Arity: 2, Flags: []string{FlagReadonly, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "hash",
+			Summary: "Returns the number of fields in a hash.", Handler: PerformHLen},
+
+		// Sorted set commands
+		{Name: "ZADD", Arity: -4, Flags: []string{FlagWrite, FlagDenyOOM, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "sorted-set",
+			Summary: "Adds one or more members to a sorted set, or updates their scores.", Handler: PerformZAdd},
+		{Name: "ZRANGE", Arity: -4, Flags: []string{FlagReadonly}, FirstKey: 1, LastKey: 1, Step: 1, Group: "sorted-set",
+			Summary: "Returns members in a sorted set within a range of indexes.", Handler: PerformZRange},
+		{Name: "ZRANK", Arity: 3, Flags: []string{FlagReadonly, FlagFast}, FirstKey: 1, LastKey: 1, Step: 1, Group: "sorted-set",
+			Summary: "Returns the index of a member in a sorted set ordered by ascending scores.", Handler: PerformZRank},
+
+		// Transaction commands
+		{Name: "MULTI", Arity: 1, Flags: []string{FlagFast}, Group: "transactions",
+			Summary: "Starts a transaction.", Handler: PerformMulti},
+		{Name: "EXEC", Arity: 1, Group: "transactions",
+			Summary: "Executes all commands in a transaction.", Handler: PerformExec},
+		{Name: "DISCARD", Arity: 1, Flags: []string{FlagFast}, Group: "transactions",
+			Summary: "Discards a transaction.", Handler: PerformDiscard},
+		{Name: "WATCH", Arity: -2, Flags: []string{FlagFast}, FirstKey: 1, LastKey: -1, Step: 1, Group: "transactions",
+			Summary: "Monitors changes to keys to determine the execution of a transaction.", Handler: PerformWatch},
+		{Name: "UNWATCH", Arity: 1, Flags: []string{FlagFast}, Group: "transactions",
+			Summary: "Forgets about watched keys of a transaction.", Handler: PerformUnwatch},
+
+		// Pub/Sub commands
+		{Name: "SUBSCRIBE", Arity: -2, Flags: []string{FlagPubSub, FlagNoMulti}, Group: "pubsub",
+			Summary: "Listens for messages published to channels.", Handler: PerformSubscribe},
+		{Name: "PSUBSCRIBE", Arity: -2, Flags: []string{FlagPubSub, FlagNoMulti}, Group: "pubsub",
+			Summary: "Listens for messages published to channels that match one or more patterns.", Handler: PerformPSubscribe},
+		{Name: "UNSUBSCRIBE", Arity: -1, Flags: []string{FlagPubSub, FlagNoMulti}, Group: "pubsub",
+			Summary: "Stops listening to messages posted to channels.", Handler: PerformUnsubscribe},
+		{Name: "PUNSUBSCRIBE", Arity: -1, Flags: []string{FlagPubSub, FlagNoMulti}, Group: "pubsub",
+			Summary: "Stops listening to messages published to channels that match one or more patterns.", Handler: PerformPUnsubscribe},
+		{Name: "PUBLISH", Arity: 3, Flags: []string{FlagPubSub, FlagFast}, Group: "pubsub",
+			Summary: "Posts a message to a channel.", Handler: PerformPublish},
+		{Name: "PUBSUB", Arity: -2, Flags: []string{FlagPubSub}, Subcommands: true, Group: "pubsub",
+			Summary: "Inspects the state of the Pub/Sub subsystem.", Handler: PerformPubSub},
+
+		// Scripting commands
+		{Name: "FCALL", Arity: -3, Flags: []string{FlagWrite, FlagDenyOOM}, Group: "scripting",
+			Summary: "Invokes a function registered from Go.", Handler: PerformFCall},
+		{Name: "FCALL_RO", Arity: -3, Flags: []string{FlagReadonly}, Group: "scripting",
+			Summary: "Invokes a read-only function registered from Go.", Handler: PerformFCallRO},
+		{Name: "FUNCTION", Arity: -2, Subcommands: true, Group: "scripting",
+			Summary: "Lists the functions registered from Go.", Handler: PerformFunction},
+
+		// Server commands
+		{Name: "ACL", Arity: -2, Flags: []string{FlagAdmin}, Subcommands: true, SubcommandFlags: map[string][]string{"whoami": {}}, Group: "server",
+			Summary: "Manages the users and their permissions.", Handler: PerformACL},
+		{Name: "COMMAND", Arity: -1, Subcommands: true, Group: "server",
+			Summary: "Returns detailed information about the commands.", Handler: PerformCommand},
+		{Name: "CONFIG", Arity: -2, Flags: []string{FlagAdmin, FlagNoMulti}, Subcommands: true, Group: "server",
+			Summary: "Reads and changes the configuration parameters of the server.", Handler: PerformConfig},
+		{Name: "SWAPDB", Arity: 3, Flags: []string{FlagWrite, FlagAdmin, FlagFast}, Group: "server",
+			Summary: "Swaps two databases.", Handler: PerformSwapDB},
+		{Name: "FLUSHDB", Arity: -1, Flags: []string{FlagWrite, FlagAdmin}, Group: "server",
+			Summary: "Removes all keys from the selected database.", Handler: PerformFlushDB},
+		{Name: "FLUSHALL", Arity: -1, Flags: []string{FlagWrite, FlagAdmin}, Group: "server",
+			Summary: "Removes all keys from all databases.", Handler: PerformFlushAll},
+		{Name: "INFO", Arity: -1, Group: "server",
+			Summary: "Returns information and statistics about the server.", Handler: PerformInfo},
+		{Name: "SLOWLOG", Arity: -2, Flags: []string{FlagAdmin}, Subcommands: true, Group: "server",
+			Summary: "Reads and resets the log of the commands slower than a threshold.", Handler: PerformSlowlog},
+		{Name: "MONITOR", Arity: 1, Flags: []string{FlagAdmin, FlagNoMulti}, Group: "server",
+			Summary: "Streams every command processed by the server.", Handler: PerformMonitor},
+		{Name: "SHUTDOWN", Arity: -1, Flags: []string{FlagAdmin, FlagNoMulti}, Group: "server",
+			Summary: "Saves the database when persistence is configured, then shuts down the server.", Handler: PerformShutdown},
+		{Name: "HELP", Arity: -1, Flags: []string{FlagFast}, Group: "server",
+			Summary: "Returns the help text of a command.", Handler: PerformHelp},
+	} {
+		commandTable[cmd.Name] = cmd
+	}
+}
+
+// LookupCommand returns the command with the given name, case insensitive,
+// or nil if the server doesn't know it
+func LookupCommand(name string) *Command {
+	return commandTable[strings.ToUpper(name)]
+}
+
+// Commands returns every command known to the server, ordered by name
+func Commands() []*Command {
+	commands := make([]*Command, 0, len(commandTable))
+	for _, cmd := range commandTable {
+		commands = append(commands, cmd)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands
+}
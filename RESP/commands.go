@@ -1,48 +1,39 @@
 package RESP
 
 import (
-	"fmt"
+	"math"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	responses "github.com/GedisCaching/Gedis/responses"
 )
 
-type StoreItem struct {
-	Value  string
-	Expiry time.Time
-	Mutex  sync.Mutex
-}
-
-// Example usage of the store to avoid unused variable error
-var store = make(map[string]*StoreItem)
-
-func SET(key string, value string) {
-	store[key] = &StoreItem{
-		Value: value,
+// PerformPong replies PONG, or echoes the message given as argument
+func PerformPong(c *Client, args []string) string {
+	// Subscribed RESP2 clients get the reply in the format of a message
+	if c.protocol == responses.RESP2 && c.subscribed() && len(args) <= 1 {
+		message := ""
+		if len(args) == 1 {
+			message = args[0]
+		}
+		return responses.ArrayMsg([]string{"pong", message})
 	}
-}
 
-func SET_WITH_Expiry(key string, value string, expiry time.Time) {
-	store[key] = &StoreItem{
-		Value:  value,
-		Expiry: expiry,
+	switch len(args) {
+	case 0:
+		return responses.StringMsg("PONG")
+	case 1:
+		return responses.BulkStringMsg(args[0])
+	default:
+		return responses.ErrorMsg("wrong number of arguments for 'PING' command")
 	}
 }
 
-func PerformPong(args []string) string {
-	return responses.StringMsg("PONG")
-}
-
-func PerformSet(args []string) string {
-	if len(args) < 2 {
-		return responses.ErrorMsg("invalid syntax provided to 'SET'")
-	}
-
-	var exp time.Time
-	key, val := &args[0], &args[1]
+// PerformSet stores a string value, with an optional expiry in seconds (EX) or milliseconds (PX)
+func PerformSet(c *Client, args []string) string {
+	var exp time.Duration
+	key, val := args[0], args[1]
 
 	if len(args) > 2 {
 		position := 2
@@ -50,219 +41,158 @@ func PerformSet(args []string) string {
 			switch strings.ToLower(args[position]) {
 			// milliseconds
 			case "px":
-				if len(args) < position+1 {
-					return responses.ErrorMsg("no time provided to 'PX'")
+				if len(args) <= position+1 {
+					return responses.ErrorMsg("syntax error")
 				}
-				expMillis, err := strconv.Atoi(string(args[position+1]))
+				expMillis, err := strconv.Atoi(args[position+1])
 				if err != nil {
-					return responses.ErrorMsg("invalid format provided to 'PX'")
+					return responses.ErrorMsg("value is not an integer or out of range")
+				}
+				// A duration past the range of time.Duration would wrap around to a negative one
+				if expMillis <= 0 || int64(expMillis) > math.MaxInt64/int64(time.Millisecond) {
+					return responses.ErrorMsg("invalid expire time in 'set' command")
 				}
 
-				exp = time.Now().Add(time.Millisecond * time.Duration(expMillis))
+				exp = time.Millisecond * time.Duration(expMillis)
 				position += 2
 
 			// seconds
 			case "ex":
-				if len(args) < position+1 {
-					return responses.ErrorMsg("no time provided to 'EX'")
+				if len(args) <= position+1 {
+					return responses.ErrorMsg("syntax error")
 				}
-				expSeconds, err := strconv.Atoi(string(args[position+1]))
+				expSeconds, err := strconv.Atoi(args[position+1])
 				if err != nil {
-					return responses.ErrorMsg("invalid format provided to 'EX'")
+					return responses.ErrorMsg("value is not an integer or out of range")
+				}
+				if expSeconds <= 0 || int64(expSeconds) > math.MaxInt64/int64(time.Second) {
+					return responses.ErrorMsg("invalid expire time in 'set' command")
 				}
 
-				exp = time.Now().Add(time.Second * time.Duration(expSeconds))
+				exp = time.Second * time.Duration(expSeconds)
 				position += 2
 			default:
-				return responses.ErrorMsg(fmt.Sprintf("invalid argument '%s'", args[position]))
+				return responses.ErrorMsg("syntax error")
 			}
 		}
 	}
+
 	// If no expiry is set, set the value without expiry
-	if exp == (time.Time{}) {
-		SET(*key, *val)
+	if exp == 0 {
+		c.db.Set(key, val)
 	} else {
-		SET_WITH_Expiry(*key, *val, time.Now().Add(time.Second*time.Duration(exp.Unix())))
+		c.db.SetWithExpiry(key, val, exp)
 	}
 	return responses.StringMsg("OK")
 }
 
 // PerformGet retrieves a value from the database,
 // if it exists and is not expired. If it is expired, it will be deleted
-func PerformGet(args []string) string {
-	if len(args) < 1 {
-		return responses.ErrorMsg("no value provided to 'GET'")
-	}
-
-	item, exists := store[args[0]]
-
-	if !exists || item == nil {
-		return responses.ErrorMsg(fmt.Sprintf("no value found for key '%s'", args[0]))
+func PerformGet(c *Client, args []string) string {
+	value, exists := c.db.Get(args[0])
+	if !exists {
+		if c.db.Type(args[0]) != "none" {
+			return responses.WrongTypeMsg()
+		}
+		return responses.NullMsg(c.protocol)
 	}
-
-	// Enforce mutual exclusion on the expiry operation and retrieval
-	item.Mutex.Lock()
-	defer item.Mutex.Unlock()
-
-	now := time.Now()
-	if !item.Expiry.IsZero() && item.Expiry.Before(now) {
-		delete(store, args[0])
-		return responses.ErrorMsg(fmt.Sprintf("no value found for key '%s'", args[0]))
+	if !isStringValue(value) {
+		return responses.WrongTypeMsg()
 	}
 
-	return responses.StringMsg(item.Value)
+	return responses.BulkStringMsg(formatValue(value))
 }
 
-// PerformDel deletes a value from the database
-// if it exists. If it does not exist, it returns an error message
-func PerformDel(args []string) string {
-	if len(args) < 1 {
-		return responses.ErrorMsg("no value provided to 'DEL'")
-	}
-
-	item, exists := store[args[0]]
-	if exists {
-		item.Mutex.Lock()
-		defer item.Mutex.Unlock()
-		delete(store, args[0])
-		return responses.StringMsg("The key has been deleted")
+// PerformDel deletes one or more keys
+// and returns the number of keys that were removed
+func PerformDel(c *Client, args []string) string {
+	deleted := 0
+	for _, key := range args {
+		if c.db.Delete(key) {
+			deleted++
+		}
 	}
-
-	return responses.ErrorMsg(fmt.Sprintf("no value found for key '%s'", args[0]))
+	return responses.IntegerMsg(deleted)
 }
 
-func PerformExists(args []string) string {
-	if len(args) < 1 {
-		return responses.ErrorMsg("no value provided to 'EXISTS'")
-	}
-	answer := PerformGet(args)
-	if strings.Contains(answer, "no value found") {
-		return responses.StringMsg("False")
+// PerformExists returns how many of the given keys exist,
+// a key given several times is counted several times
+func PerformExists(c *Client, args []string) string {
+	count := 0
+	for _, key := range args {
+		if c.db.Type(key) != "none" {
+			count++
+		}
 	}
-	return responses.StringMsg("True")
+	return responses.IntegerMsg(count)
 }
 
-// PerformTTL returns the remaining time to live for a key in seconds
-func PerformTTL(args []string) string {
-	if len(args) != 1 {
-		return responses.ErrorMsg("wrong number of arguments for 'TTL' command")
-	}
-
+// PerformTTL returns the remaining time to live for a key in seconds,
+// -1 if the key has no expiry, or -2 if the key doesn't exist
+func PerformTTL(c *Client, args []string) string {
 	key := args[0]
-	remaining, exists := store[key]
+	remaining, exists := c.db.TTL(key)
 	if !exists {
-		return responses.ErrorMsg(fmt.Sprintf("no value found for key '%s'", key))
-	}
-
-	// Enforce mutual exclusion on the expiry operation and retrieval
-	remaining.Mutex.Lock()
-	defer remaining.Mutex.Unlock()
-
-	// If the key has no expiry, return 0
-	if remaining.Expiry.IsZero() {
-		return responses.StringMsg("0")
+		if c.db.Type(key) != "none" {
+			// Sorted sets are kept apart from the expiring keys and never expire
+			return responses.IntegerMsg(-1)
+		}
+		return responses.IntegerMsg(-2)
 	}
 
-	now := time.Now()
-	// If the key is expired, delete it and return -2
-	if remaining.Expiry.Before(now) {
-		delete(store, key)
-		return responses.StringMsg("-2")
+	// If the key has no expiry, return -1
+	if remaining == 0 {
+		return responses.IntegerMsg(-1)
 	}
 
-	ttl := remaining.Expiry.Sub(now).Seconds()
-	return responses.StringMsg(fmt.Sprintf("%d", int(ttl)))
+	return responses.IntegerMsg(int(remaining.Round(time.Second).Seconds()))
 }
 
 // PerformGETDEL retrieves a value and deletes it in a single operation
-func PerformGETDEL(args []string) string {
-	if len(args) != 1 {
-		return responses.ErrorMsg("wrong number of arguments for 'GETDEL' command")
-	}
-
+func PerformGETDEL(c *Client, args []string) string {
 	key := args[0]
-	item, exists := store[key]
+	value, exists := c.db.GETDEL(key)
 	if !exists {
-		return responses.ErrorMsg(fmt.Sprintf("no value found for key '%s'", key))
-	}
-
-	// Enforce mutual exclusion on the expiry operation and retrieval
-	item.Mutex.Lock()
-	defer item.Mutex.Unlock()
-
-	now := time.Now()
-	// If the key is expired, delete it and return -2
-	if !item.Expiry.IsZero() && item.Expiry.Before(now) {
-		delete(store, key)
-		return responses.StringMsg("-2")
+		if !hasType(c, key, "string") {
+			return responses.WrongTypeMsg()
+		}
+		return responses.NullMsg(c.protocol)
 	}
 
-	value := item.Value
-	delete(store, key)
-	return responses.StringMsg(value)
+	return responses.BulkStringMsg(formatValue(value))
 }
 
 // PerformRename renames a key to a new key
-func PerformRename(args []string) string {
-	if len(args) != 2 {
-		return responses.ErrorMsg("wrong number of arguments for 'RENAME' command")
-	}
-
+func PerformRename(c *Client, args []string) string {
 	oldKey := args[0]
 	newKey := args[1]
 
-	item, exists := store[oldKey]
-	if !exists {
-		return responses.ErrorMsg(fmt.Sprintf("no value found for key '%s'", oldKey))
+	if c.db.Type(oldKey) == "none" {
+		return responses.ErrorMsg("no such key")
 	}
 
-	// Enforce mutual exclusion on the expiry operation and retrieval
-	item.Mutex.Lock()
-	defer item.Mutex.Unlock()
-
-	store[newKey] = item
-	delete(store, oldKey)
+	if err := c.db.RENAME(oldKey, newKey); err != nil {
+		return responses.ErrorMsg(err.Error())
+	}
 
 	return responses.StringMsg("OK")
 }
 
-// PerformExpire sets an expiry time for a key
-func PerformExpire(args []string) string {
-	if len(args) != 2 {
-		return responses.ErrorMsg("wrong number of arguments for 'EXPIRE' command")
-	}
-
+// PerformExpire sets an expiry time for a key in seconds, a zero or negative timeout deletes it,
+// and returns 1 if the timeout was set, 0 if the key doesn't exist
+func PerformExpire(c *Client, args []string) string {
 	key := args[0]
 	expirySeconds, err := strconv.Atoi(args[1])
 	if err != nil {
-		return responses.ErrorMsg("invalid expiry time provided")
+		return responses.ErrorMsg("value is not an integer or out of range")
 	}
-
-	item, exists := store[key]
-	if !exists {
-		return responses.ErrorMsg(fmt.Sprintf("no value found for key '%s'", key))
+	if int64(expirySeconds) > math.MaxInt64/int64(time.Second) {
+		return responses.ErrorMsg("invalid expire time in 'expire' command")
 	}
 
-	// Enforce mutual exclusion on the expiry operation and retrieval
-	item.Mutex.Lock()
-	defer item.Mutex.Unlock()
-
-	item.Expiry = time.Now().Add(time.Second * time.Duration(expirySeconds))
-	return responses.StringMsg("OK")
-}
-
-func WatchCommands(args []string) string {
-	if len(args) > 1 {
-		return responses.ErrorMsg("no arguments expected for 'WATCH' command")
-	}
-
-	if len(args) == 1 {
-		if value, exists := Mapping[args[0]]; exists {
-			return value
-		}
-		return "UNWATCH: is a function that stops watching keys for changes."
+	if err := c.db.DEXPIRE(key, time.Second*time.Duration(expirySeconds)); err != nil {
+		return responses.IntegerMsg(0)
 	}
 
-	url := "watchCommandsPageUrl"
-	return responses.StringMsg("Fetching watch commands from: " + url)
+	return responses.IntegerMsg(1)
 }
@@ -0,0 +1,81 @@
+package RESP
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// ------------------------------ Config Commands ------------------------------
+
+// PerformConfig reads and changes the parameters of the server.
+// CONFIG GET pattern [pattern ...] | CONFIG SET parameter value [parameter value ...] | CONFIG REWRITE
+func PerformConfig(c *Client, args []string) string {
+	config := c.handler.config
+
+	switch strings.ToUpper(args[0]) {
+	case "GET":
+		if len(args) < 2 {
+			return responses.ErrorMsg("wrong number of arguments for 'config|get' command")
+		}
+		if config == nil {
+			return responses.ErrorMsg("CONFIG is not available without a server")
+		}
+
+		patterns := make([]string, len(args)-1)
+		for i, pattern := range args[1:] {
+			patterns[i] = strings.ToLower(pattern)
+		}
+		values := config.ConfigGet(patterns...)
+
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		frames := make([]string, 0, len(names)*2)
+		for _, name := range names {
+			frames = append(frames, responses.BulkStringMsg(name), responses.BulkStringMsg(values[name]))
+		}
+		return responses.MapMsg(c.protocol, frames)
+
+	case "SET":
+		if len(args) < 3 || len(args)%2 == 0 {
+			return responses.ErrorMsg("wrong number of arguments for 'config|set' command")
+		}
+		if config == nil {
+			return responses.ErrorMsg("CONFIG is not available without a server")
+		}
+
+		values := make(map[string]string, len(args)/2)
+		for i := 1; i < len(args); i += 2 {
+			name := strings.ToLower(args[i])
+			if _, duplicate := values[name]; duplicate {
+				return responses.ErrorMsg(fmt.Sprintf("CONFIG SET failed - duplicate parameter '%s'", name))
+			}
+			values[name] = args[i+1]
+		}
+		if err := config.ConfigSet(values); err != nil {
+			return responses.ErrorMsg("CONFIG SET failed - " + err.Error())
+		}
+		return responses.StringMsg("OK")
+
+	case "REWRITE":
+		if len(args) != 1 {
+			return responses.ErrorMsg("wrong number of arguments for 'config|rewrite' command")
+		}
+		if config == nil {
+			return responses.ErrorMsg("CONFIG is not available without a server")
+		}
+		if err := config.ConfigRewrite(); err != nil {
+			return responses.ErrorMsg(err.Error())
+		}
+		return responses.StringMsg("OK")
+
+	default:
+		return responses.ErrorMsg(fmt.Sprintf("unknown subcommand '%s'. Try CONFIG HELP.", args[0]))
+	}
+}
@@ -0,0 +1,124 @@
+package RESP
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/GedisCaching/Gedis/acl"
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// Version is the server version reported to clients
+const Version = "1.0.0"
+
+// ------------------------------ Connection Commands ------------------------------
+
+// PerformHello negotiates the protocol version of the connection,
+// optionally authenticating and naming it, and replies with the server properties.
+// HELLO [protover [AUTH username password] [SETNAME clientname]]
+func PerformHello(c *Client, args []string) string {
+	proto := c.protocol
+
+	if len(args) > 0 {
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			return responses.ErrorMsg("Protocol version is not an integer or out of range")
+		}
+		if version != responses.RESP2 && version != responses.RESP3 {
+			return responses.ErrorCodeMsg("NOPROTO", "unsupported protocol version")
+		}
+		proto = version
+	}
+
+	var name, username, password string
+	setName, auth := false, false
+
+	for position := 1; position < len(args); {
+		switch strings.ToUpper(args[position]) {
+		case "AUTH":
+			if len(args) <= position+2 {
+				return responses.ErrorMsg("syntax error")
+			}
+			username, password = args[position+1], args[position+2]
+			auth = true
+			position += 3
+		case "SETNAME":
+			if len(args) <= position+1 {
+				return responses.ErrorMsg("syntax error")
+			}
+			name = args[position+1]
+			if strings.ContainsAny(name, " \n") {
+				return responses.ErrorMsg("Client names cannot contain spaces, newlines or special characters.")
+			}
+			setName = true
+			position += 2
+		default:
+			return responses.ErrorMsg("syntax error in HELLO option '" + args[position] + "'")
+		}
+	}
+
+	// The options are only applied once they are all valid, starting with AUTH which may still fail
+	if auth {
+		if errMsg := c.authenticate(username, password); errMsg != "" {
+			return errMsg
+		}
+	}
+	if !c.authenticated && c.handler.requiresAuth() {
+		return responses.ErrorCodeMsg("NOAUTH", "HELLO must be called with the client already authenticated, otherwise the HELLO <proto> AUTH <user> <pass> option can be used to authenticate the client and select the RESP protocol version at the same time")
+	}
+
+	c.infoMu.Lock()
+	c.protocol = proto
+	if setName {
+		c.name = name
+	}
+	c.infoMu.Unlock()
+
+	return responses.MapMsg(c.protocol, []string{
+		responses.BulkStringMsg("server"), responses.BulkStringMsg("gedis"),
+		responses.BulkStringMsg("version"), responses.BulkStringMsg(Version),
+		responses.BulkStringMsg("proto"), responses.IntegerMsg(c.protocol),
+		responses.BulkStringMsg("id"), responses.IntegerMsg(int(c.id)),
+		responses.BulkStringMsg("mode"), responses.BulkStringMsg("standalone"),
+		responses.BulkStringMsg("role"), responses.BulkStringMsg("master"),
+		responses.BulkStringMsg("modules"), responses.RawArrayMsg(nil),
+	})
+}
+
+// PerformAuth authenticates the connection.
+// AUTH [username] password
+func PerformAuth(c *Client, args []string) string {
+	if len(args) > 2 {
+		return responses.ErrorMsg("syntax error")
+	}
+
+	if len(args) == 1 {
+		// The single argument form authenticates the default user,
+		// which is pointless while it doesn't need a password
+		if !c.handler.requiresAuth() {
+			return responses.ErrorMsg("AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?")
+		}
+		args = []string{acl.DefaultUser, args[0]}
+	}
+
+	if errMsg := c.authenticate(args[0], args[1]); errMsg != "" {
+		return errMsg
+	}
+	return responses.StringMsg("OK")
+}
+
+// authenticate checks the credentials sent by the client against the ACL users
+// and marks it authenticated as that user.
+// It returns an error reply, or an empty string on success.
+func (c *Client) authenticate(username string, password string) string {
+	if !c.handler.acl.Authenticate(username, password) {
+		c.handler.acl.Log(acl.ReasonAuth, "toplevel", "AUTH", username, c.info())
+		return responses.ErrorCodeMsg("WRONGPASS", "invalid username-password pair or user is disabled.")
+	}
+
+	c.infoMu.Lock()
+	c.user = username
+	c.infoMu.Unlock()
+	c.authenticated = true
+	return ""
+}
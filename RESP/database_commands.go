@@ -0,0 +1,98 @@
+package RESP
+
+import (
+	"strconv"
+	"strings"
+
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// ------------------------------ Database Commands ------------------------------
+
+// PerformSelect switches the connection to the database with the given index.
+// SELECT index
+func PerformSelect(c *Client, args []string) string {
+	index, err := strconv.Atoi(args[0])
+	if err != nil {
+		return responses.ErrorMsg("value is not an integer or out of range")
+	}
+	if !c.selectDB(index) {
+		return responses.ErrorMsg("DB index is out of range")
+	}
+	return responses.StringMsg("OK")
+}
+
+// PerformMove moves a key with its expiry to another database.
+// It returns 1 if the key was moved, 0 if it doesn't exist or the destination already holds it.
+// MOVE key db
+func PerformMove(c *Client, args []string) string {
+	index, err := strconv.Atoi(args[1])
+	if err != nil {
+		return responses.ErrorMsg("value is not an integer or out of range")
+	}
+	dest := c.dbs.DB(index)
+	if dest == nil {
+		return responses.ErrorMsg("DB index is out of range")
+	}
+
+	moved, err := c.db.Move(args[0], dest)
+	if err != nil {
+		return responses.ErrorMsg(err.Error())
+	}
+	if moved {
+		return responses.IntegerMsg(1)
+	}
+	return responses.IntegerMsg(0)
+}
+
+// PerformSwapDB swaps the keys of two databases atomically:
+// the clients connected to one of them immediately see the keys of the other.
+// SWAPDB index1 index2
+func PerformSwapDB(c *Client, args []string) string {
+	first, err := strconv.Atoi(args[0])
+	if err != nil || c.dbs.DB(first) == nil {
+		return responses.ErrorMsg("invalid first DB index")
+	}
+	second, err := strconv.Atoi(args[1])
+	if err != nil || c.dbs.DB(second) == nil {
+		return responses.ErrorMsg("invalid second DB index")
+	}
+
+	if err := c.dbs.Swap(first, second); err != nil {
+		return responses.ErrorMsg(err.Error())
+	}
+	return responses.StringMsg("OK")
+}
+
+// PerformFlushDB deletes every key of the selected database.
+// FLUSHDB [ASYNC | SYNC]
+func PerformFlushDB(c *Client, args []string) string {
+	if !validFlushMode(args) {
+		return responses.ErrorMsg("syntax error")
+	}
+	c.db.Flush()
+	return responses.StringMsg("OK")
+}
+
+// PerformFlushAll deletes every key of every database.
+// FLUSHALL [ASYNC | SYNC]
+func PerformFlushAll(c *Client, args []string) string {
+	if !validFlushMode(args) {
+		return responses.ErrorMsg("syntax error")
+	}
+	c.dbs.FlushAll()
+	return responses.StringMsg("OK")
+}
+
+// validFlushMode checks the optional ASYNC or SYNC argument of FLUSHDB and FLUSHALL.
+// Both flush the same way: the keys are unlinked at once, their memory is freed by the garbage collector.
+func validFlushMode(args []string) bool {
+	if len(args) == 0 {
+		return true
+	}
+	if len(args) > 1 {
+		return false
+	}
+	mode := strings.ToUpper(args[0])
+	return mode == "ASYNC" || mode == "SYNC"
+}
@@ -0,0 +1,145 @@
+package RESP
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/GedisCaching/Gedis/glob"
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// ------------------------------ Function Commands ------------------------------
+
+// PerformFCall runs a function registered from Go, atomically.
+// FCALL function numkeys [key [key ...]] [arg [arg ...]]
+func PerformFCall(c *Client, args []string) string {
+	return fcall(c, args, false)
+}
+
+// PerformFCallRO runs a function flagged no-writes.
+// FCALL_RO function numkeys [key [key ...]] [arg [arg ...]]
+func PerformFCallRO(c *Client, args []string) string {
+	return fcall(c, args, true)
+}
+
+func fcall(c *Client, args []string, readOnly bool) string {
+	numKeys, err := strconv.Atoi(args[1])
+	if err != nil {
+		return responses.ErrorMsg("value is not an integer or out of range")
+	}
+	if numKeys < 0 {
+		return responses.ErrorMsg("Number of keys can't be negative")
+	}
+	if numKeys > len(args)-2 {
+		return responses.ErrorMsg("Number of keys can't be greater than number of args")
+	}
+	keys, fnArgs := args[2:2+numKeys], args[2+numKeys:]
+
+	fn := c.handler.functions.lookup(args[0])
+	if fn == nil {
+		return responses.ErrorMsg(ErrUnknownFunction.Error())
+	}
+	write := !fn.HasFlag(FunctionNoWrites)
+	if readOnly && write {
+		return responses.ErrorMsg("Can not execute a script with write flag using *_ro command.")
+	}
+
+	// The keys are only known once numkeys is parsed, and a function without
+	// the no-writes flag needs write access to them
+	if errMsg := checkKeyPermissions(c, keys, write); errMsg != "" {
+		return errMsg
+	}
+
+	result, err := runFunction(c.db, fn, keys, fnArgs)
+	if err != nil {
+		return responses.ErrorMsg(err.Error())
+	}
+	return functionResultMsg(c, result)
+}
+
+// functionResultMsg converts the result of a function into a RESP reply
+func functionResultMsg(c *Client, result interface{}) string {
+	switch v := result.(type) {
+	case nil:
+		return responses.NullMsg(c.protocol)
+	case int:
+		return responses.IntegerMsg(v)
+	case int64:
+		return responses.IntegerMsg(int(v))
+	case bool:
+		if v {
+			return responses.IntegerMsg(1)
+		}
+		return responses.IntegerMsg(0)
+	case []string:
+		return responses.ArrayMsg(v)
+	case []interface{}:
+		frames := make([]string, len(v))
+		for i, element := range v {
+			frames[i] = functionResultMsg(c, element)
+		}
+		return responses.RawArrayMsg(frames)
+	default:
+		return responses.BulkStringMsg(formatValue(v))
+	}
+}
+
+// PerformFunction inspects the functions registered from Go.
+// FUNCTION LIST [LIBRARYNAME pattern]
+func PerformFunction(c *Client, args []string) string {
+	switch strings.ToUpper(args[0]) {
+	case "LIST":
+		pattern := "*"
+		rest := args[1:]
+		for len(rest) > 0 {
+			if strings.ToUpper(rest[0]) != "LIBRARYNAME" || len(rest) < 2 {
+				return responses.ErrorMsg("syntax error")
+			}
+			pattern, rest = rest[1], rest[2:]
+		}
+		return functionListMsg(c, pattern)
+
+	default:
+		return responses.ErrorMsg(fmt.Sprintf("unknown subcommand '%s'. Try FUNCTION HELP.", args[0]))
+	}
+}
+
+// functionListMsg formats the FUNCTION LIST reply: one entry per library,
+// with its name, engine and functions
+func functionListMsg(c *Client, pattern string) string {
+	var libraries []string
+	var current string
+	var functions []string
+
+	flush := func() {
+		if current == "" {
+			return
+		}
+		libraries = append(libraries, responses.MapMsg(c.protocol, []string{
+			responses.BulkStringMsg("library_name"), responses.BulkStringMsg(current),
+			responses.BulkStringMsg("engine"), responses.BulkStringMsg("GO"),
+			responses.BulkStringMsg("functions"), responses.RawArrayMsg(functions),
+		}))
+		functions = nil
+	}
+
+	// The functions are sorted by library, so each library is a run of functions
+	for _, fn := range c.handler.functions.list() {
+		if !glob.Match(pattern, fn.Library) {
+			continue
+		}
+		if fn.Library != current {
+			flush()
+			current = fn.Library
+		}
+		functions = append(functions, responses.MapMsg(c.protocol, []string{
+			responses.BulkStringMsg("name"), responses.BulkStringMsg(fn.Name),
+			responses.BulkStringMsg("description"), responses.BulkStringMsg(fn.Description),
+			responses.BulkStringMsg("flags"), responses.SetMsg(c.protocol, fn.Flags),
+		}))
+	}
+	flush()
+
+	return responses.RawArrayMsg(libraries)
+}
@@ -0,0 +1,157 @@
+package RESP
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// FunctionNoWrites flags a function that only reads the keys it is given,
+// so it can run with FCALL_RO and only needs read access to its keys
+const FunctionNoWrites = "no-writes"
+
+// defaultLibrary holds the functions registered without a library
+const defaultLibrary = "go"
+
+// FunctionHandler is the Go code of a server-side function. It runs with exclusive access to db:
+// no other command runs until it returns, and db must not be used after it returned.
+// keys are the keys the caller declared, args the remaining arguments.
+//
+// The result is sent to network clients as a RESP reply: nil as a null, strings and []byte as
+// bulk strings, integers and booleans as integers, and []interface{} or []string as arrays.
+// A returned error is sent as an error reply.
+type FunctionHandler func(db *storage.Database, keys []string, args []string) (interface{}, error)
+
+// Function describes a server-side function callable with FCALL
+type Function struct {
+	// Name the function is called with, case sensitive
+	Name string
+
+	// Library groups functions in FUNCTION LIST, "go" when empty
+	Library string
+
+	// Description is shown by FUNCTION LIST
+	Description string
+
+	// Flags of the function, see FunctionNoWrites
+	Flags []string
+
+	// Handler executes the function
+	Handler FunctionHandler
+}
+
+// HasFlag reports whether the function carries the given flag
+func (fn *Function) HasFlag(flag string) bool {
+	for _, f := range fn.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrUnknownFunction is returned when calling a function that was not registered
+var ErrUnknownFunction = errors.New("Function not found")
+
+// functionRegistry holds the functions registered on a server, by name
+type functionRegistry struct {
+	mu        sync.RWMutex
+	functions map[string]*Function
+}
+
+func newFunctionRegistry() *functionRegistry {
+	return &functionRegistry{functions: make(map[string]*Function)}
+}
+
+// lookup returns the function registered under name, nil if there is none
+func (r *functionRegistry) lookup(name string) *Function {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.functions[name]
+}
+
+// list returns the registered functions sorted by library and name
+func (r *functionRegistry) list() []*Function {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	functions := make([]*Function, 0, len(r.functions))
+	for _, fn := range r.functions {
+		functions = append(functions, fn)
+	}
+	sort.Slice(functions, func(i, j int) bool {
+		if functions[i].Library != functions[j].Library {
+			return functions[i].Library < functions[j].Library
+		}
+		return functions[i].Name < functions[j].Name
+	})
+	return functions
+}
+
+// RegisterFunction makes fn callable with FCALL and CallFunction.
+// Registering a name twice is an error.
+func (h *Handler) RegisterFunction(fn Function) error {
+	if fn.Name == "" {
+		return errors.New("function name can't be empty")
+	}
+	if fn.Handler == nil {
+		return fmt.Errorf("function '%s' has no handler", fn.Name)
+	}
+	for _, flag := range fn.Flags {
+		if flag != FunctionNoWrites {
+			return fmt.Errorf("unknown flag '%s' for function '%s'", flag, fn.Name)
+		}
+	}
+	if fn.Library == "" {
+		fn.Library = defaultLibrary
+	}
+	fn.Flags = append([]string(nil), fn.Flags...)
+
+	r := h.functions
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.functions[fn.Name]; exists {
+		return fmt.Errorf("function '%s' already exists", fn.Name)
+	}
+	r.functions[fn.Name] = &fn
+	return nil
+}
+
+// UnregisterFunction removes the function registered under name and reports whether there was one
+func (h *Handler) UnregisterFunction(name string) bool {
+	r := h.functions
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, exists := r.functions[name]
+	delete(r.functions, name)
+	return exists
+}
+
+// CallFunction runs the function registered under name atomically against the database at index
+func (h *Handler) CallFunction(index int, name string, keys []string, args ...string) (interface{}, error) {
+	db := h.dbs.DB(index)
+	if db == nil {
+		return nil, storage.ErrIndexOutOfRange
+	}
+	fn := h.functions.lookup(name)
+	if fn == nil {
+		return nil, ErrUnknownFunction
+	}
+	return runFunction(db, fn, keys, args)
+}
+
+// runFunction runs fn with exclusive access to db. A panic in fn is returned as an error.
+func runFunction(db *storage.Database, fn *Function, keys, args []string) (result interface{}, err error) {
+	db.Atomic(func(tx *storage.Database) {
+		defer func() {
+			if r := recover(); r != nil {
+				result, err = nil, fmt.Errorf("function '%s' panicked: %v", fn.Name, r)
+			}
+		}()
+		result, err = fn.Handler(tx, keys, args)
+	})
+	return result, err
+}
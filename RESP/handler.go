@@ -0,0 +1,189 @@
+package RESP
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/GedisCaching/Gedis/acl"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// Handler holds the state shared by every connection of a server:
+// the databases the commands run against and the users allowed to connect.
+type Handler struct {
+	dbs *storage.Databases
+
+	// acl holds the users, their permissions and the log of denied requests
+	acl *acl.ACL
+
+	// pubsub holds the channel and pattern subscriptions of the clients
+	pubsub *pubSub
+
+	// clients holds the connected clients by id, for CLIENT LIST and CLIENT KILL
+	clientsMu sync.RWMutex
+	clients   map[int64]*Client
+
+	// functions holds the functions registered from Go, callable with FCALL
+	functions *functionRegistry
+
+	// shutdown runs the SHUTDOWN command for the server, see SetShutdownFunc
+	shutdown func(opts ShutdownOptions) error
+
+	// config holds the parameters of the server for the CONFIG command, see SetConfiguration
+	config Configuration
+
+	// closing is closed once the server shuts down, see Close
+	closing   chan struct{}
+	closeOnce sync.Once
+
+	// keyspaceEvents holds the flags of the key changes published, see SetKeyspaceEvents
+	keyspaceEvents atomic.Int64
+
+	// stats holds the counters reported by INFO
+	stats *serverStats
+
+	// slowlog holds the commands that ran for longer than its threshold, see SetSlowlog
+	slowlog *slowLog
+
+	// monitors holds the clients receiving the commands processed, see MONITOR
+	monitors *monitors
+}
+
+// NewHandler creates a new Handler executing commands against db
+func NewHandler(db *storage.Database) *Handler {
+	return NewHandlerWithDatabases(storage.DatabasesOf(db))
+}
+
+// NewHandlerWithDatabases creates a new Handler executing commands against numbered databases,
+// clients start on database 0 and switch with SELECT
+func NewHandlerWithDatabases(dbs *storage.Databases) *Handler {
+	h := &Handler{
+		dbs: dbs,
+		acl: acl.New(func(name string) bool {
+			return LookupCommand(name) != nil
+		}),
+		pubsub:    newPubSub(),
+		functions: newFunctionRegistry(),
+		clients:   make(map[int64]*Client),
+		closing:   make(chan struct{}),
+		stats:     newServerStats(),
+		slowlog:   newSlowLog(),
+		monitors:  newMonitors(),
+	}
+	for i := 0; i < dbs.Len(); i++ {
+		index := i
+		dbs.DB(i).SetNotifier(func(class byte, event, key string) {
+			h.notifyKeyspaceEvent(index, class, event, key)
+		})
+	}
+	return h
+}
+
+// Databases returns the databases the commands run against
+func (h *Handler) Databases() *storage.Databases {
+	return h.dbs
+}
+
+// NewClient creates the state of a new connection
+func (h *Handler) NewClient() *Client {
+	return newClient(h)
+}
+
+// ACL returns the users allowed to connect and their permissions
+func (h *Handler) ACL() *acl.ACL {
+	return h.acl
+}
+
+// Publish sends a message to the clients subscribed to channel, directly or through a pattern,
+// and returns how many received it
+func (h *Handler) Publish(channel, message string) int {
+	return h.pubsub.publish(channel, message)
+}
+
+// SetPassword sets the password of the default user,
+// an empty password lets clients connect without authenticating
+func (h *Handler) SetPassword(password string) {
+	if password == "" {
+		h.acl.SetUser(acl.DefaultUser, "nopass")
+		return
+	}
+	h.acl.SetUser(acl.DefaultUser, "resetpass", ">"+password)
+}
+
+// ShutdownOptions are the modifiers of the SHUTDOWN command
+type ShutdownOptions struct {
+	// Save saves the database even when persistence is not configured,
+	// NoSave skips saving it when it is
+	Save   bool
+	NoSave bool
+
+	// Now closes the connections without waiting for the commands being executed
+	Now bool
+
+	// Force shuts down even when saving the database failed
+	Force bool
+}
+
+// SetShutdownFunc sets the function stopping the server when a client sends SHUTDOWN.
+// It returns once the server is stopping, or with an error to abort the shutdown.
+func (h *Handler) SetShutdownFunc(fn func(opts ShutdownOptions) error) {
+	h.shutdown = fn
+}
+
+// Configuration holds the parameters of a server, read and changed with the CONFIG command
+type Configuration interface {
+	// ConfigGet returns the parameters matching any of the glob-style patterns, with their values
+	ConfigGet(patterns ...string) map[string]string
+
+	// ConfigSet changes the parameters to the given values.
+	// When a value is invalid or a parameter can't be changed, none of them is.
+	ConfigSet(values map[string]string) error
+
+	// ConfigRewrite writes the current parameters to the configuration file of the server
+	ConfigRewrite() error
+}
+
+// SetConfiguration sets the parameters the CONFIG command reads and changes
+func (h *Handler) SetConfiguration(config Configuration) {
+	h.config = config
+}
+
+// Close is called when the server shuts down: the clients blocked on lists
+// reply as if their timeout expired
+func (h *Handler) Close() {
+	h.closeOnce.Do(func() { close(h.closing) })
+}
+
+// addClient registers a new connection in the client list
+func (h *Handler) addClient(c *Client) {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+	h.clients[c.id] = c
+	h.stats.totalConnections.Add(1)
+}
+
+// removeClient removes a closed connection from the client list
+func (h *Handler) removeClient(c *Client) {
+	h.clientsMu.Lock()
+	defer h.clientsMu.Unlock()
+	delete(h.clients, c.id)
+}
+
+// Clients returns the connected clients, ordered by id
+func (h *Handler) Clients() []*Client {
+	h.clientsMu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for _, c := range h.clients {
+		clients = append(clients, c)
+	}
+	h.clientsMu.RUnlock()
+
+	sort.Slice(clients, func(i, j int) bool { return clients[i].id < clients[j].id })
+	return clients
+}
+
+// requiresAuth reports whether clients must authenticate before running commands
+func (h *Handler) requiresAuth() bool {
+	return h.acl.RequiresAuth()
+}
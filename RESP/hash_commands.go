@@ -0,0 +1,110 @@
+package RESP
+
+import (
+	"sort"
+
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// ------------------------------ Hash Commands ------------------------------
+
+// PerformHSet sets one or more field-value pairs in a hash
+// and returns the number of fields that were added
+func PerformHSet(c *Client, args []string) string {
+	if len(args)%2 != 1 {
+		return responses.ErrorMsg("wrong number of arguments for 'HSET' command")
+	}
+
+	fields := make(map[string]interface{}, len(args)/2)
+	for i := 1; i < len(args); i += 2 {
+		fields[args[i]] = args[i+1]
+	}
+
+	added, err := c.db.HMSET(args[0], fields)
+	if err != nil {
+		return responses.WrongTypeMsg()
+	}
+	return responses.IntegerMsg(added)
+}
+
+// PerformHGet returns the value of a field in a hash
+func PerformHGet(c *Client, args []string) string {
+	value, exists := c.db.HGET(args[0], args[1])
+	if !exists {
+		if !hasType(c, args[0], "hash") {
+			return responses.WrongTypeMsg()
+		}
+		return responses.NullMsg(c.protocol)
+	}
+	return responses.BulkStringMsg(formatValue(value))
+}
+
+// PerformHDel deletes one or more fields from a hash
+// and returns the number of fields that were removed
+func PerformHDel(c *Client, args []string) string {
+	removed, err := c.db.HDELFields(args[0], args[1:]...)
+	if err != nil {
+		return responses.WrongTypeMsg()
+	}
+	return responses.IntegerMsg(removed)
+}
+
+// PerformHGetAll returns every field followed by its value, ordered by field name.
+// RESP3 clients receive a map.
+func PerformHGetAll(c *Client, args []string) string {
+	hash, exists := c.db.HGETALL(args[0])
+	if !exists && !hasType(c, args[0], "hash") {
+		return responses.WrongTypeMsg()
+	}
+	fields := sortedFields(hash)
+
+	frames := make([]string, 0, len(fields)*2)
+	for _, field := range fields {
+		frames = append(frames, responses.BulkStringMsg(field), responses.BulkStringMsg(formatValue(hash[field])))
+	}
+	return responses.MapMsg(c.protocol, frames)
+}
+
+// PerformHKeys returns every field name of a hash
+func PerformHKeys(c *Client, args []string) string {
+	hash, exists := c.db.HGETALL(args[0])
+	if !exists && !hasType(c, args[0], "hash") {
+		return responses.WrongTypeMsg()
+	}
+	return responses.ArrayMsg(sortedFields(hash))
+}
+
+// PerformHVals returns every value of a hash,
+// in the same order as HKEYS returns the fields
+func PerformHVals(c *Client, args []string) string {
+	hash, exists := c.db.HGETALL(args[0])
+	if !exists && !hasType(c, args[0], "hash") {
+		return responses.WrongTypeMsg()
+	}
+	fields := sortedFields(hash)
+
+	values := make([]string, len(fields))
+	for i, field := range fields {
+		values[i] = formatValue(hash[field])
+	}
+	return responses.ArrayMsg(values)
+}
+
+// PerformHLen returns the number of fields in a hash
+func PerformHLen(c *Client, args []string) string {
+	length, exists := c.db.HLEN(args[0])
+	if !exists && !hasType(c, args[0], "hash") {
+		return responses.WrongTypeMsg()
+	}
+	return responses.IntegerMsg(length)
+}
+
+// sortedFields returns the field names of a hash in a stable order
+func sortedFields(hash map[string]interface{}) []string {
+	fields := make([]string, 0, len(hash))
+	for field := range hash {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,111 @@
+package RESP
+
+import (
+	"fmt"
+	"strings"
+
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// Help texts of the commands, returned by HELP
+
+var (
+	HelpSET = `
+	    SET: is a function that sets a key-value pair in the store. 
+		it takes a key and a value as arguments.
+	    like this: SET key value
+	    SET key value (EX, PX) 30 
+	`
+
+	HelpGET = `
+	    GET: is a function that retrieves a value for a given key from the store.
+	    it takes a key as argument.
+	    like this: GET key
+	`
+
+	HelpDEL = `
+	    DEL: is a function that deletes key-value pairs from the store.
+	    it takes one or more keys as arguments.
+	    like this: DEL key [key ...]
+	    returns the number of keys that were deleted.
+	`
+
+	HelpEXISTS = `
+	    EXISTS: is a function that checks if a key exists in the store.
+	    it takes one or more keys as arguments.
+	    like this: EXISTS key [key ...]
+	    returns the number of keys that exist.
+	`
+
+	HelpTTL = `
+	    TTL: is a function that returns the time to live of a key in seconds.
+	    it takes a key as argument.
+	    like this: TTL key
+	    returns the remaining time in seconds, -1 if no expiry, or -2 if the key doesn't exist.
+	`
+
+	HelpPING = `
+	    PING: is a function used to test if the server is responsive.
+	    like this: PING
+	    returns PONG
+	`
+
+	HelpEXPIRE = `
+	    EXPIRE: is a function that sets a timeout on a key after which the key will be automatically deleted.
+	    it takes a key and seconds as arguments.
+	    like this: EXPIRE key seconds
+	    returns 1 if the timeout was set, 0 if the key doesn't exist or the timeout couldn't be set.
+	`
+
+	HelpGETDEL = `
+	    GETDEL: is a function that gets the value of a key and deletes the key.
+	    it takes a key as argument.
+	    like this: GETDEL key
+	    returns the value of the key if it exists, or nil if the key doesn't exist.
+	    this is an atomic operation that combines GET and DEL.
+	`
+
+	HelpRENAME = `
+	    RENAME: is a function that renames a key to a new key name.
+	    it takes the old key name and the new key name as arguments.
+	    like this: RENAME oldkey newkey
+	    returns OK if successful, or an error if the key doesn't exist or the new key already exists.
+	    if newkey already exists, it is overwritten.
+	`
+)
+
+// Mapping holds the help text of the commands that have one, by upper case name
+var Mapping = map[string]string{
+	"SET":    HelpSET,
+	"GET":    HelpGET,
+	"DEL":    HelpDEL,
+	"EXISTS": HelpEXISTS,
+	"TTL":    HelpTTL,
+	"PING":   HelpPING,
+	"EXPIRE": HelpEXPIRE,
+	"GETDEL": HelpGETDEL,
+	"RENAME": HelpRENAME,
+}
+
+// PerformHelp returns the help text of a command,
+// or a one line summary of every command when none is given.
+// HELP [command]
+func PerformHelp(c *Client, args []string) string {
+	if len(args) == 0 {
+		commands := Commands()
+		lines := make([]string, len(commands))
+		for i, cmd := range commands {
+			lines[i] = cmd.Name + " - " + cmd.Summary
+		}
+		return responses.ArrayMsg(lines)
+	}
+
+	cmd := LookupCommand(args[0])
+	if cmd == nil {
+		return responses.ErrorMsg(fmt.Sprintf("unknown command '%s'", args[0]))
+	}
+	if text, exists := Mapping[cmd.Name]; exists {
+		return responses.BulkStringMsg(text)
+	}
+	return responses.BulkStringMsg(cmd.Name + ": " + strings.TrimSuffix(cmd.Summary, "."))
+}
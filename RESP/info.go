@@ -0,0 +1,236 @@
+package RESP
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	responses "github.com/GedisCaching/Gedis/responses"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// serverStats holds the counters of a handler reported by INFO
+type serverStats struct {
+	// started is when the handler was created, runID identifies that run of the server
+	started time.Time
+	runID   string
+
+	totalConnections atomic.Int64
+	totalCommands    atomic.Int64
+	blockedClients   atomic.Int64
+
+	// commands holds the statistics of every command of the command table, by name
+	commands map[string]*commandStats
+}
+
+// commandStats holds the statistics of a command for the commandstats section of INFO
+type commandStats struct {
+	calls    atomic.Int64
+	duration atomic.Int64 // total time spent running the command, in nanoseconds
+
+	// rejected counts the calls refused before running, failed those replying with an error
+	rejected atomic.Int64
+	failed   atomic.Int64
+}
+
+func newServerStats() *serverStats {
+	id := make([]byte, 20)
+	rand.Read(id)
+
+	stats := &serverStats{
+		started:  time.Now(),
+		runID:    hex.EncodeToString(id),
+		commands: make(map[string]*commandStats, len(commandTable)),
+	}
+	for name := range commandTable {
+		stats.commands[name] = &commandStats{}
+	}
+	return stats
+}
+
+// recordCall records a command that ran for duration, failed when it replied with an error
+func (s *serverStats) recordCall(cmd *Command, duration time.Duration, failed bool) {
+	s.totalCommands.Add(1)
+	stats := s.commands[cmd.Name]
+	stats.calls.Add(1)
+	stats.duration.Add(int64(duration))
+	if failed {
+		stats.failed.Add(1)
+	}
+}
+
+// recordRejected records a command refused before running, like a call with the wrong number of arguments
+func (s *serverStats) recordRejected(cmd *Command) {
+	s.commands[cmd.Name].rejected.Add(1)
+}
+
+// infoSections are the sections of INFO in the order they are written,
+// and whether they are part of the default reply
+var infoSections = []struct {
+	name         string
+	defaultReply bool
+	write        func(h *Handler, dbs *storage.Databases, sb *strings.Builder)
+}{
+	{"server", true, (*Handler).writeServerInfo},
+	{"clients", true, (*Handler).writeClientsInfo},
+	{"memory", true, (*Handler).writeMemoryInfo},
+	{"stats", true, (*Handler).writeStatsInfo},
+	{"commandstats", false, (*Handler).writeCommandStatsInfo},
+	{"keyspace", true, (*Handler).writeKeyspaceInfo},
+}
+
+// PerformInfo returns information and statistics about the server, in the Redis INFO layout.
+// The databases are read through those of the client, the views EXEC locked inside a transaction.
+// INFO [section [section ...]]
+func PerformInfo(c *Client, args []string) string {
+	return responses.BulkStringMsg(c.handler.info(c.dbs, args))
+}
+
+// Info returns the sections of the INFO command, in the Redis text layout:
+// a "# Section" header followed by "field:value" lines, sections separated by an empty line.
+// Without sections, the default ones are returned. "all" or "everything" returns every section,
+// "default" the default ones, and unknown sections are left out.
+func (h *Handler) Info(sections ...string) string {
+	return h.info(h.dbs, sections)
+}
+
+// info returns the sections of INFO, reading the keys through dbs
+func (h *Handler) info(dbs *storage.Databases, sections []string) string {
+	selected := make(map[string]bool)
+	if len(sections) == 0 {
+		sections = []string{"default"}
+	}
+	for _, section := range sections {
+		section = strings.ToLower(section)
+		for _, s := range infoSections {
+			switch section {
+			case "all", "everything":
+				selected[s.name] = true
+			case "default":
+				selected[s.name] = selected[s.name] || s.defaultReply
+			case s.name:
+				selected[s.name] = true
+			}
+		}
+	}
+
+	var sb strings.Builder
+	for _, s := range infoSections {
+		if !selected[s.name] {
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\r\n")
+		}
+		s.write(h, dbs, &sb)
+	}
+	return sb.String()
+}
+
+// infoLine writes a "field:value" line of INFO
+func infoLine(sb *strings.Builder, field string, value interface{}) {
+	fmt.Fprintf(sb, "%s:%v\r\n", field, value)
+}
+
+func (h *Handler) writeServerInfo(dbs *storage.Databases, sb *strings.Builder) {
+	uptime := time.Since(h.stats.started)
+	sb.WriteString("# Server\r\n")
+	infoLine(sb, "redis_version", Version)
+	infoLine(sb, "redis_mode", "standalone")
+	infoLine(sb, "os", runtime.GOOS+" "+runtime.GOARCH)
+	infoLine(sb, "arch_bits", strconv.IntSize)
+	infoLine(sb, "go_version", runtime.Version())
+	infoLine(sb, "process_id", os.Getpid())
+	infoLine(sb, "run_id", h.stats.runID)
+	infoLine(sb, "uptime_in_seconds", int64(uptime.Seconds()))
+	infoLine(sb, "uptime_in_days", int64(uptime.Hours()/24))
+}
+
+func (h *Handler) writeClientsInfo(dbs *storage.Databases, sb *strings.Builder) {
+	h.clientsMu.RLock()
+	connected := len(h.clients)
+	h.clientsMu.RUnlock()
+
+	sb.WriteString("# Clients\r\n")
+	infoLine(sb, "connected_clients", connected)
+	infoLine(sb, "blocked_clients", h.stats.blockedClients.Load())
+}
+
+func (h *Handler) writeMemoryInfo(dbs *storage.Databases, sb *strings.Builder) {
+	used := dbs.UsedMemory()
+	limit, policy := dbs.MaxMemory()
+
+	sb.WriteString("# Memory\r\n")
+	infoLine(sb, "used_memory", used)
+	infoLine(sb, "used_memory_human", humanBytes(used))
+	infoLine(sb, "maxmemory", limit)
+	infoLine(sb, "maxmemory_human", humanBytes(limit))
+	infoLine(sb, "maxmemory_policy", policy)
+}
+
+func (h *Handler) writeStatsInfo(dbs *storage.Databases, sb *strings.Builder) {
+	keyspace := dbs.Stats()
+
+	sb.WriteString("# Stats\r\n")
+	infoLine(sb, "total_connections_received", h.stats.totalConnections.Load())
+	infoLine(sb, "total_commands_processed", h.stats.totalCommands.Load())
+	infoLine(sb, "expired_keys", keyspace.ExpiredKeys)
+	infoLine(sb, "evicted_keys", keyspace.EvictedKeys)
+	infoLine(sb, "keyspace_hits", keyspace.KeyspaceHits)
+	infoLine(sb, "keyspace_misses", keyspace.KeyspaceMisses)
+	infoLine(sb, "pubsub_channels", len(h.pubsub.activeChannels("")))
+	infoLine(sb, "pubsub_patterns", h.pubsub.numPatterns())
+}
+
+// writeCommandStatsInfo writes a line for every command called since the server started, ordered by name
+func (h *Handler) writeCommandStatsInfo(dbs *storage.Databases, sb *strings.Builder) {
+	sb.WriteString("# Commandstats\r\n")
+	for _, cmd := range Commands() {
+		stats := h.stats.commands[cmd.Name]
+		calls, rejected, failed := stats.calls.Load(), stats.rejected.Load(), stats.failed.Load()
+		if calls == 0 && rejected == 0 {
+			continue
+		}
+
+		usec := stats.duration.Load() / int64(time.Microsecond)
+		perCall := 0.0
+		if calls > 0 {
+			perCall = float64(usec) / float64(calls)
+		}
+		fmt.Fprintf(sb, "cmdstat_%s:calls=%d,usec=%d,usec_per_call=%.2f,rejected_calls=%d,failed_calls=%d\r\n",
+			strings.ToLower(cmd.Name), calls, usec, perCall, rejected, failed)
+	}
+}
+
+// writeKeyspaceInfo writes a line for every database holding keys
+func (h *Handler) writeKeyspaceInfo(dbs *storage.Databases, sb *strings.Builder) {
+	sb.WriteString("# Keyspace\r\n")
+	for i := 0; i < dbs.Len(); i++ {
+		info := dbs.DB(i).KeyspaceInfo()
+		if info.Keys == 0 {
+			continue
+		}
+		fmt.Fprintf(sb, "db%d:keys=%d,expires=%d,avg_ttl=%d\r\n", i, info.Keys, info.Expires, info.AvgTTL.Milliseconds())
+	}
+}
+
+// humanBytes formats a number of bytes the way INFO does, like 1.50M
+func humanBytes(n int64) string {
+	const units = "KMGTP"
+	if n < 1024 {
+		return fmt.Sprintf("%dB", n)
+	}
+	value := float64(n) / 1024
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.2f%c", value, units[unit])
+}
@@ -0,0 +1,142 @@
+package RESP
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnbalancedQuotes is returned by SplitArgs when a quoted argument is not terminated,
+// or a closing quote is not followed by a space
+var ErrUnbalancedQuotes = errors.New("unbalanced quotes in request")
+
+// SplitArgs splits a plain text command into arguments the way redis-cli does:
+//   - arguments are separated by spaces, tabs and newlines
+//   - double quoted arguments support the \n, \r, \t, \b, \a, \" and \\ escapes,
+//     and \xHH for any byte given as two hexadecimal digits
+//   - single quoted arguments are literal, only \' is unescaped
+//
+// It is used for inline commands and can be used by clients to parse user input.
+func SplitArgs(line string) ([]string, error) {
+	var args []string
+
+	i := 0
+	for {
+		// Skip the blanks between arguments
+		for i < len(line) && isBlank(line[i]) {
+			i++
+		}
+		if i == len(line) {
+			return args, nil
+		}
+
+		var arg strings.Builder
+		inDouble, inSingle := false, false
+
+		for done := false; !done; {
+			switch {
+			case inDouble:
+				if i == len(line) {
+					return nil, ErrUnbalancedQuotes
+				}
+				c := line[i]
+				switch {
+				case c == '\\' && i+3 < len(line) && line[i+1] == 'x' && isHexDigit(line[i+2]) && isHexDigit(line[i+3]):
+					arg.WriteByte(hexValue(line[i+2])<<4 | hexValue(line[i+3]))
+					i += 3
+				case c == '\\' && i+1 < len(line):
+					i++
+					arg.WriteByte(unescape(line[i]))
+				case c == '"':
+					// The closing quote must end the argument
+					if i+1 < len(line) && !isBlank(line[i+1]) {
+						return nil, ErrUnbalancedQuotes
+					}
+					done = true
+				default:
+					arg.WriteByte(c)
+				}
+
+			case inSingle:
+				if i == len(line) {
+					return nil, ErrUnbalancedQuotes
+				}
+				c := line[i]
+				switch {
+				case c == '\\' && i+1 < len(line) && line[i+1] == '\'':
+					i++
+					arg.WriteByte('\'')
+				case c == '\'':
+					if i+1 < len(line) && !isBlank(line[i+1]) {
+						return nil, ErrUnbalancedQuotes
+					}
+					done = true
+				default:
+					arg.WriteByte(c)
+				}
+
+			default:
+				if i == len(line) {
+					done = true
+					continue
+				}
+				switch c := line[i]; {
+				case isBlank(c):
+					done = true
+				case c == '"':
+					inDouble = true
+				case c == '\'':
+					inSingle = true
+				default:
+					arg.WriteByte(c)
+				}
+			}
+
+			if i < len(line) {
+				i++
+			}
+		}
+
+		args = append(args, arg.String())
+	}
+}
+
+// isBlank reports whether c separates arguments
+func isBlank(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\v', '\f':
+		return true
+	}
+	return false
+}
+
+// unescape returns the byte a backslash escape inside double quotes stands for
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'b':
+		return '\b'
+	case 'a':
+		return '\a'
+	}
+	return c
+}
+
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
@@ -0,0 +1,228 @@
+package RESP
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	responses "github.com/GedisCaching/Gedis/responses"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// ------------------------------ List Commands ------------------------------
+
+// PerformLPush adds one or more values to the head of a list
+// and returns the length of the list after the push
+func PerformLPush(c *Client, args []string) string {
+	length, err := c.db.LPush(args[0], toValues(args[1:])...)
+	if err != nil {
+		return responses.WrongTypeMsg()
+	}
+	return responses.IntegerMsg(length)
+}
+
+// PerformRPush adds one or more values to the tail of a list
+// and returns the length of the list after the push
+func PerformRPush(c *Client, args []string) string {
+	length, err := c.db.RPush(args[0], toValues(args[1:])...)
+	if err != nil {
+		return responses.WrongTypeMsg()
+	}
+	return responses.IntegerMsg(length)
+}
+
+// PerformLPop removes and returns the first element of a list
+func PerformLPop(c *Client, args []string) string {
+	value, err := c.db.LPop(args[0])
+	if err != nil {
+		return responses.WrongTypeMsg()
+	}
+	if value == nil {
+		return responses.NullMsg(c.protocol)
+	}
+	return responses.BulkStringMsg(formatValue(value))
+}
+
+// PerformRPop removes and returns the last element of a list
+func PerformRPop(c *Client, args []string) string {
+	value, err := c.db.RPop(args[0])
+	if err != nil {
+		return responses.WrongTypeMsg()
+	}
+	if value == nil {
+		return responses.NullMsg(c.protocol)
+	}
+	return responses.BulkStringMsg(formatValue(value))
+}
+
+// PerformLLen returns the length of a list, 0 if the key doesn't exist
+func PerformLLen(c *Client, args []string) string {
+	length, err := c.db.LLen(args[0])
+	if errors.Is(err, storage.ErrWrongType) {
+		return responses.WrongTypeMsg()
+	}
+	if err != nil {
+		return responses.IntegerMsg(0)
+	}
+	return responses.IntegerMsg(length)
+}
+
+// PerformLRange returns the elements of a list between start and stop (inclusive)
+func PerformLRange(c *Client, args []string) string {
+	start, err1 := strconv.Atoi(args[1])
+	stop, err2 := strconv.Atoi(args[2])
+	if err1 != nil || err2 != nil {
+		return responses.ErrorMsg("value is not an integer or out of range")
+	}
+
+	values, err := c.db.LRange(args[0], start, stop)
+	if err != nil {
+		return responses.WrongTypeMsg()
+	}
+	return responses.ArrayMsg(toStrings(values))
+}
+
+// PerformLSet sets the element at index in a list,
+// negative indexes count from the end of the list
+func PerformLSet(c *Client, args []string) string {
+	index, err := strconv.Atoi(args[1])
+	if err != nil {
+		return responses.ErrorMsg("value is not an integer or out of range")
+	}
+
+	if err := c.db.LSet(args[0], index, args[2]); err != nil {
+		return lsetErrorMsg(err)
+	}
+	return responses.StringMsg("OK")
+}
+
+// lsetErrorMsg returns the reply to an LSET the storage refused
+func lsetErrorMsg(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrWrongType):
+		return responses.WrongTypeMsg()
+	case err.Error() == "key does not exist":
+		return responses.ErrorMsg("no such key")
+	}
+	return responses.ErrorMsg(err.Error())
+}
+
+// PerformBLPop removes and returns the first element of the first non-empty list among the keys,
+// waiting for an element to be pushed when every list is empty.
+// BLPOP key [key ...] timeout
+func PerformBLPop(c *Client, args []string) string {
+	return blockingPop(c, args, c.db.BLPop)
+}
+
+// PerformBRPop is BLPOP popping the last element of the list
+// BRPOP key [key ...] timeout
+func PerformBRPop(c *Client, args []string) string {
+	return blockingPop(c, args, c.db.BRPop)
+}
+
+// blockingPop runs BLPOP or BRPOP with pop, replying with the key and the element popped,
+// or a null array once the timeout expired
+func blockingPop(c *Client, args []string, pop func(ctx context.Context, keys ...string) (string, interface{}, error)) string {
+	timeout, errMsg := parseTimeout(args[len(args)-1])
+	if errMsg != "" {
+		return errMsg
+	}
+
+	ctx, cancel := blockingContext(c, timeout)
+	defer cancel()
+
+	key, value, err := pop(ctx, args[:len(args)-1]...)
+	if isTimeout(ctx, err) {
+		return responses.NullArrayMsg(c.protocol)
+	}
+	if err != nil {
+		return responses.WrongTypeMsg()
+	}
+	return responses.ArrayMsg([]string{key, formatValue(value)})
+}
+
+// PerformBLMove pops an element from the source list and pushes it to the destination list,
+// waiting for an element to be pushed when the source list is empty.
+// BLMOVE source destination LEFT|RIGHT LEFT|RIGHT timeout
+func PerformBLMove(c *Client, args []string) string {
+	fromLeft, ok1 := parseDirection(args[2])
+	toLeft, ok2 := parseDirection(args[3])
+	if !ok1 || !ok2 {
+		return responses.ErrorMsg("syntax error")
+	}
+	timeout, errMsg := parseTimeout(args[4])
+	if errMsg != "" {
+		return errMsg
+	}
+
+	ctx, cancel := blockingContext(c, timeout)
+	defer cancel()
+
+	value, err := c.db.BLMove(ctx, args[0], args[1], fromLeft, toLeft)
+	if isTimeout(ctx, err) {
+		return responses.NullMsg(c.protocol)
+	}
+	if err != nil {
+		return responses.WrongTypeMsg()
+	}
+	return responses.BulkStringMsg(formatValue(value))
+}
+
+// parseTimeout parses the timeout of a blocking command in seconds, 0 waiting forever
+func parseTimeout(arg string) (time.Duration, string) {
+	seconds, err := strconv.ParseFloat(arg, 64)
+	if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) {
+		return 0, responses.ErrorMsg("timeout is not a float or out of range")
+	}
+	if seconds < 0 {
+		return 0, responses.ErrorMsg("timeout is negative")
+	}
+	return time.Duration(seconds * float64(time.Second)), ""
+}
+
+// parseDirection parses the LEFT or RIGHT end of a list, reporting whether it is LEFT
+func parseDirection(arg string) (left bool, ok bool) {
+	switch strings.ToUpper(arg) {
+	case "LEFT":
+		return true, true
+	case "RIGHT":
+		return false, true
+	}
+	return false, false
+}
+
+// blockingContext returns the context a blocking command waits with:
+// it ends after timeout, unless timeout is 0, or when the connection ends or the server shuts down
+func blockingContext(c *Client, timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	ctx, cancelConn := context.WithCancel(ctx)
+	c.handler.stats.blockedClients.Add(1)
+
+	go func() {
+		select {
+		case <-c.done:
+			cancelConn()
+		case <-c.handler.closing:
+			cancelConn()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		cancelConn()
+		cancel()
+		c.handler.stats.blockedClients.Add(-1)
+	}
+}
+
+// isTimeout reports whether a blocking command ended without an element:
+// the timeout expired, the connection ended, or the command ran in a transaction
+func isTimeout(ctx context.Context, err error) bool {
+	return err != nil && (ctx.Err() != nil || errors.Is(err, storage.ErrWouldBlock))
+}
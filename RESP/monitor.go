@@ -0,0 +1,110 @@
+package RESP
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// monitors holds the clients that ran MONITOR, each receiving a line for every command processed
+type monitors struct {
+	mu      sync.RWMutex
+	clients map[*Client]struct{}
+
+	// count lets feed return at once while nobody is monitoring
+	count atomic.Int64
+}
+
+func newMonitors() *monitors {
+	return &monitors{clients: make(map[*Client]struct{})}
+}
+
+// PerformMonitor turns the connection into a feed of every command the server processes.
+// A monitor that cannot keep up with the feed is disconnected.
+// MONITOR
+func PerformMonitor(c *Client, args []string) string {
+	m := c.handler.monitors
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.clients[c]; !exists {
+		m.clients[c] = struct{}{}
+		m.count.Add(1)
+		c.monitor.Store(true)
+	}
+	return responses.StringMsg("OK")
+}
+
+// Monitoring reports whether the client ran MONITOR
+func (c *Client) Monitoring() bool {
+	return c.monitor.Load()
+}
+
+// remove stops feeding a client once its connection is closed
+func (m *monitors) remove(c *Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.clients[c]; exists {
+		delete(m.clients, c)
+		m.count.Add(-1)
+	}
+}
+
+// feed sends a command about to run to every monitor, like
+// +1700000000.123456 [0 127.0.0.1:50000] "SET" "key" "value".
+// Administrative commands are left out, and passwords are redacted as in the slow log.
+func (m *monitors) feed(c *Client, cmd *Command, args []string, now time.Time) {
+	if m.count.Load() == 0 || cmd.HasFlag(FlagAdmin) {
+		return
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d.%06d [%d %s]", now.Unix(), now.Nanosecond()/1000, c.dbIndex, c.Addr())
+	for _, arg := range append([]string{cmd.Name}, redactArgs(cmd, args)...) {
+		sb.WriteByte(' ')
+		sb.WriteString(quoteArg(arg))
+	}
+	line := responses.StringMsg(sb.String())
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for monitor := range m.clients {
+		monitor.push(line)
+	}
+}
+
+// quoteArg quotes an argument for the MONITOR feed, escaping quotes, backslashes and the bytes that are not printable
+func quoteArg(arg string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(arg); i++ {
+		switch b := arg[i]; b {
+		case '\\', '"':
+			sb.WriteByte('\\')
+			sb.WriteByte(b)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\a':
+			sb.WriteString(`\a`)
+		case '\b':
+			sb.WriteString(`\b`)
+		default:
+			if b < 0x20 || b > 0x7e {
+				fmt.Fprintf(&sb, `\x%02x`, b)
+			} else {
+				sb.WriteByte(b)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
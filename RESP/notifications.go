@@ -0,0 +1,132 @@
+package RESP
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// Flags of the notify-keyspace-events setting selecting which key changes are published
+const (
+	notifyKeyspace = 1 << iota // K: publish to __keyspace@<db>__:<key>
+	notifyKeyevent             // E: publish to __keyevent@<db>__:<event>
+	notifyGeneric              // g
+	notifyString               // $
+	notifyList                 // l
+	notifyHash                 // h
+	notifyZSet                 // z
+	notifyExpired              // x
+	notifyEvicted              // e
+
+	notifyAll = notifyGeneric | notifyString | notifyList | notifyHash | notifyZSet | notifyExpired | notifyEvicted
+)
+
+// notifyClasses maps the flag characters to their bits, in the order they are formatted
+var notifyClasses = []struct {
+	char byte
+	flag int
+}{
+	{storage.ClassGeneric, notifyGeneric},
+	{storage.ClassString, notifyString},
+	{storage.ClassList, notifyList},
+	{storage.ClassHash, notifyHash},
+	{storage.ClassZSet, notifyZSet},
+	{storage.ClassExpired, notifyExpired},
+	{storage.ClassEvicted, notifyEvicted},
+}
+
+// parseKeyspaceEvents decodes a notify-keyspace-events setting like "KEA" or "Kgx"
+func parseKeyspaceEvents(value string) (int, error) {
+	flags := 0
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case 'K':
+			flags |= notifyKeyspace
+		case 'E':
+			flags |= notifyKeyevent
+		case 'A':
+			flags |= notifyAll
+		default:
+			flag := classFlag(c)
+			if flag == 0 {
+				return 0, fmt.Errorf("invalid keyspace events flag '%c'", c)
+			}
+			flags |= flag
+		}
+	}
+	return flags, nil
+}
+
+// formatKeyspaceEvents encodes flags back into a notify-keyspace-events setting
+func formatKeyspaceEvents(flags int) string {
+	var sb strings.Builder
+	if flags&notifyAll == notifyAll {
+		sb.WriteByte('A')
+	} else {
+		for _, class := range notifyClasses {
+			if flags&class.flag != 0 {
+				sb.WriteByte(class.char)
+			}
+		}
+	}
+	if flags&notifyKeyspace != 0 {
+		sb.WriteByte('K')
+	}
+	if flags&notifyKeyevent != 0 {
+		sb.WriteByte('E')
+	}
+	return sb.String()
+}
+
+// classFlag returns the flag of an event class, 0 if the class is unknown
+func classFlag(class byte) int {
+	for _, c := range notifyClasses {
+		if c.char == class {
+			return c.flag
+		}
+	}
+	return 0
+}
+
+// SetKeyspaceEvents selects the key changes published to the __keyspace@<db>__ and __keyevent@<db>__
+// channels, with the flags of the Redis notify-keyspace-events setting. An empty value disables them.
+func (h *Handler) SetKeyspaceEvents(value string) error {
+	flags, err := parseKeyspaceEvents(value)
+	if err != nil {
+		return err
+	}
+	h.keyspaceEvents.Store(int64(flags))
+	return nil
+}
+
+// NormalizeKeyspaceEvents checks a notify-keyspace-events setting and returns it
+// the way KeyspaceEvents reports it, like "AKE" for "KEA"
+func NormalizeKeyspaceEvents(value string) (string, error) {
+	flags, err := parseKeyspaceEvents(value)
+	if err != nil {
+		return "", err
+	}
+	return formatKeyspaceEvents(flags), nil
+}
+
+// KeyspaceEvents returns the flags of the published key changes
+func (h *Handler) KeyspaceEvents() string {
+	return formatKeyspaceEvents(int(h.keyspaceEvents.Load()))
+}
+
+// notifyKeyspaceEvent publishes a change of key according to the keyspace events setting.
+// It is the storage.Notifier of database number db and runs with its lock held.
+func (h *Handler) notifyKeyspaceEvent(db int, class byte, event, key string) {
+	flags := int(h.keyspaceEvents.Load())
+	if flags&classFlag(class) == 0 {
+		return
+	}
+
+	if flags&notifyKeyspace != 0 {
+		h.pubsub.publish(fmt.Sprintf("__keyspace@%d__:%s", db, key), event)
+	}
+	if flags&notifyKeyevent != 0 {
+		h.pubsub.publish(fmt.Sprintf("__keyevent@%d__:%s", db, event), key)
+	}
+}
@@ -0,0 +1,41 @@
+package RESP
+
+import (
+	"errors"
+
+	responses "github.com/GedisCaching/Gedis/responses"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// ------------------------------ Numeric Commands ------------------------------
+
+// PerformIncr increments the integer stored at key by one
+// and returns the new value
+func PerformIncr(c *Client, args []string) string {
+	value, err := c.db.Incr(args[0])
+	if err != nil {
+		return numericErrorMsg(err)
+	}
+	return responses.IntegerMsg(value)
+}
+
+// PerformDecr decrements the integer stored at key by one
+// and returns the new value
+func PerformDecr(c *Client, args []string) string {
+	value, err := c.db.Decr(args[0])
+	if err != nil {
+		return numericErrorMsg(err)
+	}
+	return responses.IntegerMsg(value)
+}
+
+// numericErrorMsg returns the error reply of an increment or decrement that failed
+func numericErrorMsg(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrWrongType):
+		return responses.WrongTypeMsg()
+	case errors.Is(err, storage.ErrOverflow):
+		return responses.ErrorMsg(err.Error())
+	}
+	return responses.ErrorMsg("value is not an integer or out of range")
+}
@@ -1,138 +1,144 @@
 package RESP
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"strings"
+	"time"
 
+	"github.com/GedisCaching/Gedis/acl"
 	responses "github.com/GedisCaching/Gedis/responses"
 )
 
-type Parser struct {
-	NumberOfExpectedArguments int
-	LengthOfNextArgument      int
-	NumberOfArgumentsParsed   int
+// Parse decodes a single command from a byte slice and executes it.
+// Both RESP arrays and plain text commands like "SET key value EX 30" are accepted.
+func Parse(c *Client, command []byte) string {
+	args, err := NewReader(bytes.NewReader(command)).ReadCommand()
+	if err != nil {
+		if err == io.EOF {
+			return responses.ErrorMsg("empty command")
+		}
+		return responses.ErrorMsg(err.Error())
+	}
+
+	return ParseCommand(c, args[0], args[1:])
 }
 
-func Parse(command []byte) string {
-	// Check if this is a plain text command (doesn't start with RESP markers)
-	if len(command) > 0 && command[0] != '*' && command[0] != '$' {
-		return parsePlainTextCommand(command)
+// ParseCommand executes a command for the client against its database.
+// The number of arguments is checked against the command table before the handler runs.
+// Inside a transaction, valid commands are queued until EXEC.
+// The reply is empty for commands that push their replies to the client's Output, like SUBSCRIBE.
+func ParseCommand(c *Client, command string, args []string) string {
+	c.recordCommand(command, args)
+
+	cmd, errMsg := checkCommand(c, command, args)
+	if errMsg != "" {
+		if known := LookupCommand(command); known != nil {
+			c.handler.stats.recordRejected(known)
+		}
+		// A command that cannot be queued makes EXEC abort the transaction
+		if c.tx != nil {
+			c.tx.failed = true
+		}
+		return errMsg
 	}
 
-	// Original RESP parsing logic
-	var cmd string
-	args := []string{}
+	if c.tx != nil && !controlsTransaction(cmd) {
+		c.tx.commands = append(c.tx.commands, queuedCommand{cmd: cmd, args: args})
+		return responses.StringMsg("QUEUED")
+	}
 
-	parser := &Parser{
-		NumberOfExpectedArguments: 0,
-		LengthOfNextArgument:      0,
-		NumberOfArgumentsParsed:   0,
+	return call(c, cmd, args)
+}
+
+// call runs the handler of a command, after feeding it to the monitors,
+// and records the call for INFO and the slow log
+func call(c *Client, cmd *Command, args []string) string {
+	start := time.Now()
+	c.handler.monitors.feed(c, cmd, args, start)
+	reply := cmd.Handler(c, args)
+	duration := time.Since(start)
+
+	c.handler.stats.recordCall(cmd, duration, strings.HasPrefix(reply, "-"))
+	c.handler.slowlog.record(c, cmd, args, start, duration)
+	return reply
+}
+
+// checkCommand looks a command up and checks the client may run it with these arguments.
+// It returns an error reply, or an empty string if the command can run.
+func checkCommand(c *Client, command string, args []string) (*Command, string) {
+	cmd := LookupCommand(command)
+	if cmd == nil {
+		return nil, responses.ErrorMsg(fmt.Sprintf("unknown command '%s'", command))
 	}
 
-	position := 0
-	for position < len(command) {
-		switch command[position] {
-		// Array of Strings
-		case '*':
-			result, err := getIntArg(position+1, command)
-			if err != nil {
-				return responses.ErrorMsg(err.Error())
-			}
-			parser.NumberOfExpectedArguments = result.Result
-			args = make([]string, parser.NumberOfExpectedArguments-1)
-			position += result.PositionsParsed
-
-		// Bulk String
-		case '$':
-			result, err := getIntArg(position+1, command)
-			if err != nil {
-				return responses.ErrorMsg(err.Error())
-			}
-			// Enforce the length of the next argument
-			parser.LengthOfNextArgument = result.Result
-			position += result.PositionsParsed
-
-		// to denote the end of the argument count
-		case '\r':
-			position++
-		// to denote the end of the argument count
-		case '\n':
-			position++
-
-		default:
-			if parser.NumberOfExpectedArguments == 0 || parser.LengthOfNextArgument == 0 || parser.NumberOfArgumentsParsed >= parser.NumberOfExpectedArguments {
-				return responses.StringMsg("Invalid syntax")
-			}
-
-			// parse it!
-			parsedItem := string(command[position : parser.LengthOfNextArgument+position])
-			if parser.NumberOfArgumentsParsed > 0 {
-				// it's an arg, add to args array
-				args[parser.NumberOfArgumentsParsed-1] = parsedItem
-			} else {
-				// The first 'arg' we parse is the primary command
-				cmd = parsedItem
-			}
-
-			position += parser.LengthOfNextArgument
-
-			parser.LengthOfNextArgument = 0
-			parser.NumberOfArgumentsParsed += 1
-		}
+	if !cmd.CheckArity(len(args) + 1) {
+		return nil, responses.ErrorMsg(fmt.Sprintf("wrong number of arguments for '%s' command", strings.ToLower(cmd.Name)))
+	}
+
+	// Until the client authenticated, only the commands needed to do so are accepted
+	if !c.authenticated && !cmd.HasFlag(FlagNoAuth) && c.handler.requiresAuth() {
+		return nil, responses.ErrorCodeMsg("NOAUTH", "Authentication required.")
 	}
-	return ParseCommand(cmd, args)
-}
 
-// parsePlainTextCommand handles commands in plain text format like "SET key value EX 30"
-func parsePlainTextCommand(command []byte) string {
-	// Trim any trailing whitespace, CR, LF
-	commandStr := strings.TrimSpace(string(command))
+	if errMsg := checkPermissions(c, cmd, args); errMsg != "" {
+		return nil, errMsg
+	}
 
-	// Split the command by whitespace
-	parts := strings.Fields(commandStr)
-	if len(parts) == 0 {
-		return responses.ErrorMsg("empty command")
+	if c.tx != nil && cmd.HasFlag(FlagNoMulti) {
+		return nil, responses.ErrorMsg("Command not allowed inside a transaction")
 	}
 
-	// The first part is the command, the rest are arguments
-	cmd := parts[0]
-	args := []string{}
-	if len(parts) > 1 {
-		args = parts[1:]
+	// RESP2 connections carry nothing but messages once subscribed
+	if c.protocol == responses.RESP2 && c.subscribed() && !allowedWhileSubscribed(cmd) {
+		return nil, responses.ErrorMsg(fmt.Sprintf("Can't execute '%s': only (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING are allowed in this context", strings.ToLower(cmd.Name)))
 	}
 
-	fmt.Printf("Parsed plain text command: %s, args: %v\n", cmd, args)
+	// Once the memory limit is reached, keys are evicted before adding data, or the command is refused
+	if cmd.HasFlag(FlagDenyOOM) && !c.handler.dbs.FreeMemory() {
+		return nil, responses.ErrorCodeMsg("OOM", "command not allowed when used memory > 'maxmemory'.")
+	}
 
-	// Pass to the command handler
-	return ParseCommand(cmd, args)
+	return cmd, ""
 }
 
-func ParseCommand(command string, args []string) string {
-	cmd := strings.ToUpper(command)
-	fmt.Printf("Received '%s' command\n", cmd)
-
-	switch cmd {
-	case "PING":
-		return PerformPong(args)
-	case "SET":
-		return PerformSet(args)
-	case "GET":
-		return PerformGet(args)
-	case "DEL":
-		return PerformDel(args)
-	case "EXISTS":
-		return PerformExists(args)
-	case "TTL":
-		return PerformTTL(args)
-	case "EXPIRE":
-		return PerformExpire(args)
-	case "GETDEL":
-		return PerformGETDEL(args)
-	case "RENAME":
-		return PerformRename(args)
-	case "WATCH":
-		return WatchCommands(args)
-	default:
-		return responses.ErrorMsg(fmt.Sprintf("unknown command '%s'", cmd))
+// checkPermissions checks the ACL user of the client may run the command on its keys.
+// It returns an error reply, logged in the ACL LOG, or an empty string if allowed.
+func checkPermissions(c *Client, cmd *Command, args []string) string {
+	// The commands needed to authenticate are available to every user
+	if cmd.HasFlag(FlagNoAuth) {
+		return ""
+	}
+
+	name := strings.ToLower(cmd.Name)
+	subcommand := ""
+	if cmd.Subcommands && len(args) > 0 {
+		subcommand = strings.ToLower(args[0])
+	}
+
+	users := c.handler.acl
+	if !users.CanRun(c.user, name, subcommand, cmd.CategoriesOf(subcommand)) {
+		object := name
+		if subcommand != "" {
+			object += "|" + subcommand
+		}
+		users.Log(acl.ReasonCommand, "toplevel", object, c.user, c.info())
+		return responses.ErrorCodeMsg("NOPERM", fmt.Sprintf("User %s has no permissions to run the '%s' command", c.user, object))
+	}
+
+	return checkKeyPermissions(c, cmd.Keys(args), cmd.HasFlag(FlagWrite))
+}
+
+// checkKeyPermissions checks the ACL user of the client may read keys, or write them too.
+// It returns an error reply, logged in the ACL LOG, or an empty string if allowed.
+func checkKeyPermissions(c *Client, keys []string, write bool) string {
+	users := c.handler.acl
+	for _, key := range keys {
+		if !users.CanAccessKey(c.user, key, write) {
+			users.Log(acl.ReasonKey, "toplevel", key, c.user, c.info())
+			return responses.ErrorCodeMsg("NOPERM", "No permissions to access a key")
+		}
 	}
+	return ""
 }
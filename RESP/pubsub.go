@@ -0,0 +1,169 @@
+package RESP
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/GedisCaching/Gedis/glob"
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// pubSub routes published messages to the clients subscribed to a channel,
+// or to a glob pattern matching it
+type pubSub struct {
+	mu       sync.RWMutex
+	channels map[string]map[*Client]struct{}
+	patterns map[string]map[*Client]struct{}
+}
+
+func newPubSub() *pubSub {
+	return &pubSub{
+		channels: make(map[string]map[*Client]struct{}),
+		patterns: make(map[string]map[*Client]struct{}),
+	}
+}
+
+// subscribe adds channels, or patterns, to the subscriptions of c
+// and confirms each of them with a push message
+func (ps *pubSub) subscribe(c *Client, names []string, pattern bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	kind, registry, subscribed := "subscribe", ps.channels, c.channels
+	if pattern {
+		kind, registry, subscribed = "psubscribe", ps.patterns, c.patterns
+	}
+
+	for _, name := range names {
+		if _, exists := subscribed[name]; !exists {
+			subscribed[name] = struct{}{}
+			if registry[name] == nil {
+				registry[name] = make(map[*Client]struct{})
+			}
+			registry[name][c] = struct{}{}
+		}
+		// The confirmation is queued under the lock, before any message of the channel
+		c.push(subscriptionMsg(c, kind, responses.BulkStringMsg(name)))
+	}
+}
+
+// unsubscribe removes channels, or patterns, from the subscriptions of c,
+// every one of them when names is empty, and confirms each of them with a push message
+func (ps *pubSub) unsubscribe(c *Client, names []string, pattern bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	kind, registry, subscribed := "unsubscribe", ps.channels, c.channels
+	if pattern {
+		kind, registry, subscribed = "punsubscribe", ps.patterns, c.patterns
+	}
+
+	if len(names) == 0 {
+		if len(subscribed) == 0 {
+			c.push(subscriptionMsg(c, kind, responses.NullMsg(c.protocol)))
+			return
+		}
+		for name := range subscribed {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		ps.remove(registry, subscribed, c, name)
+		c.push(subscriptionMsg(c, kind, responses.BulkStringMsg(name)))
+	}
+}
+
+// unsubscribeAll removes every subscription of c, without confirmation
+func (ps *pubSub) unsubscribeAll(c *Client) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	for name := range c.channels {
+		ps.remove(ps.channels, c.channels, c, name)
+	}
+	for name := range c.patterns {
+		ps.remove(ps.patterns, c.patterns, c, name)
+	}
+}
+
+// remove deletes the subscription of c to name, dropping the name once it has no subscriber
+func (ps *pubSub) remove(registry map[string]map[*Client]struct{}, subscribed map[string]struct{}, c *Client, name string) {
+	delete(subscribed, name)
+	delete(registry[name], c)
+	if len(registry[name]) == 0 {
+		delete(registry, name)
+	}
+}
+
+// publish sends a message to the subscribers of channel and returns how many received it
+func (ps *pubSub) publish(channel, message string) int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	receivers := 0
+	for c := range ps.channels[channel] {
+		c.push(responses.PushMsg(c.protocol, []string{
+			responses.BulkStringMsg("message"),
+			responses.BulkStringMsg(channel),
+			responses.BulkStringMsg(message),
+		}))
+		receivers++
+	}
+
+	for pattern, clients := range ps.patterns {
+		if !glob.Match(pattern, channel) {
+			continue
+		}
+		for c := range clients {
+			c.push(responses.PushMsg(c.protocol, []string{
+				responses.BulkStringMsg("pmessage"),
+				responses.BulkStringMsg(pattern),
+				responses.BulkStringMsg(channel),
+				responses.BulkStringMsg(message),
+			}))
+			receivers++
+		}
+	}
+	return receivers
+}
+
+// activeChannels returns the channels with at least one subscriber matching pattern, sorted
+func (ps *pubSub) activeChannels(pattern string) []string {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	channels := []string{}
+	for channel := range ps.channels {
+		if pattern == "" || glob.Match(pattern, channel) {
+			channels = append(channels, channel)
+		}
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// numSubscribers returns the number of clients subscribed to channel, patterns excluded
+func (ps *pubSub) numSubscribers(channel string) int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return len(ps.channels[channel])
+}
+
+// numPatterns returns the number of patterns with at least one subscriber
+func (ps *pubSub) numPatterns() int {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	return len(ps.patterns)
+}
+
+// subscriptionMsg formats the confirmation of a (un)subscription,
+// with the number of subscriptions the client has left
+func subscriptionMsg(c *Client, kind string, name string) string {
+	return responses.PushMsg(c.protocol, []string{
+		responses.BulkStringMsg(kind),
+		name,
+		responses.IntegerMsg(len(c.channels) + len(c.patterns)),
+	})
+}
@@ -0,0 +1,129 @@
+package RESP
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/GedisCaching/Gedis/acl"
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// ------------------------------ Pub/Sub Commands ------------------------------
+
+// PerformSubscribe subscribes the client to channels.
+// The confirmations and the messages are pushed to the client, the command itself has no reply.
+// SUBSCRIBE channel [channel ...]
+func PerformSubscribe(c *Client, args []string) string {
+	if errMsg := checkChannels(c, args, false); errMsg != "" {
+		return errMsg
+	}
+	c.handler.pubsub.subscribe(c, args, false)
+	return ""
+}
+
+// PerformPSubscribe subscribes the client to the channels matching glob patterns.
+// PSUBSCRIBE pattern [pattern ...]
+func PerformPSubscribe(c *Client, args []string) string {
+	if errMsg := checkChannels(c, args, true); errMsg != "" {
+		return errMsg
+	}
+	c.handler.pubsub.subscribe(c, args, true)
+	return ""
+}
+
+// PerformUnsubscribe unsubscribes the client from channels, or from every channel.
+// UNSUBSCRIBE [channel ...]
+func PerformUnsubscribe(c *Client, args []string) string {
+	c.handler.pubsub.unsubscribe(c, args, false)
+	return ""
+}
+
+// PerformPUnsubscribe unsubscribes the client from patterns, or from every pattern.
+// PUNSUBSCRIBE [pattern ...]
+func PerformPUnsubscribe(c *Client, args []string) string {
+	c.handler.pubsub.unsubscribe(c, args, true)
+	return ""
+}
+
+// PerformPublish sends a message to the subscribers of a channel
+// and replies with the number of clients that received it.
+// PUBLISH channel message
+func PerformPublish(c *Client, args []string) string {
+	if errMsg := checkChannels(c, args[:1], false); errMsg != "" {
+		return errMsg
+	}
+	return responses.IntegerMsg(c.handler.Publish(args[0], args[1]))
+}
+
+// PerformPubSub inspects the state of the Pub/Sub system.
+// PUBSUB CHANNELS [pattern] | PUBSUB NUMSUB [channel ...] | PUBSUB NUMPAT
+func PerformPubSub(c *Client, args []string) string {
+	ps := c.handler.pubsub
+
+	switch strings.ToUpper(args[0]) {
+	case "CHANNELS":
+		if len(args) > 2 {
+			return responses.ErrorMsg("wrong number of arguments for 'pubsub|channels' command")
+		}
+		pattern := ""
+		if len(args) == 2 {
+			pattern = args[1]
+		}
+		return responses.ArrayMsg(ps.activeChannels(pattern))
+
+	case "NUMSUB":
+		frames := make([]string, 0, (len(args)-1)*2)
+		for _, channel := range args[1:] {
+			frames = append(frames, responses.BulkStringMsg(channel), responses.IntegerMsg(ps.numSubscribers(channel)))
+		}
+		return responses.RawArrayMsg(frames)
+
+	case "NUMPAT":
+		if len(args) != 1 {
+			return responses.ErrorMsg("wrong number of arguments for 'pubsub|numpat' command")
+		}
+		return responses.IntegerMsg(ps.numPatterns())
+
+	default:
+		return responses.ErrorMsg(fmt.Sprintf("unknown subcommand '%s'. Try PUBSUB HELP.", args[0]))
+	}
+}
+
+// checkChannels checks the ACL user of the client may use every channel, or every pattern when patterns is set.
+// It returns an error reply, logged in the ACL LOG, or an empty string if allowed.
+func checkChannels(c *Client, channels []string, patterns bool) string {
+	for _, channel := range channels {
+		allowed := false
+		if patterns {
+			allowed = c.handler.acl.CanAccessPattern(c.user, channel)
+		} else {
+			allowed = c.handler.acl.CanAccessChannel(c.user, channel)
+		}
+		if !allowed {
+			c.handler.acl.Log(acl.ReasonChannel, "toplevel", channel, c.user, c.info())
+			return responses.ErrorCodeMsg("NOPERM", "No permissions to access a channel")
+		}
+	}
+	return ""
+}
+
+// subscribed reports whether the client has subscriptions, which restricts the commands
+// it can run while it uses RESP2
+func (c *Client) subscribed() bool {
+	return len(c.channels)+len(c.patterns) > 0
+}
+
+// Subscribed reports whether the client has subscriptions.
+// It must be called from the goroutine executing the client's commands.
+func (c *Client) Subscribed() bool {
+	return c.subscribed()
+}
+
+// allowedWhileSubscribed reports whether a RESP2 client with subscriptions can run a command
+func allowedWhileSubscribed(cmd *Command) bool {
+	switch cmd.Name {
+	case "SUBSCRIBE", "PSUBSCRIBE", "UNSUBSCRIBE", "PUNSUBSCRIBE", "PING":
+		return true
+	}
+	return false
+}
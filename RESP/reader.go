@@ -0,0 +1,179 @@
+package RESP
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	// maxBulkLength is the largest bulk string accepted from a client (512MB, as in Redis)
+	maxBulkLength = 512 * 1024 * 1024
+
+	// maxArrayLength is the largest number of arguments accepted in a single command
+	maxArrayLength = 1024 * 1024
+
+	// maxInlineLength is the largest inline (plain text) command accepted
+	maxInlineLength = 64 * 1024
+)
+
+// ProtocolError is returned by the Reader when the client sent bytes that
+// cannot be decoded. The connection cannot be resynchronised after it.
+type ProtocolError struct {
+	msg string
+}
+
+func (e *ProtocolError) Error() string {
+	return "Protocol error: " + e.msg
+}
+
+// Reader decodes commands from a stream one at a time. Bytes that belong to
+// the next command stay buffered, so pipelined commands are read in order and
+// a command split over several TCP segments is reassembled.
+type Reader struct {
+	rd *bufio.Reader
+}
+
+// NewReader creates a new Reader reading from rd
+func NewReader(rd io.Reader) *Reader {
+	return &Reader{rd: bufio.NewReader(rd)}
+}
+
+// Buffered returns the number of bytes already received but not yet decoded.
+// A value of zero means every pipelined command that arrived has been read.
+func (r *Reader) Buffered() int {
+	return r.rd.Buffered()
+}
+
+// Wait blocks until more input is received, without decoding it,
+// and returns the error ending the connection if it ends first
+func (r *Reader) Wait() error {
+	_, err := r.rd.Peek(1)
+	return err
+}
+
+// ReadCommand blocks until one complete command has been received and returns
+// it as a list of arguments, the first one being the command name.
+// Empty inline lines are skipped.
+func (r *Reader) ReadCommand() ([]string, error) {
+	for {
+		prefix, err := r.rd.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+
+		var args []string
+		if prefix[0] == '*' {
+			args, err = r.readArray()
+		} else {
+			args, err = r.readInline()
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(args) > 0 {
+			return args, nil
+		}
+	}
+}
+
+// readArray decodes a command sent as a RESP array of bulk strings
+func (r *Reader) readArray() ([]string, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count > maxArrayLength {
+		return nil, &ProtocolError{msg: "invalid multibulk length"}
+	}
+	if count <= 0 {
+		return nil, nil
+	}
+
+	args := make([]string, 0, count)
+	for len(args) < count {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if len(line) == 0 || line[0] != '$' {
+			return nil, &ProtocolError{msg: fmt.Sprintf("expected '$', got '%s'", firstByte(line))}
+		}
+
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 || size > maxBulkLength {
+			return nil, &ProtocolError{msg: "invalid bulk length"}
+		}
+
+		// Read the payload together with its trailing CRLF
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r.rd, buf); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if buf[size] != '\r' || buf[size+1] != '\n' {
+			return nil, &ProtocolError{msg: "bulk string not terminated by CRLF"}
+		}
+
+		args = append(args, string(buf[:size]))
+	}
+	return args, nil
+}
+
+// readInline decodes a plain text command like `SET greeting "hello world" EX 30`,
+// see SplitArgs for the quoting rules
+func (r *Reader) readInline() ([]string, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := SplitArgs(line)
+	if err != nil {
+		return nil, &ProtocolError{msg: err.Error()}
+	}
+	return args, nil
+}
+
+// readLine reads up to the next LF and returns the line without its CRLF
+func (r *Reader) readLine() (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.rd.ReadSlice('\n')
+		line = append(line, chunk...)
+		if err == nil {
+			break
+		}
+		if err != bufio.ErrBufferFull {
+			if len(line) > 0 {
+				return "", unexpectedEOF(err)
+			}
+			return "", err
+		}
+		if len(line) > maxInlineLength {
+			return "", &ProtocolError{msg: "too big inline request"}
+		}
+	}
+
+	line = bytes.TrimSuffix(line[:len(line)-1], []byte{'\r'})
+	return string(line), nil
+}
+
+// unexpectedEOF reports a connection closed in the middle of a command
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func firstByte(line string) string {
+	if len(line) == 0 {
+		return ""
+	}
+	return line[:1]
+}
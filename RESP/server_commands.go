@@ -0,0 +1,142 @@
+package RESP
+
+import (
+	"fmt"
+	"strings"
+
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// ------------------------------ Server Commands ------------------------------
+
+// PerformCommand describes the commands known to the server,
+// so client libraries and proxies can discover its capabilities.
+// COMMAND | COMMAND COUNT | COMMAND LIST | COMMAND INFO [name ...] | COMMAND DOCS [name ...]
+func PerformCommand(c *Client, args []string) string {
+	if len(args) == 0 {
+		return commandInfoMsg(c, Commands())
+	}
+
+	switch strings.ToUpper(args[0]) {
+	case "COUNT":
+		if len(args) != 1 {
+			return responses.ErrorMsg("wrong number of arguments for 'command|count' command")
+		}
+		return responses.IntegerMsg(len(commandTable))
+
+	case "LIST":
+		if len(args) != 1 {
+			return responses.ErrorMsg("wrong number of arguments for 'command|list' command")
+		}
+		commands := Commands()
+		names := make([]string, len(commands))
+		for i, cmd := range commands {
+			names[i] = strings.ToLower(cmd.Name)
+		}
+		return responses.ArrayMsg(names)
+
+	case "INFO":
+		if len(args) == 1 {
+			return commandInfoMsg(c, Commands())
+		}
+		// Unknown commands are reported as null entries
+		commands := make([]*Command, len(args)-1)
+		for i, name := range args[1:] {
+			commands[i] = LookupCommand(name)
+		}
+		return commandInfoMsg(c, commands)
+
+	case "DOCS":
+		commands := Commands()
+		if len(args) > 1 {
+			// Unknown commands are left out of the reply
+			commands = commands[:0]
+			for _, name := range args[1:] {
+				if cmd := LookupCommand(name); cmd != nil {
+					commands = append(commands, cmd)
+				}
+			}
+		}
+		return commandDocsMsg(c, commands)
+
+	default:
+		return responses.ErrorMsg(fmt.Sprintf("unknown subcommand '%s'. Try COMMAND HELP.", args[0]))
+	}
+}
+
+// commandInfoMsg formats the COMMAND INFO reply, one entry per command:
+// name, arity, flags, first key, last key, step, ACL categories, tips, key specs and subcommands
+func commandInfoMsg(c *Client, commands []*Command) string {
+	frames := make([]string, len(commands))
+	for i, cmd := range commands {
+		if cmd == nil {
+			frames[i] = responses.NullMsg(c.protocol)
+			continue
+		}
+
+		categories := cmd.Categories()
+		for j, category := range categories {
+			categories[j] = "@" + category
+		}
+
+		frames[i] = responses.RawArrayMsg([]string{
+			responses.BulkStringMsg(strings.ToLower(cmd.Name)),
+			responses.IntegerMsg(cmd.Arity),
+			responses.SetMsg(c.protocol, cmd.Flags),
+			responses.IntegerMsg(cmd.FirstKey),
+			responses.IntegerMsg(cmd.LastKey),
+			responses.IntegerMsg(cmd.Step),
+			responses.SetMsg(c.protocol, categories),
+			responses.RawArrayMsg(nil),
+			responses.RawArrayMsg(nil),
+			responses.RawArrayMsg(nil),
+		})
+	}
+	return responses.RawArrayMsg(frames)
+}
+
+// commandDocsMsg formats the COMMAND DOCS reply, a map from command name to its documentation
+func commandDocsMsg(c *Client, commands []*Command) string {
+	frames := make([]string, 0, len(commands)*2)
+	for _, cmd := range commands {
+		doc := responses.MapMsg(c.protocol, []string{
+			responses.BulkStringMsg("summary"), responses.BulkStringMsg(cmd.Summary),
+			responses.BulkStringMsg("since"), responses.BulkStringMsg(Version),
+			responses.BulkStringMsg("group"), responses.BulkStringMsg(cmd.Group),
+		})
+		frames = append(frames, responses.BulkStringMsg(strings.ToLower(cmd.Name)), doc)
+	}
+	return responses.MapMsg(c.protocol, frames)
+}
+
+// PerformShutdown stops the server: it saves the database when persistence is configured,
+// lets the commands being executed finish and closes every connection. Nothing is replied on success.
+// SHUTDOWN [NOSAVE | SAVE] [NOW] [FORCE]
+func PerformShutdown(c *Client, args []string) string {
+	var opts ShutdownOptions
+	for _, arg := range args {
+		switch strings.ToUpper(arg) {
+		case "SAVE":
+			opts.Save = true
+		case "NOSAVE":
+			opts.NoSave = true
+		case "NOW":
+			opts.Now = true
+		case "FORCE":
+			opts.Force = true
+		default:
+			return responses.ErrorMsg("syntax error")
+		}
+	}
+	if opts.Save && opts.NoSave {
+		return responses.ErrorMsg("syntax error")
+	}
+
+	if c.handler.shutdown == nil {
+		return responses.ErrorMsg("SHUTDOWN is not available without a server")
+	}
+	if err := c.handler.shutdown(opts); err != nil {
+		return responses.ErrorMsg("Errors trying to SHUTDOWN. Check logs.")
+	}
+	return ""
+}
@@ -0,0 +1,232 @@
+package RESP
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// Defaults of the slow log of a handler, see SetSlowlog
+const (
+	DefaultSlowlogThreshold = 10 * time.Millisecond
+	DefaultSlowlogMaxLen    = 128
+)
+
+// Bounds of the arguments recorded for a command, so the slow log stays small
+const (
+	slowlogMaxArgs   = 32
+	slowlogMaxArgLen = 128
+)
+
+// SlowlogEntry is a command that ran for longer than the slow log threshold
+type SlowlogEntry struct {
+	// ID identifies the entry, it increases with every entry logged since the server started
+	ID int64
+
+	// Time is when the command started and Duration how long it ran
+	Time     time.Time
+	Duration time.Duration
+
+	// Args holds the command name and its arguments, truncated and with secrets redacted
+	Args []string
+
+	// ClientAddr and ClientName identify the connection that sent the command
+	ClientAddr string
+	ClientName string
+}
+
+// slowLog keeps the last commands that ran for longer than its threshold
+type slowLog struct {
+	// threshold is the duration from which commands are logged, negative when the log is disabled
+	threshold atomic.Int64
+
+	mu      sync.Mutex
+	entries []SlowlogEntry // oldest first
+	maxLen  int
+	nextID  int64
+}
+
+func newSlowLog() *slowLog {
+	log := &slowLog{maxLen: DefaultSlowlogMaxLen}
+	log.threshold.Store(int64(DefaultSlowlogThreshold))
+	return log
+}
+
+// SetSlowlog logs the commands running for threshold or longer, a negative threshold disables the log.
+// Only the last maxLen entries are kept.
+func (h *Handler) SetSlowlog(threshold time.Duration, maxLen int) {
+	h.slowlog.threshold.Store(int64(threshold))
+
+	h.slowlog.mu.Lock()
+	defer h.slowlog.mu.Unlock()
+	h.slowlog.maxLen = maxLen
+	h.slowlog.trim()
+}
+
+// record logs the command if it ran for longer than the threshold.
+// The time blocking commands spend waiting for data is not counted, they are never logged.
+func (log *slowLog) record(c *Client, cmd *Command, args []string, start time.Time, duration time.Duration) {
+	threshold := time.Duration(log.threshold.Load())
+	if threshold < 0 || duration < threshold || cmd.HasFlag(FlagBlocking) {
+		return
+	}
+
+	entry := SlowlogEntry{
+		Time:       start,
+		Duration:   duration,
+		Args:       slowlogArgs(cmd, args),
+		ClientAddr: c.Addr(),
+		ClientName: c.Name(),
+	}
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	entry.ID = log.nextID
+	log.nextID++
+	log.entries = append(log.entries, entry)
+	log.trim()
+}
+
+// trim drops the oldest entries beyond maxLen, it is called with the lock held
+func (log *slowLog) trim() {
+	if excess := len(log.entries) - log.maxLen; excess > 0 {
+		log.entries = append([]SlowlogEntry(nil), log.entries[excess:]...)
+	}
+}
+
+// Slowlog returns up to n entries of the slow log, the newest first, or all of them when n is negative
+func (h *Handler) Slowlog(n int) []SlowlogEntry {
+	return h.slowlog.latest(n)
+}
+
+// latest returns up to n entries, the newest first, or all of them when n is negative
+func (log *slowLog) latest(n int) []SlowlogEntry {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	if n < 0 || n > len(log.entries) {
+		n = len(log.entries)
+	}
+	entries := make([]SlowlogEntry, n)
+	for i := range entries {
+		entries[i] = log.entries[len(log.entries)-1-i]
+	}
+	return entries
+}
+
+// slowlogArgs returns the arguments of a command as recorded by the slow log:
+// at most slowlogMaxArgs of them, each at most slowlogMaxArgLen bytes long
+func slowlogArgs(cmd *Command, args []string) []string {
+	args = redactArgs(cmd, args)
+	all := append([]string{cmd.Name}, args...)
+
+	recorded := all
+	if len(all) > slowlogMaxArgs {
+		recorded = append(all[:slowlogMaxArgs-1:slowlogMaxArgs-1],
+			fmt.Sprintf("... (%d more arguments)", len(all)-slowlogMaxArgs+1))
+	}
+	for i, arg := range recorded {
+		if len(arg) > slowlogMaxArgLen {
+			recorded[i] = fmt.Sprintf("%s... (%d more bytes)", arg[:slowlogMaxArgLen], len(arg)-slowlogMaxArgLen)
+		}
+	}
+	return recorded
+}
+
+// redactArgs returns the arguments of a command with the passwords they hold replaced,
+// so they are not exposed to the clients reading the slow log or the MONITOR feed
+func redactArgs(cmd *Command, args []string) []string {
+	redacted := append([]string(nil), args...)
+	hide := func(from, to int) {
+		for i := from; i < to && i < len(redacted); i++ {
+			redacted[i] = "(redacted)"
+		}
+	}
+
+	switch cmd.Name {
+	case "AUTH":
+		hide(0, len(redacted))
+	case "HELLO":
+		for i := 1; i < len(redacted); i++ {
+			if strings.EqualFold(redacted[i], "AUTH") {
+				hide(i+1, i+3)
+			}
+		}
+	case "ACL":
+		if len(redacted) > 0 && strings.EqualFold(redacted[0], "SETUSER") {
+			hide(2, len(redacted))
+		}
+	case "CONFIG":
+		if len(redacted) > 0 && strings.EqualFold(redacted[0], "SET") {
+			for i := 1; i+1 < len(redacted); i += 2 {
+				if strings.EqualFold(redacted[i], "requirepass") {
+					hide(i+1, i+2)
+				}
+			}
+		}
+	}
+	return redacted
+}
+
+// PerformSlowlog reads and resets the slow log.
+// SLOWLOG GET [count] | SLOWLOG LEN | SLOWLOG RESET
+func PerformSlowlog(c *Client, args []string) string {
+	log := c.handler.slowlog
+
+	switch strings.ToUpper(args[0]) {
+	case "GET":
+		if len(args) > 2 {
+			return responses.ErrorMsg("wrong number of arguments for 'slowlog|get' command")
+		}
+		count := 10
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				return responses.ErrorMsg("value is not an integer or out of range")
+			}
+			if n < -1 {
+				return responses.ErrorMsg("count should be greater than or equal to -1")
+			}
+			count = n
+		}
+
+		entries := log.latest(count)
+		frames := make([]string, len(entries))
+		for i, entry := range entries {
+			frames[i] = responses.RawArrayMsg([]string{
+				responses.IntegerMsg(int(entry.ID)),
+				responses.IntegerMsg(int(entry.Time.Unix())),
+				responses.IntegerMsg(int(entry.Duration.Microseconds())),
+				responses.ArrayMsg(entry.Args),
+				responses.BulkStringMsg(entry.ClientAddr),
+				responses.BulkStringMsg(entry.ClientName),
+			})
+		}
+		return responses.RawArrayMsg(frames)
+
+	case "LEN":
+		if len(args) != 1 {
+			return responses.ErrorMsg("wrong number of arguments for 'slowlog|len' command")
+		}
+		log.mu.Lock()
+		defer log.mu.Unlock()
+		return responses.IntegerMsg(len(log.entries))
+
+	case "RESET":
+		if len(args) != 1 {
+			return responses.ErrorMsg("wrong number of arguments for 'slowlog|reset' command")
+		}
+		log.mu.Lock()
+		defer log.mu.Unlock()
+		log.entries = nil
+		return responses.StringMsg("OK")
+
+	default:
+		return responses.ErrorMsg(fmt.Sprintf("unknown subcommand '%s'. Try SLOWLOG HELP.", args[0]))
+	}
+}
@@ -0,0 +1,92 @@
+package RESP
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// ------------------------------ Sorted Set Commands ------------------------------
+
+// PerformZAdd adds one or more score-member pairs to a sorted set
+// and returns the number of members that were added
+func PerformZAdd(c *Client, args []string) string {
+	if len(args)%2 != 1 {
+		return responses.ErrorMsg("wrong number of arguments for 'ZADD' command")
+	}
+
+	scoreMembers := make(map[string]float64, len(args)/2)
+	for i := 1; i < len(args); i += 2 {
+		score, err := strconv.ParseFloat(args[i], 64)
+		if err != nil || math.IsNaN(score) {
+			return responses.ErrorMsg("value is not a valid float")
+		}
+		scoreMembers[args[i+1]] = score
+	}
+
+	added, err := c.db.ZADDChecked(args[0], scoreMembers)
+	if err != nil {
+		return responses.WrongTypeMsg()
+	}
+	return responses.IntegerMsg(added)
+}
+
+// PerformZRange returns the members of a sorted set between start and stop (inclusive),
+// ordered from the lowest to the highest score.
+// With WITHSCORES every member is followed by its score.
+func PerformZRange(c *Client, args []string) string {
+	if len(args) > 4 {
+		return responses.ErrorMsg("syntax error")
+	}
+
+	start, err1 := strconv.Atoi(args[1])
+	stop, err2 := strconv.Atoi(args[2])
+	if err1 != nil || err2 != nil {
+		return responses.ErrorMsg("value is not an integer or out of range")
+	}
+
+	withScores := false
+	if len(args) == 4 {
+		if strings.ToUpper(args[3]) != "WITHSCORES" {
+			return responses.ErrorMsg("syntax error")
+		}
+		withScores = true
+	}
+
+	items := c.db.ZRANGE(args[0], start, stop, withScores)
+	if len(items) == 0 && !hasType(c, args[0], "zset") {
+		return responses.WrongTypeMsg()
+	}
+	if !withScores {
+		return responses.ArrayMsg(toStrings(items))
+	}
+
+	// RESP3 clients receive [member, score] pairs with the score as a double,
+	// RESP2 clients a flat list of members and scores
+	frames := make([]string, 0, len(items))
+	for i := 0; i+1 < len(items); i += 2 {
+		member := responses.BulkStringMsg(formatValue(items[i]))
+		score := responses.DoubleMsg(c.protocol, items[i+1].(float64))
+		if c.protocol == responses.RESP3 {
+			frames = append(frames, responses.RawArrayMsg([]string{member, score}))
+		} else {
+			frames = append(frames, member, score)
+		}
+	}
+	return responses.RawArrayMsg(frames)
+}
+
+// PerformZRank returns the rank of a member in a sorted set,
+// or a nil reply if the member doesn't exist
+func PerformZRank(c *Client, args []string) string {
+	rank, exists := c.db.ZRANK(args[0], args[1])
+	if !exists {
+		if !hasType(c, args[0], "zset") {
+			return responses.WrongTypeMsg()
+		}
+		return responses.NullMsg(c.protocol)
+	}
+	return responses.IntegerMsg(rank)
+}
@@ -0,0 +1,140 @@
+package RESP
+
+import (
+	responses "github.com/GedisCaching/Gedis/responses"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// ------------------------------ Transaction Commands ------------------------------
+
+// transaction holds the commands a client queued since MULTI
+type transaction struct {
+	commands []queuedCommand
+
+	// failed is set when a command could not be queued, EXEC then aborts
+	failed bool
+}
+
+// queuedCommand is a command checked at queue time, waiting for EXEC
+type queuedCommand struct {
+	cmd  *Command
+	args []string
+}
+
+// controlsTransaction reports whether a command runs immediately inside a transaction
+// instead of being queued
+func controlsTransaction(cmd *Command) bool {
+	switch cmd.Name {
+	case "MULTI", "EXEC", "DISCARD", "WATCH":
+		return true
+	}
+	return false
+}
+
+// PerformMulti starts a transaction, the following commands are queued until EXEC.
+// MULTI
+func PerformMulti(c *Client, args []string) string {
+	if c.tx != nil {
+		return responses.ErrorMsg("MULTI calls can not be nested")
+	}
+	c.tx = &transaction{}
+	return responses.StringMsg("OK")
+}
+
+// PerformExec runs every queued command atomically: no command of another client
+// runs in between. The reply holds the reply of each command, in order.
+// EXEC
+func PerformExec(c *Client, args []string) string {
+	if c.tx == nil {
+		return responses.ErrorMsg("EXEC without MULTI")
+	}
+
+	tx := c.tx
+	c.tx = nil
+	if tx.failed {
+		c.unwatch()
+		return responses.ErrorCodeMsg("EXECABORT", "Transaction discarded because of previous errors.")
+	}
+
+	var replies []string
+	aborted := false
+	c.dbs.Atomic(func(dbs *storage.Databases) {
+		// A watched key written since WATCH aborts the transaction.
+		// The keys are unwatched before the commands run, so UNWATCH in the queue is a no-op.
+		for index, w := range c.watches {
+			db := dbs.DB(index)
+			aborted = db.Touched(w) || aborted
+			db.Unwatch(w)
+		}
+		c.watches = nil
+		if aborted {
+			return
+		}
+
+		// The handlers run against the locked views of the databases,
+		// SELECT in the queue switches between them
+		shared := c.dbs
+		c.dbs, c.db = dbs, dbs.DB(c.dbIndex)
+		defer func() { c.dbs, c.db = shared, shared.DB(c.dbIndex) }()
+
+		replies = make([]string, len(tx.commands))
+		for i, queued := range tx.commands {
+			// Permissions may have changed since the command was queued
+			if errMsg := checkPermissions(c, queued.cmd, queued.args); errMsg != "" {
+				replies[i] = errMsg
+				continue
+			}
+			replies[i] = call(c, queued.cmd, queued.args)
+		}
+	})
+
+	if aborted {
+		return responses.NullArrayMsg(c.protocol)
+	}
+	return responses.RawArrayMsg(replies)
+}
+
+// PerformDiscard drops the queued commands and ends the transaction.
+// DISCARD
+func PerformDiscard(c *Client, args []string) string {
+	if c.tx == nil {
+		return responses.ErrorMsg("DISCARD without MULTI")
+	}
+	c.tx = nil
+	c.unwatch()
+	return responses.StringMsg("OK")
+}
+
+// PerformWatch watches keys of the selected database for the next transaction:
+// EXEC fails if any of them is written, deleted or expires before it runs.
+// WATCH key [key ...]
+func PerformWatch(c *Client, args []string) string {
+	if c.tx != nil {
+		return responses.ErrorMsg("WATCH inside MULTI is not allowed")
+	}
+	if c.watches == nil {
+		c.watches = make(map[int]*storage.Watch)
+	}
+	w, exists := c.watches[c.dbIndex]
+	if !exists {
+		w = &storage.Watch{}
+		c.watches[c.dbIndex] = w
+	}
+	c.db.Watch(w, args...)
+	return responses.StringMsg("OK")
+}
+
+// PerformUnwatch forgets every watched key.
+// UNWATCH
+func PerformUnwatch(c *Client, args []string) string {
+	c.unwatch()
+	return responses.StringMsg("OK")
+}
+
+// unwatch forgets the keys watched by the client
+func (c *Client) unwatch() {
+	for index, w := range c.watches {
+		c.dbs.DB(index).Unwatch(w)
+	}
+	c.watches = nil
+}
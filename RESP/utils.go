@@ -1,54 +1,67 @@
 package RESP
 
 import (
-	"errors"
+	"fmt"
 	"strconv"
-	"strings"
 )
 
-type GetIntArgResult struct {
-	Result          int // parsing operation
-	PositionsParsed int // How many indexes were parsed
+// formatValue converts a value stored through the Go API into the string
+// sent to network clients
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	default:
+		return fmt.Sprint(v)
+	}
 }
 
-// getIntArg parses an integer from the byte array starting at the given position
-// and returns the result and the number of positions parsed.
-func getIntArg(startPosition int, arr []byte) (*GetIntArgResult, error) {
-	var err error
-	result := &GetIntArgResult{
-		Result:          0,
-		PositionsParsed: 0,
+// isStringValue reports whether a stored value can be read with string commands.
+// Lists and hashes are stored as slices and maps and need their own commands.
+func isStringValue(value interface{}) bool {
+	switch value.(type) {
+	case []interface{}, map[string]interface{}:
+		return false
+	default:
+		return true
 	}
+}
 
-	// Get digits until termination characters
-	notDone := true
-	position := startPosition
-
-	var stringVal strings.Builder
-	for position < len(arr) && notDone {
-		// Check for literal "\r\n" string (4 characters)
-		if position+3 < len(arr) && arr[position] == '\\' && arr[position+1] == 'r' && arr[position+2] == '\\' && arr[position+3] == 'n' {
-			notDone = false
-			result.PositionsParsed += 4 // Skip over "\r\n"
-		} else if position+1 < len(arr) && arr[position] == '\r' && arr[position+1] == '\n' {
-			notDone = false
-			result.PositionsParsed += 2 // Skip over CR+LF
-		} else {
-			stringVal.WriteByte(arr[position])
-			result.PositionsParsed += 1
-			position += 1
-		}
-	}
+// hasType reports whether key holds the wanted type or doesn't exist yet.
+// Reads check it once they found nothing at key, to tell a missing key from one of another type.
+// Writes rely on the type check the storage operations make under their lock instead.
+func hasType(c *Client, key string, want string) bool {
+	kind := c.db.Type(key)
+	return kind == want || kind == "none"
+}
 
-	if stringVal.Len() == 0 {
-		return nil, errors.New("no value was detected")
+// toValues converts command arguments into values for the storage layer
+func toValues(args []string) []interface{} {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg
 	}
+	return values
+}
 
-	resultInt, err := strconv.Atoi(stringVal.String())
-	if err != nil {
-		return nil, errors.New("failed to parse int")
+// toStrings converts values returned by the storage layer into reply elements
+func toStrings(values []interface{}) []string {
+	result := make([]string, len(values))
+	for i, value := range values {
+		result[i] = formatValue(value)
 	}
-
-	result.Result = resultInt
-	return result, nil
+	return result
 }
@@ -0,0 +1,358 @@
+package acl
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GedisCaching/Gedis/glob"
+)
+
+// DefaultUser is the user new connections are authenticated as
+const DefaultUser = "default"
+
+// Categories lists the command categories rules can refer to with +@category
+var Categories = []string{
+	"all", "read", "write", "admin", "dangerous", "fast", "slow", "keyspace",
+	"string", "list", "hash", "sortedset", "connection", "transaction",
+	"pubsub", "blocking", "scripting",
+}
+
+// ACL holds the users allowed to connect, with their permissions,
+// and the log of denied requests.
+type ACL struct {
+	mu    sync.RWMutex
+	users map[string]*user
+
+	// knownCommand validates the command names used in rules
+	knownCommand func(name string) bool
+
+	logMu       sync.Mutex
+	log         []*LogEntry // newest first
+	nextEntryID int
+}
+
+// New creates a new ACL with only the default user, which can run every command
+// on every key without a password. knownCommand is used to validate the command
+// names given in rules.
+func New(knownCommand func(name string) bool) *ACL {
+	a := &ACL{
+		users:        make(map[string]*user),
+		knownCommand: knownCommand,
+	}
+
+	defaultUser := newUser(DefaultUser)
+	for _, rule := range []string{"on", "nopass", "allkeys", "allchannels", "allcommands"} {
+		if err := defaultUser.apply(rule, knownCommand); err != nil {
+			panic(err)
+		}
+	}
+	a.users[DefaultUser] = defaultUser
+	return a
+}
+
+// SetUser creates or modifies a user by applying rules in order.
+// Either every rule is applied or, if one is invalid, none is.
+func (a *ACL) SetUser(name string, rules ...string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	u, exists := a.users[name]
+	if !exists {
+		u = newUser(name)
+	}
+
+	// Work on a copy so an invalid rule leaves the user untouched
+	updated := u.clone()
+	for _, rule := range rules {
+		if err := updated.apply(rule, a.knownCommand); err != nil {
+			return fmt.Errorf("Error in ACL SETUSER modifier '%s': %v", rule, err)
+		}
+	}
+
+	a.users[name] = updated
+	return nil
+}
+
+// DeleteUsers removes users and returns how many existed.
+// The default user cannot be removed.
+func (a *ACL) DeleteUsers(names ...string) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, name := range names {
+		if name == DefaultUser {
+			return 0, errors.New("The 'default' user cannot be removed")
+		}
+	}
+
+	deleted := 0
+	for _, name := range names {
+		if _, exists := a.users[name]; exists {
+			delete(a.users, name)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// GetUser returns a snapshot of a user, or nil if it doesn't exist
+func (a *ACL) GetUser(name string) *UserInfo {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	u, exists := a.users[name]
+	if !exists {
+		return nil
+	}
+	return u.info()
+}
+
+// Users returns the names of every user, sorted
+func (a *ACL) Users() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	names := make([]string, 0, len(a.users))
+	for name := range a.users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// List returns every user described as ACL rules, sorted by name
+func (a *ACL) List() []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	names := make([]string, 0, len(a.users))
+	for name := range a.users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	descriptions := make([]string, len(names))
+	for i, name := range names {
+		descriptions[i] = a.users[name].describe()
+	}
+	return descriptions
+}
+
+// RequiresAuth reports whether new connections must authenticate,
+// which is the case unless the default user is enabled and has no password
+func (a *ACL) RequiresAuth() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	u := a.users[DefaultUser]
+	return !u.enabled || !u.nopass
+}
+
+// Authenticate checks a username and password.
+// The passwords are compared in constant time.
+func (a *ACL) Authenticate(name, password string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	u, exists := a.users[name]
+	if !exists {
+		// Still hash the password so unknown users take as long as known ones
+		hashPassword(password)
+		return false
+	}
+	return u.checkPassword(password) && u.enabled
+}
+
+// CanRun reports whether the user may run a command, given its lower case name,
+// its subcommand (empty if it has none) and the categories it belongs to
+func (a *ACL) CanRun(name, command, subcommand string, categories []string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	u, exists := a.users[name]
+	if !exists || !u.enabled {
+		return false
+	}
+	return u.canRun(command, subcommand, categories)
+}
+
+// CanAccessKey reports whether the user may read, or write, a key
+func (a *ACL) CanAccessKey(name, key string, write bool) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	u, exists := a.users[name]
+	if !exists {
+		return false
+	}
+
+	for _, pattern := range u.keys {
+		if (write && pattern.write || !write && pattern.read) && glob.Match(pattern.pattern, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessChannel reports whether the user may publish or subscribe to a channel
+func (a *ACL) CanAccessChannel(name, channel string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	u, exists := a.users[name]
+	if !exists {
+		return false
+	}
+
+	for _, pattern := range u.channels {
+		if glob.Match(pattern, channel) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanAccessPattern reports whether the user may subscribe to a channel pattern.
+// Unlike a channel, a pattern must equal one of the user's patterns,
+// or else it could match channels the user has no access to.
+func (a *ACL) CanAccessPattern(name, pattern string) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	u, exists := a.users[name]
+	if !exists {
+		return false
+	}
+
+	for _, allowed := range u.channels {
+		// allchannels grants every pattern
+		if allowed == "*" || allowed == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// hashPassword returns the hex encoded SHA-256 of a password,
+// the form passwords are stored and shown in
+func hashPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+// constantTimeEqual compares two password hashes in constant time
+func constantTimeEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// ------------------------------ ACL LOG ------------------------------
+
+// Reasons a request is denied, as shown in the ACL LOG
+const (
+	ReasonAuth    = "auth"
+	ReasonCommand = "command"
+	ReasonKey     = "key"
+	ReasonChannel = "channel"
+)
+
+// maxLogEntries bounds the number of entries kept in the ACL LOG
+const maxLogEntries = 128
+
+// logGroupingWindow is how long a repeated denial updates the previous entry
+// instead of creating a new one
+const logGroupingWindow = 60 * time.Second
+
+// LogEntry records a denied request
+type LogEntry struct {
+	Count       int
+	Reason      string
+	Context     string
+	Object      string
+	Username    string
+	ClientInfo  string
+	EntryID     int
+	Created     time.Time
+	LastUpdated time.Time
+}
+
+// Log records a denied request. A denial identical to a recent one
+// only increments the count of the existing entry.
+func (a *ACL) Log(reason, context, object, username, clientInfo string) {
+	a.logMu.Lock()
+	defer a.logMu.Unlock()
+
+	now := time.Now()
+	for _, entry := range a.log {
+		if entry.Reason == reason && entry.Context == context && entry.Object == object &&
+			entry.Username == username && now.Sub(entry.LastUpdated) < logGroupingWindow {
+			entry.Count++
+			entry.LastUpdated = now
+			entry.ClientInfo = clientInfo
+			return
+		}
+	}
+
+	entry := &LogEntry{
+		Count:       1,
+		Reason:      reason,
+		Context:     context,
+		Object:      object,
+		Username:    username,
+		ClientInfo:  clientInfo,
+		EntryID:     a.nextEntryID,
+		Created:     now,
+		LastUpdated: now,
+	}
+	a.nextEntryID++
+
+	a.log = append([]*LogEntry{entry}, a.log...)
+	if len(a.log) > maxLogEntries {
+		a.log = a.log[:maxLogEntries]
+	}
+}
+
+// LogEntries returns up to count entries of the ACL LOG, newest first
+func (a *ACL) LogEntries(count int) []LogEntry {
+	a.logMu.Lock()
+	defer a.logMu.Unlock()
+
+	if count < 0 || count > len(a.log) {
+		count = len(a.log)
+	}
+
+	entries := make([]LogEntry, count)
+	for i := range entries {
+		entries[i] = *a.log[i]
+	}
+	return entries
+}
+
+// ResetLog clears the ACL LOG
+func (a *ACL) ResetLog() {
+	a.logMu.Lock()
+	defer a.logMu.Unlock()
+	a.log = nil
+}
+
+// IsCategory reports whether name is a known command category
+func IsCategory(name string) bool {
+	for _, category := range Categories {
+		if category == name {
+			return true
+		}
+	}
+	return false
+}
+
+// splitCommand splits a rule target like "config|get" into command and subcommand
+func splitCommand(target string) (string, string) {
+	command, subcommand, _ := strings.Cut(strings.ToLower(target), "|")
+	return command, subcommand
+}
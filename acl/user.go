@@ -0,0 +1,360 @@
+package acl
+
+import (
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// user is a set of credentials and the permissions granted with them
+type user struct {
+	name    string
+	enabled bool
+
+	// nopass lets the user authenticate with any password
+	nopass bool
+
+	// passwords holds the hex encoded SHA-256 of every valid password
+	passwords map[string]struct{}
+
+	// commands holds the +/- command rules, applied in order
+	commands []commandRule
+
+	// keys holds the key patterns the user can access
+	keys []keyPattern
+
+	// channels holds the Pub/Sub channel patterns the user can access
+	channels []string
+}
+
+// commandRule allows or denies a command, a subcommand or a category
+type commandRule struct {
+	allow      bool
+	category   string
+	command    string
+	subcommand string
+}
+
+// keyPattern grants read and/or write access to the keys matching a glob pattern
+type keyPattern struct {
+	pattern string
+	read    bool
+	write   bool
+}
+
+// UserInfo is a snapshot of a user as shown by ACL GETUSER
+type UserInfo struct {
+	Name      string
+	Flags     []string
+	Passwords []string
+	Commands  string
+	Keys      string
+	Channels  string
+}
+
+// newUser creates a user that is disabled and has no permission
+func newUser(name string) *user {
+	return &user{
+		name:      name,
+		passwords: make(map[string]struct{}),
+	}
+}
+
+// clone returns a deep copy of the user
+func (u *user) clone() *user {
+	c := *u
+	c.passwords = make(map[string]struct{}, len(u.passwords))
+	for hash := range u.passwords {
+		c.passwords[hash] = struct{}{}
+	}
+	c.commands = append([]commandRule(nil), u.commands...)
+	c.keys = append([]keyPattern(nil), u.keys...)
+	c.channels = append([]string(nil), u.channels...)
+	return &c
+}
+
+// apply modifies the user according to a single ACL rule
+func (u *user) apply(rule string, knownCommand func(string) bool) error {
+	switch strings.ToLower(rule) {
+	case "on":
+		u.enabled = true
+		return nil
+	case "off":
+		u.enabled = false
+		return nil
+	case "nopass":
+		u.nopass = true
+		u.passwords = make(map[string]struct{})
+		return nil
+	case "resetpass":
+		u.nopass = false
+		u.passwords = make(map[string]struct{})
+		return nil
+	case "allkeys":
+		u.keys = []keyPattern{{pattern: "*", read: true, write: true}}
+		return nil
+	case "resetkeys":
+		u.keys = nil
+		return nil
+	case "allchannels":
+		u.channels = []string{"*"}
+		return nil
+	case "resetchannels":
+		u.channels = nil
+		return nil
+	case "allcommands":
+		u.commands = []commandRule{{allow: true, category: "all"}}
+		return nil
+	case "nocommands":
+		u.commands = []commandRule{{allow: false, category: "all"}}
+		return nil
+	case "reset":
+		for _, r := range []string{"resetpass", "resetkeys", "resetchannels", "nocommands", "off"} {
+			u.apply(r, knownCommand)
+		}
+		return nil
+	}
+
+	if rule == "" {
+		return errors.New("Syntax error")
+	}
+
+	switch {
+	case rule[0] == '>':
+		u.passwords[hashPassword(rule[1:])] = struct{}{}
+		u.nopass = false
+
+	case rule[0] == '<':
+		delete(u.passwords, hashPassword(rule[1:]))
+
+	case rule[0] == '#' || rule[0] == '!':
+		hash := strings.ToLower(rule[1:])
+		if decoded, err := hex.DecodeString(hash); err != nil || len(decoded) != 32 {
+			return errors.New("The password hash must be exactly 64 characters and contain only lowercase hexadecimal characters")
+		}
+		if rule[0] == '#' {
+			u.passwords[hash] = struct{}{}
+			u.nopass = false
+		} else {
+			delete(u.passwords, hash)
+		}
+
+	case rule[0] == '~':
+		u.addKeyPattern(keyPattern{pattern: rule[1:], read: true, write: true})
+
+	case rule[0] == '%':
+		permissions, pattern, found := strings.Cut(rule[1:], "~")
+		if !found || permissions == "" {
+			return errors.New("Syntax error")
+		}
+		key := keyPattern{pattern: pattern}
+		for _, p := range strings.ToUpper(permissions) {
+			switch p {
+			case 'R':
+				key.read = true
+			case 'W':
+				key.write = true
+			default:
+				return errors.New("Syntax error")
+			}
+		}
+		u.addKeyPattern(key)
+
+	case rule[0] == '&':
+		u.channels = append(u.channels, rule[1:])
+
+	case rule[0] == '+' || rule[0] == '-':
+		allow := rule[0] == '+'
+		target := rule[1:]
+
+		if strings.HasPrefix(target, "@") {
+			category := strings.ToLower(target[1:])
+			if !IsCategory(category) {
+				return errors.New("Unknown command or category name in ACL")
+			}
+			if category == "all" {
+				// +@all and -@all override every previous rule
+				u.commands = nil
+			}
+			u.commands = append(u.commands, commandRule{allow: allow, category: category})
+			return nil
+		}
+
+		command, subcommand := splitCommand(target)
+		if knownCommand != nil && !knownCommand(command) {
+			return errors.New("Unknown command or category name in ACL")
+		}
+		u.commands = append(u.commands, commandRule{allow: allow, command: command, subcommand: subcommand})
+
+	default:
+		return errors.New("Syntax error")
+	}
+	return nil
+}
+
+// addKeyPattern adds a key pattern, merging the permissions of an identical pattern
+func (u *user) addKeyPattern(key keyPattern) {
+	for i := range u.keys {
+		if u.keys[i].pattern == key.pattern {
+			u.keys[i].read = u.keys[i].read || key.read
+			u.keys[i].write = u.keys[i].write || key.write
+			return
+		}
+	}
+	u.keys = append(u.keys, key)
+}
+
+// checkPassword compares password with every password of the user in constant time
+func (u *user) checkPassword(password string) bool {
+	hash := hashPassword(password)
+
+	valid := u.nopass
+	for stored := range u.passwords {
+		if constantTimeEqual(hash, stored) {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// canRun applies the command rules in order, the last matching rule wins
+func (u *user) canRun(command, subcommand string, categories []string) bool {
+	allowed := false
+	for _, rule := range u.commands {
+		if rule.matches(command, subcommand, categories) {
+			allowed = rule.allow
+		}
+	}
+	return allowed
+}
+
+// matches reports whether the rule applies to a command
+func (r commandRule) matches(command, subcommand string, categories []string) bool {
+	if r.category != "" {
+		if r.category == "all" {
+			return true
+		}
+		for _, category := range categories {
+			if category == r.category {
+				return true
+			}
+		}
+		return false
+	}
+
+	if r.command != command {
+		return false
+	}
+	return r.subcommand == "" || r.subcommand == subcommand
+}
+
+// String formats the rule as it is written in ACL SETUSER
+func (r commandRule) String() string {
+	sign := "-"
+	if r.allow {
+		sign = "+"
+	}
+	if r.category != "" {
+		return sign + "@" + r.category
+	}
+	if r.subcommand != "" {
+		return sign + r.command + "|" + r.subcommand
+	}
+	return sign + r.command
+}
+
+// String formats the key pattern as it is written in ACL SETUSER
+func (k keyPattern) String() string {
+	switch {
+	case k.read && k.write:
+		return "~" + k.pattern
+	case k.read:
+		return "%R~" + k.pattern
+	default:
+		return "%W~" + k.pattern
+	}
+}
+
+// flags returns the on/off and nopass flags of the user
+func (u *user) flags() []string {
+	flags := []string{"off"}
+	if u.enabled {
+		flags[0] = "on"
+	}
+	if u.nopass {
+		flags = append(flags, "nopass")
+	}
+	return flags
+}
+
+// sortedPasswords returns the password hashes in a stable order
+func (u *user) sortedPasswords() []string {
+	hashes := make([]string, 0, len(u.passwords))
+	for hash := range u.passwords {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+// describeCommands formats the command rules, "-@all" when there is none
+func (u *user) describeCommands() string {
+	if len(u.commands) == 0 {
+		return "-@all"
+	}
+	rules := make([]string, len(u.commands))
+	for i, rule := range u.commands {
+		rules[i] = rule.String()
+	}
+	return strings.Join(rules, " ")
+}
+
+// describeKeys formats the key patterns
+func (u *user) describeKeys() string {
+	patterns := make([]string, len(u.keys))
+	for i, key := range u.keys {
+		patterns[i] = key.String()
+	}
+	return strings.Join(patterns, " ")
+}
+
+// describeChannels formats the channel patterns
+func (u *user) describeChannels() string {
+	patterns := make([]string, len(u.channels))
+	for i, channel := range u.channels {
+		patterns[i] = "&" + channel
+	}
+	return strings.Join(patterns, " ")
+}
+
+// describe formats the user as a single line of ACL rules, as shown by ACL LIST
+func (u *user) describe() string {
+	parts := []string{"user", u.name}
+	parts = append(parts, u.flags()...)
+	for _, hash := range u.sortedPasswords() {
+		parts = append(parts, "#"+hash)
+	}
+	if keys := u.describeKeys(); keys != "" {
+		parts = append(parts, keys)
+	}
+	if channels := u.describeChannels(); channels != "" {
+		parts = append(parts, channels)
+	} else {
+		parts = append(parts, "resetchannels")
+	}
+	parts = append(parts, u.describeCommands())
+	return strings.Join(parts, " ")
+}
+
+// info returns a snapshot of the user
+func (u *user) info() *UserInfo {
+	return &UserInfo{
+		Name:      u.name,
+		Flags:     u.flags(),
+		Passwords: u.sortedPasswords(),
+		Commands:  u.describeCommands(),
+		Keys:      u.describeKeys(),
+		Channels:  u.describeChannels(),
+	}
+}
@@ -1,14 +1,21 @@
 package gedis
 
 import (
+	"context"
 	"time"
 
+	"github.com/GedisCaching/Gedis/RESP"
 	redis "github.com/GedisCaching/Gedis/server"
+	"github.com/GedisCaching/Gedis/storage"
 )
 
-// Gedis represents a Redis-like database server with operations
+// Gedis represents a Redis-like database server with operations.
+// The operations run against database 0 of the server, or the one chosen with Select.
 type Gedis struct {
 	server *redis.Server
+
+	// index is the number of the database the operations run against
+	index int
 }
 
 type Config struct {
@@ -38,30 +45,75 @@ func NewGedis(config Config) (*Gedis, error) {
 	return &Gedis{server: server}, nil
 }
 
+// db returns the database the operations run against
+func (g *Gedis) db() *storage.Database {
+	return g.server.GetDatabases().DB(g.index)
+}
+
+// ----------------------- Database Operations -----------------------
+
+// Select returns a Gedis running its operations against the database at index of the same server,
+// like the SELECT command of a connection
+func (g *Gedis) Select(index int) (*Gedis, error) {
+	if g.server.GetDatabases().DB(index) == nil {
+		return nil, storage.ErrIndexOutOfRange
+	}
+	return &Gedis{server: g.server, index: index}, nil
+}
+
+// Move moves a key with its expiry to the database at index.
+// It returns false if the key doesn't exist or the destination already holds it.
+func (g *Gedis) Move(key string, index int) (bool, error) {
+	g.server.UpdateAccessTime()
+	dest := g.server.GetDatabases().DB(index)
+	if dest == nil {
+		return false, storage.ErrIndexOutOfRange
+	}
+	return g.db().Move(key, dest)
+}
+
+// SwapDB swaps the keys of two databases atomically
+func (g *Gedis) SwapDB(index1, index2 int) error {
+	g.server.UpdateAccessTime()
+	return g.server.GetDatabases().Swap(index1, index2)
+}
+
+// FlushDB deletes every key of the database
+func (g *Gedis) FlushDB() {
+	g.server.UpdateAccessTime()
+	g.db().Flush()
+}
+
+// FlushAll deletes every key of every database of the server
+func (g *Gedis) FlushAll() {
+	g.server.UpdateAccessTime()
+	g.server.GetDatabases().FlushAll()
+}
+
 // ----------------------- SET function -----------------------
 
 // SET function
 func (g *Gedis) Set(key string, value interface{}) {
 	g.server.UpdateAccessTime()
-	g.server.GetDB().Set(key, value)
+	g.db().Set(key, value)
 }
 
 // SetWithExpiry function
 func (g *Gedis) SetWithExpiry(key string, value interface{}, expiry time.Duration) {
 	g.server.UpdateAccessTime()
-	g.server.GetDB().SetWithExpiry(key, value, expiry)
+	g.db().SetWithExpiry(key, value, expiry)
 }
 
 // DEXPIRE function
 func (g *Gedis) DEXPIRE(key string, expiry time.Duration) error {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().DEXPIRE(key, expiry)
+	return g.db().DEXPIRE(key, expiry)
 }
 
 // RENAME function
 func (g *Gedis) RENAME(KeyOld, KeyNew string) error {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().RENAME(KeyOld, KeyNew)
+	return g.db().RENAME(KeyOld, KeyNew)
 }
 
 // ----------------------- GET, DEL, KEYS Operations -----------------------
@@ -69,25 +121,25 @@ func (g *Gedis) RENAME(KeyOld, KeyNew string) error {
 // GET function
 func (g *Gedis) Get(key string) (interface{}, bool) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().Get(key)
+	return g.db().Get(key)
 }
 
 // GETDEL function
 func (g *Gedis) GETDEL(key string) (interface{}, bool) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().GETDEL(key)
+	return g.db().GETDEL(key)
 }
 
 // DEL function
 func (g *Gedis) Delete(key string) bool {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().Delete(key)
+	return g.db().Delete(key)
 }
 
 // KEYS function
 func (g *Gedis) Keys() []string {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().Keys()
+	return g.db().Keys()
 }
 
 // ----------------------- NUMERIC Operations -----------------------
@@ -95,13 +147,13 @@ func (g *Gedis) Keys() []string {
 // INCR function
 func (g *Gedis) Incr(key string) (int, error) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().Incr(key)
+	return g.db().Incr(key)
 }
 
 // DECR function
 func (g *Gedis) Decr(key string) (int, error) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().Decr(key)
+	return g.db().Decr(key)
 }
 
 // ----------------------- List Operations -----------------------
@@ -109,43 +161,61 @@ func (g *Gedis) Decr(key string) (int, error) {
 // LPUSH function
 func (g *Gedis) LPush(key string, values ...interface{}) (int, error) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().LPush(key, values...)
+	return g.db().LPush(key, values...)
 }
 
 // RPUSH function
 func (g *Gedis) RPush(key string, values ...interface{}) (int, error) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().RPush(key, values...)
+	return g.db().RPush(key, values...)
 }
 
 // LRANGE function
 func (g *Gedis) LRange(key string, start, stop int) ([]interface{}, error) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().LRange(key, start, stop)
+	return g.db().LRange(key, start, stop)
 }
 
 // LPOP function
 func (g *Gedis) LPop(key string) (interface{}, error) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().LPop(key)
+	return g.db().LPop(key)
 }
 
 // RPOP function
 func (g *Gedis) RPop(key string) (interface{}, error) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().RPop(key)
+	return g.db().RPop(key)
 }
 
 // GET list length
 func (g *Gedis) LLen(key string) (int, error) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().LLen(key)
+	return g.db().LLen(key)
 }
 
 // SET list element
 func (g *Gedis) LSet(key string, index int, value interface{}) error {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().LSet(key, index, value)
+	return g.db().LSet(key, index, value)
+}
+
+// BLPOP function, waits for an element until ctx is done and then returns ctx.Err()
+func (g *Gedis) BLPop(ctx context.Context, keys ...string) (string, interface{}, error) {
+	g.server.UpdateAccessTime()
+	return g.db().BLPop(ctx, keys...)
+}
+
+// BRPOP function, waits for an element until ctx is done and then returns ctx.Err()
+func (g *Gedis) BRPop(ctx context.Context, keys ...string) (string, interface{}, error) {
+	g.server.UpdateAccessTime()
+	return g.db().BRPop(ctx, keys...)
+}
+
+// BLMOVE function, waits for an element until ctx is done and then returns ctx.Err()
+func (g *Gedis) BLMove(ctx context.Context, source, destination string, fromLeft, toLeft bool) (interface{}, error) {
+	g.server.UpdateAccessTime()
+	return g.db().BLMove(ctx, source, destination, fromLeft, toLeft)
 }
 
 // ------------------------- TTL Operations -----------------------
@@ -153,7 +223,7 @@ func (g *Gedis) LSet(key string, index int, value interface{}) error {
 // TTL function
 func (g *Gedis) TTL(key string) (time.Duration, bool) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().TTL(key)
+	return g.db().TTL(key)
 }
 
 // ------------------------- Sorted Set Operations -----------------------
@@ -161,19 +231,25 @@ func (g *Gedis) TTL(key string) (time.Duration, bool) {
 // ZADD function
 func (g *Gedis) ZAdd(key string, scoreMembers map[string]float64) int {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().ZADD(key, scoreMembers)
+	return g.db().ZADD(key, scoreMembers)
+}
+
+// ZAddChecked is ZAdd returning storage.ErrWrongType when key holds another type of value
+func (g *Gedis) ZAddChecked(key string, scoreMembers map[string]float64) (int, error) {
+	g.server.UpdateAccessTime()
+	return g.db().ZADDChecked(key, scoreMembers)
 }
 
 // ZRANGE function
 func (g *Gedis) ZRange(key string, start, stop int, withScores bool) []interface{} {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().ZRANGE(key, start, stop, withScores)
+	return g.db().ZRANGE(key, start, stop, withScores)
 }
 
 // ZRANK function
 func (g *Gedis) ZRank(key, member string) (int, bool) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().ZRANK(key, member)
+	return g.db().ZRANK(key, member)
 }
 
 // -------------------------- Hash Operations -----------------------
@@ -181,41 +257,73 @@ func (g *Gedis) ZRank(key, member string) (int, bool) {
 // HSET sets the value of a field in a hash
 func (g *Gedis) HSET(key string, field string, value interface{}) (bool, error) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().HSET(key, field, value)
+	return g.db().HSET(key, field, value)
 }
 
 // HGET retrieves the value of a field in a hash
 func (g *Gedis) HGET(key string, field string) (interface{}, bool) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().HGET(key, field)
+	return g.db().HGET(key, field)
 }
 
 // HDEL deletes a field from a hash
 func (g *Gedis) HDEL(key string, field string) (bool, error) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().HDEL(key, field)
+	return g.db().HDEL(key, field)
 }
 
 // HGETALL retrieves all fields and values in a hash
 func (g *Gedis) HGETALL(key string) (map[string]interface{}, bool) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().HGETALL(key)
+	return g.db().HGETALL(key)
 }
 
 // HKEYS retrieves all field names in a hash
 func (g *Gedis) HKEYS(key string) ([]string, bool) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().HKEYS(key)
+	return g.db().HKEYS(key)
 }
 
 // HVALS retrieves all values in a hash
 func (g *Gedis) HVALS(key string) ([]interface{}, bool) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().HVALS(key)
+	return g.db().HVALS(key)
 }
 
 // HLEN retrieves the number of fields in a hash
 func (g *Gedis) HLEN(key string) (int, bool) {
 	g.server.UpdateAccessTime()
-	return g.server.GetDB().HLEN(key)
+	return g.db().HLEN(key)
+}
+
+// -------------------------- Pub/Sub Operations -----------------------
+
+// PUBLISH sends a message to the network clients subscribed to a channel
+// and returns how many received it
+func (g *Gedis) PUBLISH(channel, message string) int {
+	g.server.UpdateAccessTime()
+	return g.server.GetHandler().Publish(channel, message)
+}
+
+// -------------------------- Function Operations -----------------------
+
+// RegisterFunction registers a Go function network clients can call with FCALL
+func (g *Gedis) RegisterFunction(fn RESP.Function) error {
+	g.server.UpdateAccessTime()
+	return g.server.GetHandler().RegisterFunction(fn)
+}
+
+// FCALL runs a registered function atomically against the selected database,
+// with the keys it declares and its arguments
+func (g *Gedis) FCALL(name string, keys []string, args ...string) (interface{}, error) {
+	g.server.UpdateAccessTime()
+	return g.server.GetHandler().CallFunction(g.index, name, keys, args...)
+}
+
+// -------------------------- Server Operations -----------------------
+
+// INFO returns the statistics of the server in the layout of the INFO command,
+// the default sections when none is given
+func (g *Gedis) INFO(sections ...string) string {
+	return g.server.GetHandler().Info(sections...)
 }
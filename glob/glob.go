@@ -0,0 +1,111 @@
+package glob
+
+// Match reports whether str matches the Redis-style glob pattern.
+// The pattern supports:
+// - '*' matching any sequence of characters, including none
+// - '?' matching exactly one character
+// - '[abc]', '[a-z]' and '[^abc]' matching one character of a set
+// - '\' escaping the next character
+// Unlike path.Match, '/' has no special meaning.
+//
+// A '*' is matched without recursion: on a mismatch, the last star seen
+// takes one more character and matching resumes after it. Earlier stars never
+// need to be revisited, so a match takes O(len(pattern) * len(str)) at worst.
+func Match(pattern, str string) bool {
+	p, s := 0, 0
+	star, starS := -1, 0
+	for s < len(str) {
+		if p < len(pattern) && pattern[p] == '*' {
+			// Remember where to resume, with the star matching nothing for now
+			for p < len(pattern) && pattern[p] == '*' {
+				p++
+			}
+			star, starS = p, s
+			continue
+		}
+
+		if p < len(pattern) {
+			if matched, width := matchOne(pattern[p:], str[s]); matched {
+				p += width
+				s++
+				continue
+			}
+		}
+
+		// Backtrack: the last star takes one more character
+		if star < 0 {
+			return false
+		}
+		starS++
+		p, s = star, starS
+	}
+
+	// The rest of the pattern must match the empty string
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// matchOne matches c against the element of the pattern starting at its first byte, which is not a '*'.
+// It returns whether c matched and the length of the element in the pattern.
+func matchOne(pattern string, c byte) (bool, int) {
+	switch pattern[0] {
+	case '?':
+		return true, 1
+
+	case '[':
+		matched, rest := matchClass(pattern[1:], c)
+		return matched, len(pattern) - len(rest)
+
+	case '\\':
+		if len(pattern) >= 2 {
+			return pattern[1] == c, 2
+		}
+	}
+	return pattern[0] == c, 1
+}
+
+// matchClass matches c against a character class whose opening bracket was consumed.
+// It returns whether c matched and the pattern following the closing bracket.
+func matchClass(pattern string, c byte) (bool, string) {
+	negate := false
+	if len(pattern) > 0 && pattern[0] == '^' {
+		negate = true
+		pattern = pattern[1:]
+	}
+
+	matched := false
+	for len(pattern) > 0 && pattern[0] != ']' {
+		switch {
+		case pattern[0] == '\\' && len(pattern) >= 2:
+			if pattern[1] == c {
+				matched = true
+			}
+			pattern = pattern[2:]
+
+		case len(pattern) >= 3 && pattern[1] == '-' && pattern[2] != ']':
+			start, end := pattern[0], pattern[2]
+			if start > end {
+				start, end = end, start
+			}
+			if c >= start && c <= end {
+				matched = true
+			}
+			pattern = pattern[3:]
+
+		default:
+			if pattern[0] == c {
+				matched = true
+			}
+			pattern = pattern[1:]
+		}
+	}
+
+	// Skip the closing bracket, an unterminated class runs to the end of the pattern
+	if len(pattern) > 0 {
+		pattern = pattern[1:]
+	}
+
+	return matched != negate, pattern
+}
@@ -1,58 +1,138 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
-	"net"
 	"os"
+	"os/signal"
+	"syscall"
 
-	"github.com/GedisCaching/Gedis/RESP"
+	redis "github.com/GedisCaching/Gedis/server"
 )
 
 const defaultAddress = "0.0.0.0:7000"
 
-func main() {
-	l, err := net.Listen("tcp", defaultAddress)
-	if err != nil {
-		fmt.Printf("Error starting server: %v\n", err)
-		os.Exit(1)
+// paramFlag is a command-line flag setting a configuration parameter
+type paramFlag struct {
+	param  string
+	value  string
+	isBool bool
+}
+
+func (f *paramFlag) String() string {
+	return f.value
+}
+
+// Set checks the value is valid for the parameter, it is set once the configuration file was read
+func (f *paramFlag) Set(value string) error {
+	var scratch redis.Config
+	if err := scratch.Set(f.param, value); err != nil {
+		return err
 	}
+	f.value = value
+	return nil
+}
+
+func (f *paramFlag) IsBoolFlag() bool {
+	return f.isBool
+}
 
-	fmt.Println("Starting server at", defaultAddress)
+// paramFlags lists the flags and the parameters of gedis.conf they set
+var paramFlags = []struct {
+	name   string
+	param  string
+	isBool bool
+	usage  string
+}{
+	{"address", "address", false, "address accepting TCP connections (default " + defaultAddress + ")"},
+	{"password", "requirepass", false, "password clients must send with AUTH before running commands"},
+	{"databases", "databases", false, "number of databases (default 16)"},
+	{"maxmemory", "maxmemory", false, "memory limit of the keys like 100mb, unlimited by default"},
+	{"maxmemory-policy", "maxmemory-policy", false, "keys evicted once the memory limit is reached: noeviction, allkeys-random, volatile-random or volatile-ttl (default noeviction)"},
+	{"timeout", "timeout", false, "close the connections idle for that many seconds, never by default"},
+	{"slowlog-log-slower-than", "slowlog-log-slower-than", false, "log the commands running for that many microseconds or more, -1 disables the slow log (default 10000)"},
+	{"slowlog-max-len", "slowlog-max-len", false, "number of commands kept in the slow log (default 128)"},
+	{"unix-socket", "unixsocket", false, "path of a Unix domain socket accepting connections"},
+	{"unix-socket-perm", "unixsocketperm", false, "octal permissions of the Unix socket file, like 770"},
+	{"disable-tcp", "disable-tcp", true, "don't listen on the TCP address, only on the Unix socket or TLS address"},
+	{"tls-address", "tls-address", false, "address accepting TLS connections, by default TLS replaces plaintext on the main address"},
+	{"tls-cert-file", "tls-cert-file", false, "PEM certificate of the server, enables TLS"},
+	{"tls-key-file", "tls-key-file", false, "PEM private key of the server certificate"},
+	{"tls-ca-cert-file", "tls-ca-cert-file", false, "PEM certificates of the authorities client certificates are checked against"},
+	{"tls-auth-clients", "tls-auth-clients", true, "require clients to present a certificate signed by the CA"},
+	{"notify-keyspace-events", "notify-keyspace-events", false, "key changes published to Pub/Sub, like KEA, disabled by default"},
+	{"dbfilename", "dbfilename", false, "file the database is saved to on shutdown and loaded from on start, disabled by default"},
+	{"shutdown-timeout", "shutdown-timeout", false, "how long shutting down waits for the commands being executed, like 30s (default 10s)"},
+}
+
+func main() {
+	configFile := flag.String("config", "", "gedis.conf file to read the configuration from, the other flags override it")
+	for _, f := range paramFlags {
+		flag.Var(&paramFlag{param: f.param, isBool: f.isBool}, f.name, f.usage)
+	}
+	flag.Parse()
 
-	// Listen for inputs and respond
-	for {
-		conn, err := l.Accept()
-		if err != nil {
-			fmt.Printf("Error accepting connection: %v\n", err)
-			continue
+	config := &redis.Config{Address: defaultAddress}
+	if *configFile != "" {
+		if err := redis.LoadConfigFile(*configFile, config); err != nil {
+			fmt.Printf("Error reading the configuration: %v\n", err)
+			os.Exit(1)
 		}
-		go handleConnection(conn)
 	}
-}
+	flag.Visit(func(f *flag.Flag) {
+		if pf, ok := f.Value.(*paramFlag); ok {
+			// The value was checked when the flags were parsed
+			config.Set(pf.param, pf.value)
+		}
+	})
 
-func handleConnection(conn net.Conn) {
-	defer conn.Close()
-	buf := make([]byte, 2014) // store out stuff somewhere
+	// The network clients share the database of the server registered for this address,
+	// so keys written through the embedded API are visible over TCP
+	srv, err := redis.NewServer(config)
+	if err != nil {
+		fmt.Printf("Error creating server: %v\n", err)
+		os.Exit(1)
+	}
 
-	for {
-		len, err := conn.Read(buf)
-		if err != nil {
-			if err != io.EOF {
-				fmt.Printf("Error reading: %#v\n", err)
+	// SIGHUP reloads the TLS certificates, so they can be renewed without a restart
+	if srv.GetConfig().TLSEnabled() {
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		go func() {
+			for range reload {
+				if err := srv.ReloadTLS(); err != nil {
+					fmt.Printf("Error reloading TLS certificates: %v\n", err)
+					continue
+				}
+				fmt.Println("Reloaded TLS certificates")
 			}
-			break
-		}
+		}()
+	}
 
-		// Parse the command out
-		command := buf[:len]
-		response := RESP.Parse(command)
+	// SIGINT and SIGTERM shut the server down gracefully, like SHUTDOWN:
+	// the commands being executed complete and the database is saved
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig := <-stop
+		fmt.Printf("Received %v, shutting down\n", sig)
+		ctx, cancel := context.WithTimeout(context.Background(), srv.Settings().ShutdownTimeout)
+		defer cancel()
+		srv.Close(ctx)
+	}()
 
-		// Write the response back to the connection
-		_, Reserr := conn.Write([]byte(fmt.Sprintf("%v\r\n", response)))
-		if Reserr != nil {
-			fmt.Printf("Error reading: %#v\n", Reserr)
-			break
-		}
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, redis.ErrServerClosed) {
+		fmt.Printf("Error starting server: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Wait for the connections to be drained and the database saved
+	<-srv.Done()
+	if err := srv.Close(context.Background()); err != nil {
+		fmt.Printf("Error shutting down: %v\n", err)
+		os.Exit(1)
 	}
+	fmt.Println("Server stopped")
 }
@@ -2,18 +2,46 @@ package responses
 
 import (
 	"fmt"
+	"math"
+	"strconv"
+	"strings"
 )
 
+// Every helper returns a complete RESP frame, terminated by CRLF,
+// which can be written to the connection as is.
+
+// StringMsg formats a RESP simple string, used for status replies like OK
 func StringMsg(msg string) string {
-	return "+" + msg
+	return "+" + msg + "\r\n"
 }
 
+// ErrorMsg formats a generic RESP error
 func ErrorMsg(msg string) string {
-	return fmt.Sprintf("-ERR %s", msg)
+	return ErrorCodeMsg("ERR", msg)
+}
+
+// ErrorCodeMsg formats a RESP error starting with a specific error code,
+// like WRONGTYPE or NOAUTH, that clients use to tell errors apart
+func ErrorCodeMsg(code string, msg string) string {
+	// Errors are single line, a newline would end the frame early
+	msg = strings.NewReplacer("\r", " ", "\n", " ").Replace(msg)
+	return fmt.Sprintf("-%s %s\r\n", code, msg)
+}
+
+// WrongTypeMsg formats the error returned when a command is used
+// against a key holding another data type
+func WrongTypeMsg() string {
+	return ErrorCodeMsg("WRONGTYPE", "Operation against a key holding the wrong kind of value")
 }
 
+// BulkStringMsg formats a binary-safe RESP bulk string
+func BulkStringMsg(msg string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(msg), msg)
+}
+
+// NilBulkStringMsg formats the nil reply returned for missing values
 func NilBulkStringMsg() string {
-	return "$-1"
+	return "$-1\r\n"
 }
 
 // ArrayMsg formats a slice of strings as a RESP array
@@ -27,5 +55,97 @@ func ArrayMsg(elements []string) string {
 
 // IntegerMsg formats an integer as a RESP integer
 func IntegerMsg(n int) string {
-	return fmt.Sprintf(":%d", n)
+	return fmt.Sprintf(":%d\r\n", n)
+}
+
+// ------------------------------ RESP3 ------------------------------
+
+// Protocol versions a connection can negotiate with HELLO
+const (
+	RESP2 = 2
+	RESP3 = 3
+)
+
+// RawArrayMsg formats an array whose elements are already formatted frames,
+// used for nested and mixed-type replies
+func RawArrayMsg(frames []string) string {
+	return aggregateMsg('*', len(frames), frames)
+}
+
+// NullMsg formats a missing value, a null in RESP3 and a nil bulk string in RESP2
+func NullMsg(proto int) string {
+	if proto == RESP3 {
+		return "_\r\n"
+	}
+	return NilBulkStringMsg()
+}
+
+// NullArrayMsg formats a missing array, like the reply of an aborted EXEC,
+// a null in RESP3 and a nil array in RESP2
+func NullArrayMsg(proto int) string {
+	if proto == RESP3 {
+		return "_\r\n"
+	}
+	return "*-1\r\n"
+}
+
+// DoubleMsg formats a floating point number, a double in RESP3 and a bulk string in RESP2
+func DoubleMsg(proto int, f float64) string {
+	var value string
+	switch {
+	case math.IsInf(f, 1):
+		value = "inf"
+	case math.IsInf(f, -1):
+		value = "-inf"
+	default:
+		value = strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	if proto == RESP3 {
+		return "," + value + "\r\n"
+	}
+	return BulkStringMsg(value)
+}
+
+// MapMsg formats key-value pairs given as alternating formatted frames,
+// a map in RESP3 and a flat array in RESP2
+func MapMsg(proto int, frames []string) string {
+	if proto == RESP3 {
+		return aggregateMsg('%', len(frames)/2, frames)
+	}
+	return RawArrayMsg(frames)
+}
+
+// SetMsg formats unordered unique strings, a set in RESP3 and an array in RESP2
+func SetMsg(proto int, elements []string) string {
+	frames := make([]string, len(elements))
+	for i, element := range elements {
+		frames[i] = BulkStringMsg(element)
+	}
+
+	if proto == RESP3 {
+		return aggregateMsg('~', len(frames), frames)
+	}
+	return RawArrayMsg(frames)
+}
+
+// PushMsg formats an out-of-band message sent without a matching request,
+// a push frame in RESP3 and an array in RESP2
+func PushMsg(proto int, frames []string) string {
+	if proto == RESP3 {
+		return aggregateMsg('>', len(frames), frames)
+	}
+	return RawArrayMsg(frames)
+}
+
+// aggregateMsg formats an aggregate type header followed by its frames
+func aggregateMsg(kind byte, size int, frames []string) string {
+	var b strings.Builder
+	b.WriteByte(kind)
+	b.WriteString(strconv.Itoa(size))
+	b.WriteString("\r\n")
+	for _, frame := range frames {
+		b.WriteString(frame)
+	}
+	return b.String()
 }
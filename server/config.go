@@ -1,8 +1,102 @@
 package redis
 
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
 type Config struct {
 	Address  string
 	Password string
+
+	// UnixSocket is the path of a Unix domain socket accepting connections, alongside Address
+	UnixSocket string
+
+	// UnixSocketPerm sets the permissions of the socket file, the umask applies when it is 0
+	UnixSocketPerm os.FileMode
+
+	// DisableTCP stops the server from listening on Address,
+	// so clients can only connect through UnixSocket or TLSAddress
+	DisableTCP bool
+
+	// TLSAddress is where TLS connections are accepted, alongside plaintext ones on Address.
+	// When it is empty and a certificate is configured, Address only accepts TLS connections.
+	TLSAddress string
+
+	// TLSCertFile and TLSKeyFile are the PEM encoded certificate and private key of the server.
+	// TLS is enabled once both are set.
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// TLSCACertFile holds the PEM encoded certificates of the authorities client certificates are checked against
+	TLSCACertFile string
+
+	// TLSAuthClients requires clients to present a certificate signed by one of the TLSCACertFile authorities
+	TLSAuthClients bool
+
+	// NotifyKeyspaceEvents selects the key changes published to the __keyspace@0__ and __keyevent@0__ channels,
+	// with the flags of the Redis notify-keyspace-events setting like "KEA". They are disabled when it is empty.
+	NotifyKeyspaceEvents string
+
+	// DBFilename is the file the database is saved to when the server shuts down,
+	// and loaded from when it is created. Persistence is disabled when it is empty.
+	DBFilename string
+
+	// ShutdownTimeout bounds how long SHUTDOWN waits for the commands being executed,
+	// 10 seconds when it is 0
+	ShutdownTimeout time.Duration
+
+	// Databases is the number of databases, 16 when it is 0
+	Databases int
+
+	// MaxMemory limits the estimated memory used by the keys, in bytes. There is no limit when it is 0.
+	// MaxMemoryPolicy selects the keys evicted once the limit is reached, noeviction when it is empty.
+	MaxMemory       int64
+	MaxMemoryPolicy storage.EvictionPolicy
+
+	// Timeout closes the connections that sent no command for that long,
+	// connections are never closed for being idle when it is 0
+	Timeout time.Duration
+
+	// SlowlogLogSlowerThan is the duration from which commands are recorded in the slow log,
+	// 10ms when it is 0. The slow log is disabled when it is negative.
+	// SlowlogMaxLen is the number of commands it keeps, 128 when it is 0.
+	SlowlogLogSlowerThan time.Duration
+	SlowlogMaxLen        int
+
+	// ConfigFile is the gedis.conf file the configuration was read from, see LoadConfigFile.
+	// CONFIG REWRITE saves the parameters changed at runtime to it.
+	ConfigFile string
+}
+
+// Defaults of the fields left to 0
+const (
+	defaultShutdownTimeout = 10 * time.Second
+	defaultDatabases       = 16
+)
+
+// withDefaults returns a copy of c where the fields left to 0 hold their default
+func (c Config) withDefaults() Config {
+	if c.ShutdownTimeout == 0 {
+		c.ShutdownTimeout = defaultShutdownTimeout
+	}
+	if c.Databases == 0 {
+		c.Databases = defaultDatabases
+	}
+	if c.MaxMemoryPolicy == "" {
+		c.MaxMemoryPolicy = storage.NoEviction
+	}
+	if c.SlowlogLogSlowerThan == 0 {
+		c.SlowlogLogSlowerThan = RESP.DefaultSlowlogThreshold
+	}
+	if c.SlowlogMaxLen == 0 {
+		c.SlowlogMaxLen = RESP.DefaultSlowlogMaxLen
+	}
+	return c
 }
 
 func DefaultConfig() *Config {
@@ -11,3 +105,36 @@ func DefaultConfig() *Config {
 		Password: "",
 	}
 }
+
+// TLSEnabled reports whether the server accepts TLS connections
+func (c Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// validateTLS checks the TLS options are consistent
+func (c Config) validateTLS() error {
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errors.New("TLS requires both a certificate and a private key")
+	}
+	if !c.TLSEnabled() && (c.TLSAddress != "" || c.TLSCACertFile != "" || c.TLSAuthClients) {
+		return errors.New("TLS options require a certificate and a private key")
+	}
+	if c.TLSAuthClients && c.TLSCACertFile == "" {
+		return errors.New("TLS client authentication requires a CA certificate")
+	}
+	return nil
+}
+
+// validateListeners checks the server has at least one way to accept connections
+func (c Config) validateListeners() error {
+	if c.DisableTCP && c.UnixSocket == "" && c.TLSAddress == "" {
+		return errors.New("disabling TCP requires a Unix socket or a TLS address")
+	}
+	if c.UnixSocketPerm != 0 && c.UnixSocket == "" {
+		return errors.New("Unix socket permissions require a Unix socket path")
+	}
+	if c.UnixSocketPerm&^os.ModePerm != 0 {
+		return errors.New("invalid Unix socket permissions")
+	}
+	return nil
+}
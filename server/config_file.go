@@ -0,0 +1,159 @@
+package redis
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/GedisCaching/Gedis/RESP"
+)
+
+// rewriteMarker precedes the parameters CONFIG REWRITE appends to the configuration file
+const rewriteMarker = "# Generated by CONFIG REWRITE"
+
+// LoadConfigFile sets the parameters found in a gedis.conf file on config, and records the file
+// as its ConfigFile. Every line holds a parameter and its value, like "maxmemory 100mb".
+// Values are quoted like redis-cli arguments, and lines starting with # are comments.
+func LoadConfigFile(path string, config *Config) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		name, value, ok, err := parseConfigLine(scanner.Text())
+		if err == nil && ok {
+			err = config.Set(name, value)
+		}
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	config.ConfigFile = path
+	return nil
+}
+
+// parseConfigLine returns the parameter set by a line of gedis.conf and its value,
+// ok is false for blank lines and comments
+func parseConfigLine(line string) (name, value string, ok bool, err error) {
+	if strings.HasPrefix(strings.TrimSpace(line), "#") {
+		return "", "", false, nil
+	}
+	args, err := RESP.SplitArgs(line)
+	if err != nil {
+		return "", "", false, err
+	}
+	if len(args) == 0 {
+		return "", "", false, nil
+	}
+	if len(args) != 2 {
+		return "", "", false, fmt.Errorf("wrong number of arguments for '%s'", args[0])
+	}
+	return args[0], args[1], true, nil
+}
+
+// rewriteConfigFile saves settings to the configuration file at path. The lines setting a parameter
+// are updated in place, comments and blank lines are kept, and the parameters missing from the file
+// are appended when their value differs from the default.
+func rewriteConfigFile(path string, settings Config) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	var lines []string
+	written := make(map[string]bool)
+	hasMarker := false
+	if len(content) > 0 {
+		for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+			hasMarker = hasMarker || line == rewriteMarker
+			name, _, ok, err := parseConfigLine(line)
+			p := lookupParam(name)
+			if err != nil || !ok || p == nil {
+				lines = append(lines, line)
+				continue
+			}
+			// A parameter set twice keeps its first line
+			if !written[p.name] {
+				lines = append(lines, configLine(p, &settings))
+				written[p.name] = true
+			}
+		}
+	}
+
+	defaults := Config{}.withDefaults()
+	for i := range params {
+		p := &params[i]
+		if written[p.name] || p.get(&settings) == p.get(&defaults) {
+			continue
+		}
+		if !hasMarker {
+			lines = append(lines, rewriteMarker)
+			hasMarker = true
+		}
+		lines = append(lines, configLine(p, &settings))
+	}
+
+	return writeFileAtomic(path, func(w io.Writer) error {
+		_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+		return err
+	})
+}
+
+// configLine formats the line of gedis.conf setting a parameter to its value in settings
+func configLine(p *param, settings *Config) string {
+	return p.name + " " + quoteConfigValue(p.get(settings))
+}
+
+// quoteConfigValue quotes a value when needed, so RESP.SplitArgs reads it back unchanged
+func quoteConfigValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\r\n\"'\\") && isPrintable(value) {
+		return value
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\r':
+			sb.WriteString(`\r`)
+		case c == '\t':
+			sb.WriteString(`\t`)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&sb, `\x%02x`, c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// isPrintable reports whether value only holds printable ASCII characters
+func isPrintable(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] < ' ' || value[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
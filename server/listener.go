@@ -0,0 +1,313 @@
+package redis
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	responses "github.com/GedisCaching/Gedis/responses"
+)
+
+// ErrServerClosed is returned by the Serve methods once Close was called
+var ErrServerClosed = errors.New("gedis: server closed")
+
+// ListenAndServe accepts network clients on the configured addresses:
+// plaintext connections on Address, TLS connections on TLSAddress and local ones on UnixSocket.
+// Without a TLSAddress, Address only accepts TLS connections when a certificate is configured.
+// It blocks until a listener fails or Close is called, the socket file is removed on return.
+func (s *Server) ListenAndServe() error {
+	var listeners []net.Listener
+	closeAll := func() {
+		for _, l := range listeners {
+			l.Close()
+		}
+	}
+
+	if !s.config.DisableTCP && (s.tls == nil || s.config.TLSAddress != "") {
+		l, err := net.Listen("tcp", s.config.Address)
+		if err != nil {
+			return err
+		}
+		listeners = append(listeners, l)
+	}
+
+	if s.tls != nil && (!s.config.DisableTCP || s.config.TLSAddress != "") {
+		address := s.config.TLSAddress
+		if address == "" {
+			address = s.config.Address
+		}
+		l, err := net.Listen("tcp", address)
+		if err != nil {
+			closeAll()
+			return err
+		}
+		listeners = append(listeners, tls.NewListener(l, s.tls.tlsConfig()))
+	}
+
+	if s.config.UnixSocket != "" {
+		l, err := listenUnix(s.config.UnixSocket, s.config.UnixSocketPerm)
+		if err != nil {
+			closeAll()
+			return err
+		}
+		listeners = append(listeners, l)
+	}
+
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		fmt.Println("Starting server at", l.Addr())
+		go func(l net.Listener) {
+			errs <- s.Serve(l)
+		}(l)
+	}
+
+	// The first listener to stop takes the others down with it
+	err := <-errs
+	closeAll()
+	return err
+}
+
+// Serve accepts connections on l and serves each of them in its own goroutine.
+// It blocks until l fails or Close is called.
+func (s *Server) Serve(l net.Listener) error {
+	if !s.trackListener(l) {
+		l.Close()
+		return ErrServerClosed
+	}
+	defer s.untrackListener(l)
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				fmt.Printf("Error accepting connection: %v\n", err)
+				continue
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+// listenUnix listens on a Unix domain socket, replacing the socket file left by a previous run.
+// The file is removed when the listener is closed.
+func listenUnix(path string, perm os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	if perm != 0 {
+		if err := os.Chmod(path, perm); err != nil {
+			l.Close()
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// TLSConfig returns the configuration of the TLS listener, nil when TLS is disabled.
+// It can wrap listeners passed to Serve and always uses the latest loaded certificates.
+func (s *Server) TLSConfig() *tls.Config {
+	if s.tls == nil {
+		return nil
+	}
+	return s.tls.tlsConfig()
+}
+
+// ReloadTLS reads the certificate, private key and CA certificate files again.
+// New connections use them, established ones are not affected.
+func (s *Server) ReloadTLS() error {
+	if s.tls == nil {
+		return errors.New("TLS is not enabled")
+	}
+	return s.tls.reload()
+}
+
+// trackListener registers l so Close can stop it, it reports false once the server is closed
+func (s *Server) trackListener(l net.Listener) bool {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	if s.closed {
+		return false
+	}
+	s.listeners[l] = struct{}{}
+	return true
+}
+
+// untrackListener forgets a listener that stopped serving
+func (s *Server) untrackListener(l net.Listener) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	delete(s.listeners, l)
+}
+
+// isClosed reports whether Close was called
+func (s *Server) isClosed() bool {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	return s.closed
+}
+
+// serveConn serves a client with a pair of goroutines: this one reads and executes
+// the commands, while the writer sends their replies along with the messages published
+// to the client's subscriptions, in the order they were produced
+func (s *Server) serveConn(conn net.Conn) {
+	if !s.trackConn(conn) {
+		conn.Close()
+		return
+	}
+	defer s.untrackConn(conn)
+
+	client := s.handler.NewClient()
+	client.SetAddr(conn.RemoteAddr().String(), conn.LocalAddr().String())
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		writeOutput(conn, client)
+
+		// Closing the connection also stops the reader when the client was killed
+		conn.Close()
+	}()
+
+	s.readCommands(conn, client)
+
+	// The writer sends what is left in the queue, then hangs up
+	client.Close()
+	<-written
+}
+
+// readCommands executes the commands of a client until the connection ends
+func (s *Server) readCommands(conn net.Conn, client *RESP.Client) {
+	reader := RESP.NewReader(conn)
+
+	for {
+		// Connections idle for longer than the timeout are closed, except those of subscribers and monitors.
+		// The deadline is set before checking the server is open, so it can't replace the one
+		// interrupting the read on shutdown.
+		var deadline time.Time
+		if timeout := s.Settings().Timeout; timeout > 0 && !client.Subscribed() && !client.Monitoring() {
+			deadline = time.Now().Add(timeout)
+		}
+		conn.SetReadDeadline(deadline)
+
+		// Once the server shuts down, the commands not yet started are dropped
+		if s.isClosed() {
+			return
+		}
+
+		// Read exactly one complete command, leftover bytes stay buffered
+		args, err := reader.ReadCommand()
+		if err != nil {
+			var protoErr *RESP.ProtocolError
+			if errors.As(err, &protoErr) {
+				// The stream cannot be resynchronised, report and hang up
+				client.Send(responses.ErrorMsg(protoErr.Error()))
+			} else if err != io.EOF && !errors.Is(err, os.ErrDeadlineExceeded) && !client.Killed() && !s.isClosed() {
+				fmt.Printf("Error reading: %#v\n", err)
+			}
+			return
+		}
+
+		s.UpdateAccessTime()
+
+		stop := func() {}
+		if cmd := RESP.LookupCommand(args[0]); cmd != nil && cmd.HasFlag(RESP.FlagBlocking) {
+			stop = watchHangup(conn, reader, client)
+		}
+
+		// Commands like SUBSCRIBE queue their replies themselves
+		response := RESP.ParseCommand(client, args[0], args[1:])
+		stop()
+		if response != "" {
+			if !client.Send(response) {
+				return
+			}
+		}
+	}
+}
+
+// watchHangup kills client if its connection ends while a blocking command waits,
+// which stops the command and unregisters it from the lists it waits on.
+// The returned function stops watching, it must be called before reading the next command.
+func watchHangup(conn net.Conn, reader *RESP.Reader, client *RESP.Client) func() {
+	// Blocked clients are not closed by the idle timeout
+	conn.SetReadDeadline(time.Time{})
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		// Input pipelined after the command ends the watch, it is read once the command returns
+		if err := reader.Wait(); err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+			client.Kill()
+		}
+	}()
+
+	return func() {
+		conn.SetReadDeadline(time.Now())
+		<-stopped
+	}
+}
+
+// writeOutput writes the output of a client to the connection until it is done
+func writeOutput(conn net.Conn, client *RESP.Client) {
+	writer := bufio.NewWriter(conn)
+	output := client.Output()
+
+	write := func(frame string) bool {
+		if _, err := writer.WriteString(frame); err != nil {
+			client.Kill()
+			return false
+		}
+		// Flush once every queued frame was written, so pipelined replies are batched
+		if len(output) == 0 {
+			if err := writer.Flush(); err != nil {
+				client.Kill()
+				return false
+			}
+		}
+		return true
+	}
+
+	for {
+		select {
+		case frame := <-output:
+			if !write(frame) {
+				return
+			}
+
+		case <-client.Done():
+			if client.Killed() {
+				return
+			}
+			// The connection ended normally, send the replies still queued
+			for len(output) > 0 {
+				if !write(<-output) {
+					return
+				}
+			}
+			writer.Flush()
+			return
+		}
+	}
+}
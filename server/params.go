@@ -0,0 +1,360 @@
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/glob"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// maxDatabases bounds the databases parameter
+const maxDatabases = 1 << 16
+
+// param is a configuration parameter, named as in gedis.conf.
+// It is set from the configuration file, the command line and CONFIG SET, and read with CONFIG GET.
+type param struct {
+	name string
+
+	// immutable parameters are only set when the server is created, CONFIG SET refuses them
+	immutable bool
+
+	// get formats the value of the parameter, set parses and checks a new one
+	get func(c *Config) string
+	set func(c *Config, value string) error
+}
+
+// params lists the configuration parameters, named after their Redis counterpart when there is one
+var params = []param{
+	stringParam("address", true, func(c *Config) *string { return &c.Address }),
+	stringParam("requirepass", false, func(c *Config) *string { return &c.Password }),
+	stringParam("unixsocket", true, func(c *Config) *string { return &c.UnixSocket }),
+	{
+		name:      "unixsocketperm",
+		immutable: true,
+		get:       func(c *Config) string { return strconv.FormatUint(uint64(c.UnixSocketPerm), 8) },
+		set: func(c *Config, value string) error {
+			perm, err := strconv.ParseUint(value, 8, 32)
+			if err != nil || perm > uint64(os.ModePerm) {
+				return errors.New("argument must be octal permissions like 770")
+			}
+			c.UnixSocketPerm = os.FileMode(perm)
+			return nil
+		},
+	},
+	boolParam("disable-tcp", true, func(c *Config) *bool { return &c.DisableTCP }),
+	stringParam("tls-address", true, func(c *Config) *string { return &c.TLSAddress }),
+	stringParam("tls-cert-file", true, func(c *Config) *string { return &c.TLSCertFile }),
+	stringParam("tls-key-file", true, func(c *Config) *string { return &c.TLSKeyFile }),
+	stringParam("tls-ca-cert-file", true, func(c *Config) *string { return &c.TLSCACertFile }),
+	boolParam("tls-auth-clients", true, func(c *Config) *bool { return &c.TLSAuthClients }),
+	{
+		name: "notify-keyspace-events",
+		get:  func(c *Config) string { return c.NotifyKeyspaceEvents },
+		set: func(c *Config, value string) error {
+			events, err := RESP.NormalizeKeyspaceEvents(value)
+			if err != nil {
+				return err
+			}
+			c.NotifyKeyspaceEvents = events
+			return nil
+		},
+	},
+	stringParam("dbfilename", false, func(c *Config) *string { return &c.DBFilename }),
+	durationParam("shutdown-timeout", false, false, func(c *Config) *time.Duration { return &c.ShutdownTimeout }),
+	{
+		name:      "databases",
+		immutable: true,
+		get:       func(c *Config) string { return strconv.Itoa(c.Databases) },
+		set: func(c *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > maxDatabases {
+				return fmt.Errorf("argument must be between 1 and %d", maxDatabases)
+			}
+			c.Databases = n
+			return nil
+		},
+	},
+	{
+		name: "maxmemory",
+		get:  func(c *Config) string { return strconv.FormatInt(c.MaxMemory, 10) },
+		set: func(c *Config, value string) error {
+			bytes, err := parseMemory(value)
+			if err != nil {
+				return err
+			}
+			c.MaxMemory = bytes
+			return nil
+		},
+	},
+	{
+		name: "maxmemory-policy",
+		get:  func(c *Config) string { return string(c.MaxMemoryPolicy) },
+		set: func(c *Config, value string) error {
+			for _, policy := range storage.EvictionPolicies {
+				if strings.EqualFold(value, string(policy)) {
+					c.MaxMemoryPolicy = policy
+					return nil
+				}
+			}
+			names := make([]string, len(storage.EvictionPolicies))
+			for i, policy := range storage.EvictionPolicies {
+				names[i] = string(policy)
+			}
+			return fmt.Errorf("argument must be one of %s", strings.Join(names, ", "))
+		},
+	},
+	durationParam("timeout", false, true, func(c *Config) *time.Duration { return &c.Timeout }),
+	{
+		// A number of microseconds like in Redis, where 0 logs every command and a negative value none
+		name: "slowlog-log-slower-than",
+		get: func(c *Config) string {
+			if c.SlowlogLogSlowerThan < 0 {
+				return "-1"
+			}
+			return strconv.FormatInt(c.SlowlogLogSlowerThan.Microseconds(), 10)
+		},
+		set: func(c *Config, value string) error {
+			usec, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return errors.New("argument must be a number of microseconds")
+			}
+			switch {
+			case usec < 0:
+				c.SlowlogLogSlowerThan = -1
+			case usec == 0:
+				// 0 stands for the default in Config, every command takes at least a nanosecond
+				c.SlowlogLogSlowerThan = time.Nanosecond
+			default:
+				c.SlowlogLogSlowerThan = time.Duration(usec) * time.Microsecond
+			}
+			return nil
+		},
+	},
+	{
+		name: "slowlog-max-len",
+		get:  func(c *Config) string { return strconv.Itoa(c.SlowlogMaxLen) },
+		set: func(c *Config, value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return errors.New("argument must be a positive number of entries")
+			}
+			c.SlowlogMaxLen = n
+			return nil
+		},
+	},
+}
+
+// lookupParam returns the parameter with the given name, nil if there is none
+func lookupParam(name string) *param {
+	name = strings.ToLower(name)
+	for i := range params {
+		if params[i].name == name {
+			return &params[i]
+		}
+	}
+	return nil
+}
+
+// Set sets a parameter from its text value, as written in gedis.conf
+func (c *Config) Set(name, value string) error {
+	p := lookupParam(name)
+	if p == nil {
+		return fmt.Errorf("unknown parameter '%s'", name)
+	}
+	if err := p.set(c, value); err != nil {
+		return fmt.Errorf("invalid value for '%s': %w", p.name, err)
+	}
+	return nil
+}
+
+func stringParam(name string, immutable bool, field func(c *Config) *string) param {
+	return param{
+		name:      name,
+		immutable: immutable,
+		get:       func(c *Config) string { return *field(c) },
+		set: func(c *Config, value string) error {
+			*field(c) = value
+			return nil
+		},
+	}
+}
+
+// boolParam accepts yes and no, as well as the values of Go boolean flags like true and false
+func boolParam(name string, immutable bool, field func(c *Config) *bool) param {
+	return param{
+		name:      name,
+		immutable: immutable,
+		get: func(c *Config) string {
+			if *field(c) {
+				return "yes"
+			}
+			return "no"
+		},
+		set: func(c *Config, value string) error {
+			switch strings.ToLower(value) {
+			case "yes":
+				*field(c) = true
+			case "no":
+				*field(c) = false
+			default:
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return errors.New("argument must be 'yes' or 'no'")
+				}
+				*field(c) = b
+			}
+			return nil
+		},
+	}
+}
+
+// durationParam accepts a number of seconds or a Go duration like 1m30s,
+// and formats whole seconds as a number like Redis does
+func durationParam(name string, immutable, allowZero bool, field func(c *Config) *time.Duration) param {
+	return param{
+		name:      name,
+		immutable: immutable,
+		get: func(c *Config) string {
+			d := *field(c)
+			if d%time.Second == 0 {
+				return strconv.FormatInt(int64(d/time.Second), 10)
+			}
+			return d.String()
+		},
+		set: func(c *Config, value string) error {
+			invalid := errors.New("argument must be a positive number of seconds or a duration like 1m30s")
+			var d time.Duration
+			if seconds, err := strconv.ParseInt(value, 10, 32); err == nil {
+				d = time.Duration(seconds) * time.Second
+			} else if d, err = time.ParseDuration(value); err != nil {
+				return invalid
+			}
+			if d < 0 || (d == 0 && !allowZero) {
+				return invalid
+			}
+			*field(c) = d
+			return nil
+		},
+	}
+}
+
+// memoryUnits are the suffixes of memory values, from the longest
+var memoryUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"kb", 1 << 10}, {"mb", 1 << 20}, {"gb", 1 << 30},
+	{"k", 1000}, {"m", 1000 * 1000}, {"g", 1000 * 1000 * 1000},
+	{"b", 1},
+}
+
+// parseMemory parses a number of bytes with an optional unit like 100mb, units are case insensitive
+func parseMemory(value string) (int64, error) {
+	number, multiplier := strings.ToLower(value), int64(1)
+	for _, unit := range memoryUnits {
+		if strings.HasSuffix(number, unit.suffix) {
+			number, multiplier = strings.TrimSuffix(number, unit.suffix), unit.multiplier
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 || n > (1<<63-1)/multiplier {
+		return 0, errors.New("argument must be a memory value like 100mb")
+	}
+	return n * multiplier, nil
+}
+
+// Settings returns the parameters in effect: the configuration the server was created with,
+// where the fields left to 0 hold their default, and the changes made with CONFIG SET
+func (s *Server) Settings() Config {
+	s.settingsMu.RLock()
+	defer s.settingsMu.RUnlock()
+	return s.settings
+}
+
+// ConfigGet returns the parameters matching any of the glob-style patterns, with their values
+func (s *Server) ConfigGet(patterns ...string) map[string]string {
+	settings := s.Settings()
+	values := make(map[string]string)
+	for _, p := range params {
+		for _, pattern := range patterns {
+			if glob.Match(pattern, p.name) {
+				values[p.name] = p.get(&settings)
+				break
+			}
+		}
+	}
+	return values
+}
+
+// ConfigSet changes parameters at runtime. The immutable ones are refused,
+// and when any value is invalid none of the parameters is changed.
+func (s *Server) ConfigSet(values map[string]string) error {
+	s.settingsMu.Lock()
+	defer s.settingsMu.Unlock()
+
+	// The parameters are checked in a stable order, so the same one is reported on error
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	settings := s.settings
+	for _, name := range names {
+		if p := lookupParam(name); p != nil && p.immutable {
+			return fmt.Errorf("can't set immutable config '%s'", p.name)
+		}
+		if err := settings.Set(name, values[name]); err != nil {
+			return err
+		}
+	}
+	if err := validateConfig(&settings); err != nil {
+		return err
+	}
+
+	previous := s.settings
+	if err := s.applySettings(&previous, settings); err != nil {
+		return err
+	}
+	s.settings = settings
+	return nil
+}
+
+// ConfigRewrite saves the parameters in effect to the configuration file the server was created from
+func (s *Server) ConfigRewrite() error {
+	if s.config.ConfigFile == "" {
+		return errors.New("The server is running without a config file")
+	}
+	if err := rewriteConfigFile(s.config.ConfigFile, s.Settings()); err != nil {
+		return fmt.Errorf("Rewriting config file: %w", err)
+	}
+	return nil
+}
+
+// applySettings puts the parameters handled by the handler and the databases into effect,
+// those that changed since previous, or all of them when previous is nil
+func (s *Server) applySettings(previous *Config, settings Config) error {
+	if previous == nil || settings.Password != previous.Password {
+		s.handler.SetPassword(settings.Password)
+	}
+	if err := s.handler.SetKeyspaceEvents(settings.NotifyKeyspaceEvents); err != nil {
+		return err
+	}
+	s.handler.SetSlowlog(settings.SlowlogLogSlowerThan, settings.SlowlogMaxLen)
+	if err := s.dbs.SetMaxMemory(settings.MaxMemory, settings.MaxMemoryPolicy); err != nil {
+		return err
+	}
+
+	// A lower limit evicts keys right away
+	s.dbs.FreeMemory()
+	return nil
+}
@@ -2,28 +2,71 @@ package redis
 
 import (
 	"errors"
+	"fmt"
+	"net"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/GedisCaching/Gedis/RESP"
 	"github.com/GedisCaching/Gedis/storage"
 )
 
 // Server represents Redis-like server
 type Server struct {
-	// Data stores
-	db *storage.Database
+	// Data stores, the numbered databases clients switch between with SELECT
+	dbs *storage.Databases
 
-	// Configuration
+	// Configuration the server was created with, it identifies the server in the manager
 	config *Config
 
+	// settings holds the parameters in effect, which CONFIG SET changes, see Settings
+	settingsMu sync.RWMutex
+	settings   Config
+
 	// Last access time for LRU
 	lastAccessed time.Time
+
+	// Executes the commands received from network clients
+	handler *RESP.Handler
+
+	// Certificates of the TLS listener, nil when TLS is disabled
+	tls *tlsCredentials
+
+	// Listeners accepting connections, closed by Close
+	listenersMu sync.Mutex
+	listeners   map[net.Listener]struct{}
+	closed      bool
+
+	// Connections being served, Close waits for them to end
+	connsMu sync.Mutex
+	conns   map[net.Conn]struct{}
+	connsWG sync.WaitGroup
+
+	// The shutdown runs once, shutdownDone is closed when it completed
+	shutdownOnce sync.Once
+	shutdownDone chan struct{}
+	shutdownErr  error
 }
 
-// GetDB returns the database instance
+// GetDB returns database 0, the database clients start on
 func (s *Server) GetDB() *storage.Database {
-	return s.db
+	return s.dbs.DB(0)
+}
+
+// GetDatabases returns the numbered databases of the server
+func (s *Server) GetDatabases() *storage.Databases {
+	return s.dbs
+}
+
+// GetHandler returns the handler executing the commands of network clients
+func (s *Server) GetHandler() *RESP.Handler {
+	return s.handler
+}
+
+// GetConfig returns a copy of the configuration the server was created with
+func (s *Server) GetConfig() Config {
+	return *s.config
 }
 
 // UpdateAccessTime updates the lastAccessed time of this server
@@ -126,7 +169,25 @@ func validateConfig(config *Config) error {
 		}
 	}
 
-	return nil
+	if err := config.validateListeners(); err != nil {
+		return err
+	}
+	if config.ShutdownTimeout < 0 {
+		return errors.New("shutdown timeout cannot be negative")
+	}
+	if config.Timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	if config.Databases < 0 || config.Databases > maxDatabases {
+		return fmt.Errorf("the number of databases must be between 1 and %d", maxDatabases)
+	}
+	if config.MaxMemory < 0 {
+		return errors.New("memory limit cannot be negative")
+	}
+	if config.SlowlogMaxLen < 0 {
+		return errors.New("slow log length cannot be negative")
+	}
+	return config.validateTLS()
 }
 
 // GetOrCreateServer returns an existing server for the given config or creates a new one
@@ -146,19 +207,46 @@ func (sm *ServerManager) GetOrCreateServer(config Config) (*Server, error) {
 		return server, nil
 	}
 
-	// Before creating a new server, check if we need to evict
-	if len(sm.servers) >= sm.capacity && sm.capacity > 0 {
-		sm.evictLRU()
-	}
-
 	// Create a copy of the config to store in the server
 	configCopy := config
 
-	// Create new server with the config
+	// Load the certificates and settings first so an invalid one doesn't evict another server
+	var credentials *tlsCredentials
+	if config.TLSEnabled() {
+		var err error
+		if credentials, err = newTLSCredentials(&configCopy); err != nil {
+			return nil, err
+		}
+	}
+
+	settings := config.withDefaults()
+	dbs := storage.NewDatabases(settings.Databases)
+	handler := RESP.NewHandlerWithDatabases(dbs)
 	server := &Server{
-		db:           storage.NewDatabase(),
+		dbs:          dbs,
 		config:       &configCopy,
+		settings:     settings,
 		lastAccessed: time.Now(),
+		handler:      handler,
+		tls:          credentials,
+		listeners:    make(map[net.Listener]struct{}),
+		conns:        make(map[net.Conn]struct{}),
+		shutdownDone: make(chan struct{}),
+	}
+	if err := server.applySettings(nil, server.settings); err != nil {
+		return nil, err
+	}
+	if config.DBFilename != "" {
+		if err := loadDatabase(dbs, config.DBFilename); err != nil {
+			return nil, err
+		}
+	}
+	handler.SetShutdownFunc(server.shutdownCommand)
+	handler.SetConfiguration(server)
+
+	// Before creating a new server, check if we need to evict
+	if len(sm.servers) >= sm.capacity && sm.capacity > 0 {
+		sm.evictLRU()
 	}
 
 	// Store in map
@@ -0,0 +1,207 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// Close shuts the server down gracefully: it stops accepting connections and reading commands,
+// lets the commands being executed finish, sends their replies and closes every connection.
+// The connections still open when ctx is done are closed at once and ctx.Err() is returned.
+// The database is then saved when persistence is configured.
+// Calling Close again waits for the first call to complete and returns its result.
+func (s *Server) Close(ctx context.Context) error {
+	return s.shutdown(ctx, s.Settings().DBFilename != "")
+}
+
+// shutdown runs the shutdown once, saving the database after the connections are closed when save is set
+func (s *Server) shutdown(ctx context.Context, save bool) error {
+	s.shutdownOnce.Do(func() {
+		defer close(s.shutdownDone)
+
+		err := s.closeListeners()
+		if drainErr := s.drainConns(ctx); err == nil {
+			err = drainErr
+		}
+		if save {
+			if saveErr := s.Save(); err == nil {
+				err = saveErr
+			}
+		}
+		s.shutdownErr = err
+	})
+
+	<-s.shutdownDone
+	return s.shutdownErr
+}
+
+// Done is closed once the server shut down, after Close or the SHUTDOWN command
+func (s *Server) Done() <-chan struct{} {
+	return s.shutdownDone
+}
+
+// shutdownCommand runs the SHUTDOWN command: the server is closed like Close does, in the background
+// since the client that sent SHUTDOWN is itself being served, and the database is saved once every
+// connection is closed, so no acknowledged write is lost. Unless the shutdown is forced, the database
+// is saved beforehand as well: the shutdown is aborted if that fails, while nothing is stopped yet.
+func (s *Server) shutdownCommand(opts RESP.ShutdownOptions) error {
+	settings := s.Settings()
+	save := opts.Save || (settings.DBFilename != "" && !opts.NoSave)
+	if save && !opts.Force {
+		if err := s.Save(); err != nil {
+			fmt.Printf("Error saving the database on SHUTDOWN: %v\n", err)
+			return err
+		}
+	}
+
+	timeout := settings.ShutdownTimeout
+	if opts.Now {
+		timeout = 0
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		if err := s.shutdown(ctx, save); err != nil {
+			fmt.Printf("Error on SHUTDOWN: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+// Save writes every database to DBFilename. The file is replaced atomically,
+// so a crash while saving leaves the previous snapshot intact.
+func (s *Server) Save() error {
+	path := s.Settings().DBFilename
+	if path == "" {
+		return errors.New("persistence is not configured")
+	}
+	return writeFileAtomic(path, s.dbs.Save)
+}
+
+// writeFileAtomic replaces the file at path with what write produces,
+// through a temporary file renamed once it is complete and synced
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// loadDatabase reads the databases from the file at path, a missing file leaves them empty
+func loadDatabase(dbs *storage.Databases, path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := dbs.Load(f); err != nil {
+		return fmt.Errorf("loading %s: %w", path, err)
+	}
+	return nil
+}
+
+// closeListeners stops accepting connections on every listener
+func (s *Server) closeListeners() error {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+
+	s.closed = true
+	var err error
+	for l := range s.listeners {
+		if closeErr := l.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		delete(s.listeners, l)
+	}
+	return err
+}
+
+// drainConns waits for the connections to finish their commands and close,
+// and closes those still open when ctx is done
+func (s *Server) drainConns(ctx context.Context) error {
+	// Clients blocked on lists reply as if their timeout expired
+	s.handler.Close()
+
+	// Interrupt the reads waiting for a command, the command being executed completes
+	s.connsMu.Lock()
+	for conn := range s.conns {
+		conn.SetReadDeadline(time.Now())
+	}
+	s.connsMu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.connsWG.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+	}
+
+	// The deadline passed, drop what is left
+	for _, client := range s.handler.Clients() {
+		client.Kill()
+	}
+	s.connsMu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.connsMu.Unlock()
+	<-drained
+	return ctx.Err()
+}
+
+// trackConn registers a connection so Close can wait for it, it reports false once the server is closed
+func (s *Server) trackConn(conn net.Conn) bool {
+	// The listeners lock orders trackConn against closeListeners:
+	// a connection is either tracked before the server closes, or refused
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	if s.closed {
+		return false
+	}
+
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	s.conns[conn] = struct{}{}
+	s.connsWG.Add(1)
+	return true
+}
+
+// untrackConn forgets a connection that was closed
+func (s *Server) untrackConn(conn net.Conn) {
+	s.connsMu.Lock()
+	defer s.connsMu.Unlock()
+	delete(s.conns, conn)
+	s.connsWG.Done()
+}
@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+)
+
+// tlsCredentials holds the certificates used by the TLS listener.
+// They are read from disk when the server is created and again on every reload,
+// new connections use the latest ones without restarting the server.
+type tlsCredentials struct {
+	config *Config
+
+	mu          sync.RWMutex
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+}
+
+// newTLSCredentials loads the certificates named in config
+func newTLSCredentials(config *Config) (*tlsCredentials, error) {
+	credentials := &tlsCredentials{config: config}
+	if err := credentials.reload(); err != nil {
+		return nil, err
+	}
+	return credentials, nil
+}
+
+// reload reads the certificates from disk again.
+// The previous certificates stay in use if any file is invalid.
+func (t *tlsCredentials) reload() error {
+	certificate, err := tls.LoadX509KeyPair(t.config.TLSCertFile, t.config.TLSKeyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+	if t.config.TLSCACertFile != "" {
+		pem, err := os.ReadFile(t.config.TLSCACertFile)
+		if err != nil {
+			return fmt.Errorf("loading TLS CA certificate: %w", err)
+		}
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return errors.New("loading TLS CA certificate: no certificate found in " + t.config.TLSCACertFile)
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.certificate = &certificate
+	t.clientCAs = clientCAs
+	return nil
+}
+
+// tlsConfig returns the configuration of the TLS listener,
+// which picks up the current certificates on every handshake
+func (t *tlsCredentials) tlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			t.mu.RLock()
+			defer t.mu.RUnlock()
+
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*t.certificate},
+				ClientCAs:    t.clientCAs,
+			}
+			switch {
+			case t.config.TLSAuthClients:
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			case t.clientCAs != nil:
+				config.ClientAuth = tls.VerifyClientCertIfGiven
+			}
+			return config, nil
+		},
+	}
+}
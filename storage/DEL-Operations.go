@@ -1,14 +1,45 @@
 package storage
 
-// Delete removes a key
+// Delete removes a key, whatever type of value it holds
 func (db *Database) Delete(key string) bool {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
+	if _, exists := db.setStorage[key]; exists {
+		delete(db.setStorage, key)
+		db.account(key)
+		db.modified(key, ClassGeneric, "del")
+		return true
+	}
+
 	if _, exists := db.data[key]; exists {
 		delete(db.data, key)
 		delete(db.expires, key)
+		db.account(key)
+		db.modified(key, ClassGeneric, "del")
 		return true
 	}
 	return false
 }
+
+// deleteIfEmpty deletes key once the list or hash it holds has no element left, as Redis does.
+// It must be called with the write lock held.
+func (db *Database) deleteIfEmpty(key string) {
+	switch value := db.data[key].(type) {
+	case []interface{}:
+		if len(value) > 0 {
+			return
+		}
+	case map[string]interface{}:
+		if len(value) > 0 {
+			return
+		}
+	default:
+		return
+	}
+
+	delete(db.data, key)
+	delete(db.expires, key)
+	db.account(key)
+	db.modified(key, ClassGeneric, "del")
+}
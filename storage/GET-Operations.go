@@ -1,9 +1,13 @@
 package storage
 
 import (
+	"errors"
 	"time"
 )
 
+// ErrWrongType is returned when an operation meets a key holding another type of value
+var ErrWrongType = errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")
+
 // Get retrieves a value by key
 func (db *Database) Get(key string) (interface{}, bool) {
 	db.mu.Lock()
@@ -12,30 +16,29 @@ func (db *Database) Get(key string) (interface{}, bool) {
 	// Check if key exists
 	value, exists := db.data[key]
 	if !exists {
+		db.stats.misses.Add(1)
 		return nil, false
 	}
 
-	// Check if key has expired
-	if expiry, hasExpiry := db.expires[key]; hasExpiry && time.Now().After(expiry) {
-		// Key has expired, remove it
-		delete(db.data, key)
-		delete(db.expires, key)
+	// Check if key has expired, and remove it if so
+	if db.expireIfNeeded(key) {
+		db.stats.misses.Add(1)
 		return nil, false
 	}
 
+	db.stats.hits.Add(1)
 	return value, true
 }
 
 // Keys returns all keys in the database
 func (db *Database) Keys() []string {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
+	db.mu.Lock()
+	defer db.mu.Unlock()
 
 	keys := make([]string, 0, len(db.data))
 	for k := range db.data {
-		// Check if expired
-		if expiry, hasExpiry := db.expires[k]; hasExpiry && time.Now().After(expiry) {
-			db.Delete(k) // Remove expired key
+		// Remove expired keys
+		if db.expireIfNeeded(k) {
 			continue
 		}
 		keys = append(keys, k)
@@ -45,10 +48,66 @@ func (db *Database) Keys() []string {
 
 // GETDEL Get a value and delete it in a single operation
 func (db *Database) GETDEL(key string) (interface{}, bool) {
-	value, exists := db.Get(key)
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	value, exists := db.data[key]
 	if !exists {
 		return nil, false
 	}
-	db.Delete(key)
-	return value, exists
+
+	// An expired key is deleted without being returned, a key of another type is left alone
+	if db.expireIfNeeded(key) || db.typeOf(key) != "string" {
+		return nil, false
+	}
+	delete(db.data, key)
+	delete(db.expires, key)
+	db.account(key)
+	db.modified(key, ClassGeneric, "del")
+
+	return value, true
+}
+
+// Type returns the name of the data type stored at key:
+// "string", "list", "hash", "zset", or "none" if the key doesn't exist
+func (db *Database) Type(key string) string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if expiry, hasExpiry := db.expires[key]; hasExpiry && time.Now().After(expiry) {
+		return "none"
+	}
+	return db.typeOf(key)
+}
+
+// typeOf returns the name of the data type stored at key like Type, whether or not it expired.
+// It must be called with the lock held.
+func (db *Database) typeOf(key string) string {
+	if _, exists := db.setStorage[key]; exists {
+		return "zset"
+	}
+
+	value, exists := db.data[key]
+	if !exists {
+		return "none"
+	}
+
+	switch value.(type) {
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "hash"
+	default:
+		return "string"
+	}
+}
+
+// checkType returns ErrWrongType when key holds another type of value than want.
+// It must be called with the write lock held, after expiring the key if needed,
+// so the type cannot change before the operation using it completes.
+func (db *Database) checkType(key, want string) error {
+	if kind := db.typeOf(key); kind != want && kind != "none" {
+		return ErrWrongType
+	}
+	return nil
 }
@@ -10,25 +10,32 @@ import (
 func (db *Database) HSET(key string, field string, value interface{}) (bool, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
+	if err := db.checkType(key, "hash"); err != nil {
+		return false, err
+	}
 
 	// Initialize the hash if it doesn't exist
 	if _, exists := db.data[key]; !exists {
 		db.data[key] = make(map[string]interface{})
 	}
 
-	hash, ok := db.data[key].(map[string]interface{})
-	if !ok {
-		return false, fmt.Errorf("key %s is not a hash", key)
+	hash := db.data[key].(map[string]interface{})
+	delta := fieldSize(field, value)
+	if old, exists := hash[field]; exists {
+		delta -= fieldSize(field, old)
 	}
-
 	hash[field] = value
+	db.resize(key, delta)
+	db.modified(key, ClassHash, "hset")
 	return true, nil
 }
 
 // HGET retrieves the value of a field in a hash
 func (db *Database) HGET(key string, field string) (interface{}, bool) {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
 
 	hash, exists := db.data[key].(map[string]interface{})
 	if !exists {
@@ -43,24 +50,33 @@ func (db *Database) HGET(key string, field string) (interface{}, bool) {
 func (db *Database) HDEL(key string, field string) (bool, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
+	if err := db.checkType(key, "hash"); err != nil {
+		return false, err
+	}
 
 	hash, exists := db.data[key].(map[string]interface{})
 	if !exists {
-		return false, fmt.Errorf("key %s is not a hash", key)
+		return false, fmt.Errorf("key %s does not exist", key)
 	}
 
-	if _, exists := hash[field]; !exists {
+	old, exists := hash[field]
+	if !exists {
 		return false, nil
 	}
 
 	delete(hash, field)
+	db.resize(key, -fieldSize(field, old))
+	db.modified(key, ClassHash, "hdel")
+	db.deleteIfEmpty(key)
 	return true, nil
 }
 
 // HGETALL retrieves all fields and values in a hash
 func (db *Database) HGETALL(key string) (map[string]interface{}, bool) {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
 
 	hash, exists := db.data[key].(map[string]interface{})
 	if !exists {
@@ -77,8 +93,9 @@ func (db *Database) HGETALL(key string) (map[string]interface{}, bool) {
 
 // HKEYS retrieves all field names in a hash
 func (db *Database) HKEYS(key string) ([]string, bool) {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
 
 	hash, exists := db.data[key].(map[string]interface{})
 	if !exists {
@@ -94,8 +111,9 @@ func (db *Database) HKEYS(key string) ([]string, bool) {
 
 // HVALS retrieves all values in a hash
 func (db *Database) HVALS(key string) ([]interface{}, bool) {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
 
 	hash, exists := db.data[key].(map[string]interface{})
 	if !exists {
@@ -111,8 +129,9 @@ func (db *Database) HVALS(key string) ([]interface{}, bool) {
 
 // HLEN retrieves the number of fields in a hash
 func (db *Database) HLEN(key string) (int, bool) {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
 
 	hash, exists := db.data[key].(map[string]interface{})
 	if !exists {
@@ -121,3 +140,67 @@ func (db *Database) HLEN(key string) (int, bool) {
 
 	return len(hash), true
 }
+
+// HMSET sets several fields of a hash at once
+// Returns the number of fields that were newly created
+func (db *Database) HMSET(key string, fields map[string]interface{}) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
+	if err := db.checkType(key, "hash"); err != nil {
+		return 0, err
+	}
+
+	// Initialize the hash if it doesn't exist
+	if _, exists := db.data[key]; !exists {
+		db.data[key] = make(map[string]interface{})
+	}
+
+	hash := db.data[key].(map[string]interface{})
+	added := 0
+	var delta int64
+	for field, value := range fields {
+		if old, exists := hash[field]; exists {
+			delta -= fieldSize(field, old)
+		} else {
+			added++
+		}
+		hash[field] = value
+		delta += fieldSize(field, value)
+	}
+	db.resize(key, delta)
+	db.modified(key, ClassHash, "hset")
+	return added, nil
+}
+
+// HDELFields deletes several fields from a hash at once
+// Returns the number of fields that were removed
+func (db *Database) HDELFields(key string, fields ...string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
+	if err := db.checkType(key, "hash"); err != nil {
+		return 0, err
+	}
+
+	hash, exists := db.data[key].(map[string]interface{})
+	if !exists {
+		return 0, nil
+	}
+
+	removed := 0
+	var delta int64
+	for _, field := range fields {
+		if old, exists := hash[field]; exists {
+			delete(hash, field)
+			delta -= fieldSize(field, old)
+			removed++
+		}
+	}
+	if removed > 0 {
+		db.resize(key, delta)
+		db.modified(key, ClassHash, "hdel")
+		db.deleteIfEmpty(key)
+	}
+	return removed, nil
+}
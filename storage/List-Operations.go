@@ -8,23 +8,14 @@ import (
 func (db *Database) LPush(key string, values ...interface{}) (int, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
-
-	// Check if key exists
-	var list []interface{}
-	existingVal, exists := db.data[key]
-
-	if exists {
-		// If key exists, check that it's a list
-		var ok bool
-		list, ok = existingVal.([]interface{})
-		if !ok {
-			return 0, errors.New("value at key is not a list")
-		}
-	} else {
-		// If key doesn't exist, create a new list
-		list = []interface{}{}
+	db.expireIfNeeded(key)
+	if err := db.checkType(key, "list"); err != nil {
+		return 0, err
 	}
 
+	// If key doesn't exist, start from an empty list
+	list, _ := db.data[key].([]interface{})
+
 	// Prepend values to the list
 	newList := make([]interface{}, len(values)+len(list))
 	copy(newList[len(values):], list)
@@ -32,6 +23,8 @@ func (db *Database) LPush(key string, values ...interface{}) (int, error) {
 
 	// Store updated list
 	db.data[key] = newList
+	db.resize(key, elementsSize(values))
+	db.modified(key, ClassList, "lpush")
 
 	return len(newList), nil
 }
@@ -40,48 +33,40 @@ func (db *Database) LPush(key string, values ...interface{}) (int, error) {
 func (db *Database) RPush(key string, values ...interface{}) (int, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
-
-	// Check if key exists
-	var list []interface{}
-	existingVal, exists := db.data[key]
-
-	if exists {
-		// If key exists, check that it's a list
-		var ok bool
-		list, ok = existingVal.([]interface{})
-		if !ok {
-			return 0, errors.New("value at key is not a list")
-		}
-	} else {
-		// If key doesn't exist, create a new list
-		list = []interface{}{}
+	db.expireIfNeeded(key)
+	if err := db.checkType(key, "list"); err != nil {
+		return 0, err
 	}
 
+	// If key doesn't exist, start from an empty list
+	list, _ := db.data[key].([]interface{})
+
 	// Append values to the list
 	list = append(list, values...)
 
 	// Store updated list
 	db.data[key] = list
+	db.resize(key, elementsSize(values))
+	db.modified(key, ClassList, "rpush")
 
 	return len(list), nil
 }
 
 // LRange returns a range of elements from a list
 func (db *Database) LRange(key string, start, stop int) ([]interface{}, error) {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
+	if err := db.checkType(key, "list"); err != nil {
+		return nil, err
+	}
 
 	// Check if key exists
 	existingVal, exists := db.data[key]
 	if !exists {
 		return []interface{}{}, nil // Redis returns empty list for non-existent keys
 	}
-
-	// Check if value is a list
-	list, ok := existingVal.([]interface{})
-	if !ok {
-		return nil, errors.New("value at key is not a list")
-	}
+	list := existingVal.([]interface{})
 
 	length := len(list)
 
@@ -106,26 +91,26 @@ func (db *Database) LRange(key string, start, stop int) ([]interface{}, error) {
 		stop = length - 1
 	}
 
-	// Return the range (inclusive of stop in Redis)
-	return list[start : stop+1], nil
+	// Return a copy of the range (inclusive of stop in Redis),
+	// the list may be written in place once the lock is released
+	return append([]interface{}(nil), list[start:stop+1]...), nil
 }
 
 // Remove and return the first element of a list
 func (db *Database) LPop(key string) (interface{}, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
+	if err := db.checkType(key, "list"); err != nil {
+		return nil, err
+	}
 
 	// Check if key exists
 	existingVal, exists := db.data[key]
 	if !exists {
 		return nil, nil // Redis returns nil for non-existent keys
 	}
-
-	// Check if value is a list
-	list, ok := existingVal.([]interface{})
-	if !ok {
-		return nil, errors.New("value at key is not a list")
-	}
+	list := existingVal.([]interface{})
 
 	if len(list) == 0 {
 		return nil, nil // Return nil if the list is empty
@@ -137,6 +122,9 @@ func (db *Database) LPop(key string) (interface{}, error) {
 
 	// Store updated list
 	db.data[key] = list
+	db.resize(key, -sizeOf(firstElement))
+	db.modified(key, ClassList, "lpop")
+	db.deleteIfEmpty(key)
 
 	return firstElement, nil
 }
@@ -145,18 +133,17 @@ func (db *Database) LPop(key string) (interface{}, error) {
 func (db *Database) RPop(key string) (interface{}, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
+	if err := db.checkType(key, "list"); err != nil {
+		return nil, err
+	}
 
 	// Check if key exists
 	existingVal, exists := db.data[key]
 	if !exists {
 		return nil, nil // Redis returns nil for non-existent keys
 	}
-
-	// Check if value is a list
-	list, ok := existingVal.([]interface{})
-	if !ok {
-		return nil, errors.New("value at key is not a list")
-	}
+	list := existingVal.([]interface{})
 
 	if len(list) == 0 {
 		return nil, nil // Return nil if the list is empty
@@ -168,57 +155,64 @@ func (db *Database) RPop(key string) (interface{}, error) {
 
 	// Store updated list
 	db.data[key] = list
+	db.resize(key, -sizeOf(lastElement))
+	db.modified(key, ClassList, "rpop")
+	db.deleteIfEmpty(key)
 
 	return lastElement, nil
 }
 
 // Get the length of a list
 func (db *Database) LLen(key string) (int, error) {
-	db.mu.RLock()
-	defer db.mu.RUnlock()
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
+	if err := db.checkType(key, "list"); err != nil {
+		return 0, err
+	}
 
 	// Check if key exists
 	existingVal, exists := db.data[key]
 	if !exists {
 		return 0, errors.New("key does not exist")
 	}
-
-	// Check if value is a list
-	list, ok := existingVal.([]interface{})
-	if !ok {
-		return 0, errors.New("value at key is not a list")
-	}
+	list := existingVal.([]interface{})
 
 	return len(list), nil
 }
 
-// Set the value of an element in a list by its index
+// Set the value of an element in a list by its index, negative indexes count from the end of the list
 func (db *Database) LSet(key string, index int, value interface{}) error {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
+	if err := db.checkType(key, "list"); err != nil {
+		return err
+	}
 
 	// Check if key exists
 	existingVal, exists := db.data[key]
 	if !exists {
 		return errors.New("key does not exist")
 	}
-
-	// Check if value is a list
-	list, ok := existingVal.([]interface{})
-	if !ok {
-		return errors.New("value at key is not a list")
-	}
+	list := existingVal.([]interface{})
 
 	// Check index bounds
+	if index < 0 {
+		index += len(list)
+	}
 	if index < 0 || index >= len(list) {
 		return errors.New("index out of range")
 	}
 
 	// Set the value at the specified index
+	delta := sizeOf(value) - sizeOf(list[index])
 	list[index] = value
 
 	// Store updated list
 	db.data[key] = list
+	db.resize(key, delta)
+	db.modified(key, ClassList, "lset")
 
 	return nil
 }
@@ -1,74 +1,70 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
 )
 
+// ErrOverflow is returned when incrementing or decrementing a value would leave the range of int
+var ErrOverflow = errors.New("increment or decrement would overflow")
+
 // Incr increments the value of a key by one.
 // If the key has expired, it is deleted from the database
 // and a new key is created with the incremented value and no expiration time.
 func (db *Database) Incr(key string) (int, error) {
-	value, exists := db.Get(key)
-
-	// Acquire the lock after Get because the Get operation already applies its own lock,
-	// and we cannot acquire a lock that is already held.
-	db.mu.Lock()
-	defer db.mu.Unlock()
-
-	var intValue int
-	if !exists {
-		// In Redis, INCR creates the key with value 0 if it doesn't exist, then increments it
-		intValue = 1
-	} else {
-		switch v := value.(type) {
-		case int:
-			intValue = v + 1
-		case string:
-			if parsedInt, err := strconv.Atoi(v); err == nil {
-				intValue = parsedInt + 1
-			} else {
-				return 0, fmt.Errorf("invalid value for key %s: %s", key, v)
-			}
-		default:
-			return 0, fmt.Errorf("invalid value type for key %s: %T", key, value)
-		}
-	}
-
-	db.data[key] = intValue
-	return intValue, nil
+	return db.incrBy(key, 1)
 }
 
 // Decr decrements the value of a key by one.
 // If the key has expired, it is deleted from the database
 // and a new key is created with the decremented value and no expiration time.
 func (db *Database) Decr(key string) (int, error) {
-	value, exists := db.Get(key)
+	return db.incrBy(key, -1)
+}
 
-	// Acquire the lock after Get because the Get operation already applies its own lock,
-	// and we cannot acquire a lock that is already held.
+// incrBy adds delta to the integer stored at key.
+// The read and the write happen under the same lock so concurrent
+// increments are never lost.
+func (db *Database) incrBy(key string, delta int) (int, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
 
-	var intValue int
-	if !exists {
-		// In Redis, DECR creates the key with value 0 if it doesn't exist, then decrements it
-		intValue = -1
-	} else {
+	value, exists := db.data[key]
+
+	// An expired key behaves as if it didn't exist
+	if exists && db.expireIfNeeded(key) {
+		exists = false
+	}
+	if err := db.checkType(key, "string"); err != nil {
+		return 0, err
+	}
+
+	// In Redis, INCR/DECR create the key with value 0 if it doesn't exist, then apply the delta
+	var current int
+	if exists {
 		switch v := value.(type) {
 		case int:
-			intValue = v - 1
+			current = v
 		case string:
-			if parsedInt, err := strconv.Atoi(v); err == nil {
-				intValue = parsedInt - 1
-			} else {
+			parsedInt, err := strconv.Atoi(v)
+			if err != nil {
 				return 0, fmt.Errorf("invalid value for key %s: %s", key, v)
 			}
+			current = parsedInt
 		default:
 			return 0, fmt.Errorf("invalid value type for key %s: %T", key, value)
 		}
 	}
 
+	if (delta > 0 && current > math.MaxInt-delta) || (delta < 0 && current < math.MinInt-delta) {
+		return 0, ErrOverflow
+	}
+	intValue := current + delta
+
 	db.data[key] = intValue
+	db.account(key)
+	db.modified(key, ClassString, "incrby")
 	return intValue, nil
 }
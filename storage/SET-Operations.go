@@ -5,56 +5,92 @@ import (
 	"time"
 )
 
-// Set stores a key-value pair
+// Set stores a key-value pair, discarding any previous value and expiry on the key
 func (db *Database) Set(key string, value interface{}) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	delete(db.setStorage, key)
 	db.data[key] = value
+	delete(db.expires, key)
+	db.account(key)
+	db.modified(key, ClassString, "set")
 }
 
-// SetWithExpiry sets a key with an expiration time
+// SetWithExpiry sets a key with an expiration time, discarding any previous value
 func (db *Database) SetWithExpiry(key string, value interface{}, expiry time.Duration) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	delete(db.setStorage, key)
 	db.data[key] = value
 	db.expires[key] = time.Now().Add(expiry)
+	db.account(key)
+	db.modified(key, ClassString, "set")
+	db.modified(key, ClassGeneric, "expire")
 }
 
-// DEXPIRE set expiration on existing key
+// DEXPIRE set expiration on existing key, a zero or negative expiry deletes the key right away
 func (db *Database) DEXPIRE(key string, expiry time.Duration) error {
-	_, exists := db.Get(key)
-	if !exists {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.expireIfNeeded(key)
+	if _, exists := db.data[key]; !exists {
 		return errors.New("key does not exist")
 	}
 
-	db.mu.Lock()
-	defer db.mu.Unlock()
+	if expiry <= 0 {
+		delete(db.data, key)
+		delete(db.expires, key)
+		db.account(key)
+		db.modified(key, ClassGeneric, "del")
+		return nil
+	}
+
+	if _, hasExpiry := db.expires[key]; !hasExpiry {
+		db.resize(key, expiryOverhead)
+	}
 	db.expires[key] = time.Now().Add(expiry)
+	db.modified(key, ClassGeneric, "expire")
 	return nil
 }
 
-// RENAME renames the existing key to a new key
+// RENAME renames the existing key to a new key, keeping its value and TTL
 func (db *Database) RENAME(KeyOld, KeyNew string) error {
-	// Get the value of the old key
-	value, exists := db.Get(KeyOld)
-	if !exists {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.expireIfNeeded(KeyOld)
+	db.expireIfNeeded(KeyNew)
+
+	value, inData := db.data[KeyOld]
+	set, inSets := db.setStorage[KeyOld]
+	if !inData && !inSets {
 		return errors.New("key does not exist")
 	}
 
-	if _, newKeyExists := db.Get(KeyNew); newKeyExists {
+	_, newInData := db.data[KeyNew]
+	_, newInSets := db.setStorage[KeyNew]
+	if newInData || newInSets {
 		return errors.New("new key already exists")
 	}
 
-	// Get the TTL of the old key
-	ttl, exists := db.TTL(KeyOld)
-
-	if ttl == 0 && exists {
-		db.Set(KeyNew, value)
+	if inSets {
+		db.setStorage[KeyNew] = set
+		delete(db.setStorage, KeyOld)
 	} else {
-		db.SetWithExpiry(KeyNew, value, ttl)
+		db.data[KeyNew] = value
+		delete(db.data, KeyOld)
+	}
+	if expiry, hasExpiry := db.expires[KeyOld]; hasExpiry {
+		db.expires[KeyNew] = expiry
+		delete(db.expires, KeyOld)
 	}
+	// The value keeps its size, only the length of the key changes
+	db.sizes[KeyNew] = db.sizes[KeyOld]
+	delete(db.sizes, KeyOld)
+	db.resize(KeyNew, int64(len(KeyNew)-len(KeyOld)))
 
-	// Set the new key with the same value and TTL
-	db.Delete(KeyOld)
+	db.modified(KeyOld, ClassGeneric, "rename_from")
+	db.modified(KeyNew, ClassGeneric, "rename_to")
 	return nil
 }
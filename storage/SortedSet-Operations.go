@@ -95,10 +95,21 @@ func (ss *SortedSet) Rank(member string) int {
 	return -1
 }
 
-// ZADD adds one or more members to a sorted set, or updates their score if already exist
+// ZADD adds one or more members to a sorted set, or updates their score if already exist.
+// It returns the number of members added, 0 when key holds another type of value, which is left alone.
 func (db *Database) ZADD(key string, scoreMembers map[string]float64) int {
+	count, _ := db.ZADDChecked(key, scoreMembers)
+	return count
+}
+
+// ZADDChecked is ZADD returning ErrWrongType when key holds another type of value
+func (db *Database) ZADDChecked(key string, scoreMembers map[string]float64) (int, error) {
 	db.mu.Lock()
 	defer db.mu.Unlock()
+	db.expireIfNeeded(key)
+	if err := db.checkType(key, "zset"); err != nil {
+		return 0, err
+	}
 
 	// Check if key exists and is a sorted set
 	val, exists := db.setStorage[key]
@@ -109,15 +120,19 @@ func (db *Database) ZADD(key string, scoreMembers map[string]float64) int {
 
 	// Add members to the sorted set
 	count := 0
-	for score, member := range scoreMembers {
-		if val.Add(member, score) {
+	var delta int64
+	for member, score := range scoreMembers {
+		if val.Add(score, member) {
 			count++
+			delta += memberSize(member)
 		}
 	}
 
 	// Update the sorted set in the storage
 	db.setStorage[key] = val
-	return count
+	db.resize(key, delta)
+	db.modified(key, ClassZSet, "zadd")
+	return count, nil
 }
 
 // ZRANGE returns a range of members in a sorted set, by index
@@ -30,14 +30,12 @@ func (db *Database) TTL(key string) (time.Duration, bool) {
 	// If expired, acquire a write lock to delete the key.
 	if now.After(expiry) {
 		db.mu.Lock()
-		// Double-check the expiry and existence now.
-		if exp, exists := db.expires[key]; exists && now.After(exp) {
-			delete(db.data, key)
-			delete(db.expires, key)
-			db.mu.Unlock()
+		// Double-check the expiry now, the key may have been updated in between
+		expired := db.expireIfNeeded(key)
+		db.mu.Unlock()
+		if expired {
 			return 0, false
 		}
-		db.mu.Unlock()
 	}
 
 	// If not expired, return the remaining time.
@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrWouldBlock is returned by the blocking list operations run inside Atomic when no list
+// holds an element, since nothing else can push one until the transaction ends
+var ErrWouldBlock = errors.New("operation would block")
+
+// dbLock is the lock of a Database. Releasing the write lock serves the clients
+// blocked on the lists written while it was held, so a transaction pushing
+// several elements hands them out once it is complete.
+type dbLock struct {
+	sync.RWMutex
+	db *Database
+}
+
+func (l *dbLock) Unlock() {
+	l.db.serveBlocked()
+	l.RWMutex.Unlock()
+}
+
+// blockedLists holds the clients waiting for an element to be pushed to a list
+type blockedLists struct {
+	// waiters holds the clients blocked on each key, in the order they blocked
+	waiters map[string][]*listWaiter
+
+	// ready holds the keys with waiters written since the lock was taken, in order
+	ready   []string
+	isReady map[string]bool
+}
+
+func newBlockedLists() *blockedLists {
+	return &blockedLists{
+		waiters: make(map[string][]*listWaiter),
+		isReady: make(map[string]bool),
+	}
+}
+
+// listWaiter is a client blocked until one of its keys holds a list element
+type listWaiter struct {
+	keys []string
+
+	// left pops from the head of the list, otherwise from its tail
+	left bool
+
+	// move pushes the popped element to dest, at its head when destLeft is set
+	move     bool
+	dest     string
+	destLeft bool
+
+	// result receives the element popped for the waiter
+	result chan popResult
+}
+
+// popResult is the outcome of a blocking pop
+type popResult struct {
+	key   string
+	value interface{}
+	err   error
+}
+
+// BLPop removes and returns the first element of the first non-empty list among keys, with its key.
+// When every list is empty it waits for an element to be pushed until ctx is done, and returns ctx.Err().
+// Clients blocked on the same key are served in the order they blocked.
+func (db *Database) BLPop(ctx context.Context, keys ...string) (string, interface{}, error) {
+	res := db.block(ctx, &listWaiter{keys: keys, left: true})
+	return res.key, res.value, res.err
+}
+
+// BRPop is BLPop popping the last element of the list
+func (db *Database) BRPop(ctx context.Context, keys ...string) (string, interface{}, error) {
+	res := db.block(ctx, &listWaiter{keys: keys})
+	return res.key, res.value, res.err
+}
+
+// BLMove removes an element of the source list, its first one when fromLeft is set or else its last one,
+// and pushes it to the head of the destination list when toLeft is set or else to its tail.
+// It waits for an element like BLPop, and returns the element moved.
+func (db *Database) BLMove(ctx context.Context, source, destination string, fromLeft, toLeft bool) (interface{}, error) {
+	res := db.block(ctx, &listWaiter{
+		keys:     []string{source},
+		left:     fromLeft,
+		move:     true,
+		dest:     destination,
+		destLeft: toLeft,
+	})
+	return res.value, res.err
+}
+
+// block serves w right away when one of its lists holds an element,
+// or else waits for an element to be pushed
+func (db *Database) block(ctx context.Context, w *listWaiter) popResult {
+	db.mu.Lock()
+	for _, key := range w.keys {
+		db.expireIfNeeded(key)
+		if err := db.checkType(key, "list"); err != nil {
+			db.mu.Unlock()
+			return popResult{err: err}
+		}
+		if res, served := db.popFor(w, key); served {
+			db.mu.Unlock()
+			return res
+		}
+	}
+
+	// Inside Atomic no other client can push until the transaction ends
+	if _, inAtomic := db.mu.(noLock); inAtomic {
+		db.mu.Unlock()
+		return popResult{err: ErrWouldBlock}
+	}
+	if err := ctx.Err(); err != nil {
+		db.mu.Unlock()
+		return popResult{err: err}
+	}
+
+	w.result = make(chan popResult, 1)
+	for _, key := range w.keys {
+		db.blocked.waiters[key] = append(db.blocked.waiters[key], w)
+	}
+	db.mu.Unlock()
+
+	select {
+	case res := <-w.result:
+		return res
+	case <-ctx.Done():
+	}
+
+	// The waiter may have been served while ctx was being cancelled
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	select {
+	case res := <-w.result:
+		return res
+	default:
+		db.unblock(w)
+		return popResult{err: ctx.Err()}
+	}
+}
+
+// popFor pops an element of the list at key for w, moving it when w is a BLMOVE.
+// It reports false when key holds no list element. It must be called with the write lock held.
+func (db *Database) popFor(w *listWaiter, key string) (popResult, bool) {
+	list, ok := db.data[key].([]interface{})
+	if !ok || len(list) == 0 {
+		return popResult{}, false
+	}
+
+	if w.move {
+		if err := db.checkType(w.dest, "list"); err != nil {
+			return popResult{err: err}, true
+		}
+	}
+
+	var value interface{}
+	if w.left {
+		value, db.data[key] = list[0], list[1:]
+		db.resize(key, -sizeOf(value))
+		db.modified(key, ClassList, "lpop")
+		db.deleteIfEmpty(key)
+	} else {
+		value, db.data[key] = list[len(list)-1], list[:len(list)-1]
+		db.resize(key, -sizeOf(value))
+		db.modified(key, ClassList, "rpop")
+		db.deleteIfEmpty(key)
+	}
+
+	if w.move {
+		dest, _ := db.data[w.dest].([]interface{})
+		if w.destLeft {
+			db.data[w.dest] = append([]interface{}{value}, dest...)
+			db.resize(w.dest, sizeOf(value))
+			db.modified(w.dest, ClassList, "lpush")
+		} else {
+			db.data[w.dest] = append(dest, value)
+			db.resize(w.dest, sizeOf(value))
+			db.modified(w.dest, ClassList, "rpush")
+		}
+	}
+	return popResult{key: key, value: value}, true
+}
+
+// markReady records that key was written, so its waiters are served when the lock is released
+func (db *Database) markReady(key string) {
+	if len(db.blocked.waiters[key]) == 0 || db.blocked.isReady[key] {
+		return
+	}
+	db.blocked.isReady[key] = true
+	db.blocked.ready = append(db.blocked.ready, key)
+}
+
+// serveBlocked hands the elements of the lists written while the lock was held
+// to the clients waiting for them, in the order they blocked.
+// A key written with another type than a list keeps its clients waiting.
+func (db *Database) serveBlocked() {
+	for len(db.blocked.ready) > 0 {
+		key := db.blocked.ready[0]
+		db.blocked.ready = db.blocked.ready[1:]
+		delete(db.blocked.isReady, key)
+
+		db.expireIfNeeded(key)
+		for len(db.blocked.waiters[key]) > 0 {
+			w := db.blocked.waiters[key][0]
+			res, served := db.popFor(w, key)
+			if !served {
+				break
+			}
+			db.unblock(w)
+			w.result <- res
+		}
+	}
+}
+
+// unblock removes w from the waiters of its keys
+func (db *Database) unblock(w *listWaiter) {
+	for _, key := range w.keys {
+		waiters := db.blocked.waiters[key]
+		for i := 0; i < len(waiters); i++ {
+			if waiters[i] == w {
+				waiters = append(waiters[:i], waiters[i+1:]...)
+				i--
+			}
+		}
+		if len(waiters) == 0 {
+			delete(db.blocked.waiters, key)
+		} else {
+			db.blocked.waiters[key] = waiters
+		}
+	}
+}
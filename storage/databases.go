@@ -0,0 +1,230 @@
+package storage
+
+import "errors"
+
+// ErrIndexOutOfRange is returned when a database index is not one of a Databases
+var ErrIndexOutOfRange = errors.New("DB index is out of range")
+
+// ErrSameDatabase is returned when moving a key to the database it is in
+var ErrSameDatabase = errors.New("source and destination objects are the same")
+
+// Databases is a fixed set of numbered databases sharing a memory limit.
+// Clients select the database their commands run against by its index.
+type Databases struct {
+	dbs []*Database
+}
+
+// NewDatabases creates n empty databases, numbered from 0
+func NewDatabases(n int) *Databases {
+	dbs := make([]*Database, n)
+	for i := range dbs {
+		dbs[i] = NewDatabase()
+	}
+	return DatabasesOf(dbs...)
+}
+
+// DatabasesOf numbers existing databases in the order given.
+// From then on they share the memory limit and the counters of the first one.
+func DatabasesOf(dbs ...*Database) *Databases {
+	memory, stats := dbs[0].memory, dbs[0].stats
+	for i, db := range dbs {
+		db.mu.Lock()
+		db.index = i
+		if db.memory != memory {
+			memory.used.Add(db.memory.used.Load())
+			db.memory = memory
+		}
+		if db.stats != stats {
+			stats.hits.Add(db.stats.hits.Load())
+			stats.misses.Add(db.stats.misses.Load())
+			stats.expired.Add(db.stats.expired.Load())
+			stats.evicted.Add(db.stats.evicted.Load())
+			db.stats = stats
+		}
+		db.mu.Unlock()
+	}
+	return &Databases{dbs: dbs}
+}
+
+// Len returns the number of databases
+func (d *Databases) Len() int {
+	return len(d.dbs)
+}
+
+// DB returns the database at index, nil when there is none
+func (d *Databases) DB(index int) *Database {
+	if index < 0 || index >= len(d.dbs) {
+		return nil
+	}
+	return d.dbs[index]
+}
+
+// Index returns the number of the database in its Databases, 0 for a database on its own
+func (db *Database) Index() int {
+	return db.index
+}
+
+// Atomic runs fn with exclusive access to every database, like Database.Atomic.
+// The databases of tx are views of these databases with the same indexes.
+func (d *Databases) Atomic(fn func(tx *Databases)) {
+	views := make([]*Database, len(d.dbs))
+	for i, db := range d.dbs {
+		db.mu.Lock()
+		views[i] = db.view()
+	}
+	defer func() {
+		for i := len(d.dbs) - 1; i >= 0; i-- {
+			d.dbs[i].mu.Unlock()
+		}
+	}()
+	fn(&Databases{dbs: views})
+}
+
+// Swap exchanges the keys of two databases atomically, so the clients using one of them
+// see the keys of the other. The watches on the keys of both are touched,
+// and the clients blocked on their lists are served from the new keys.
+func (d *Databases) Swap(a, b int) error {
+	first, second := d.DB(a), d.DB(b)
+	if first == nil || second == nil {
+		return ErrIndexOutOfRange
+	}
+	if a == b {
+		return nil
+	}
+
+	unlock := lockPair(first, second)
+	defer unlock()
+
+	first.data, second.data = second.data, first.data
+	first.setStorage, second.setStorage = second.setStorage, first.setStorage
+	first.expires, second.expires = second.expires, first.expires
+	first.sizes, second.sizes = second.sizes, first.sizes
+
+	for _, db := range []*Database{first, second} {
+		// A view swaps the keys of the database it was taken from as well
+		if db.origin != nil {
+			db.origin.data, db.origin.setStorage = db.data, db.setStorage
+			db.origin.expires, db.origin.sizes = db.expires, db.sizes
+		}
+		for key := range db.watchers {
+			db.touch(key)
+		}
+		for key := range db.blocked.waiters {
+			db.markReady(key)
+		}
+	}
+	return nil
+}
+
+// FlushAll deletes the keys of every database at once
+func (d *Databases) FlushAll() {
+	d.Atomic(func(tx *Databases) {
+		for _, db := range tx.dbs {
+			db.Flush()
+		}
+	})
+}
+
+// Flush deletes every key of the database. The watches on them are touched, no event is notified.
+func (db *Database) Flush() {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// The maps are cleared in place, views created by Atomic share them
+	var used int64
+	for _, size := range db.sizes {
+		used += size
+	}
+	db.memory.used.Add(-used)
+	clear(db.data)
+	clear(db.setStorage)
+	clear(db.expires)
+	clear(db.sizes)
+
+	for key := range db.watchers {
+		db.touch(key)
+	}
+}
+
+// Move moves key and its expiry to dest, another database of the same Databases.
+// It reports false when the key does not exist, or dest already holds a key of that name.
+func (db *Database) Move(key string, dest *Database) (bool, error) {
+	if db.index == dest.index {
+		return false, ErrSameDatabase
+	}
+
+	unlock := lockPair(db, dest)
+	defer unlock()
+
+	db.expireIfNeeded(key)
+	dest.expireIfNeeded(key)
+	if !db.exists(key) || dest.exists(key) {
+		return false, nil
+	}
+
+	if value, isData := db.data[key]; isData {
+		dest.data[key] = value
+		delete(db.data, key)
+	} else {
+		dest.setStorage[key] = db.setStorage[key]
+		delete(db.setStorage, key)
+	}
+	if expiry, hasExpiry := db.expires[key]; hasExpiry {
+		dest.expires[key] = expiry
+		delete(db.expires, key)
+	}
+	// The databases share the memory used, only the size of the key moves
+	dest.sizes[key] = db.sizes[key]
+	delete(db.sizes, key)
+
+	db.modified(key, ClassGeneric, "move_from")
+	dest.modified(key, ClassGeneric, "move_to")
+	return true, nil
+}
+
+// exists reports whether the database holds key, it is called with the lock held
+func (db *Database) exists(key string) bool {
+	_, isData := db.data[key]
+	_, isSet := db.setStorage[key]
+	return isData || isSet
+}
+
+// lockPair takes the write locks of two databases in the order of their indexes,
+// so operations locking the same pair cannot deadlock. It returns the function releasing them.
+func lockPair(a, b *Database) func() {
+	if b.index < a.index {
+		a, b = b, a
+	}
+	a.mu.Lock()
+	b.mu.Lock()
+	return func() {
+		b.mu.Unlock()
+		a.mu.Unlock()
+	}
+}
+
+// SetMaxMemory limits the memory used by the keys of every database together, see Database.SetMaxMemory
+func (d *Databases) SetMaxMemory(limit int64, policy EvictionPolicy) error {
+	return d.dbs[0].SetMaxMemory(limit, policy)
+}
+
+// UsedMemory returns an estimate of the memory used by the keys of every database, in bytes
+func (d *Databases) UsedMemory() int64 {
+	return d.dbs[0].UsedMemory()
+}
+
+// MaxMemory returns the memory limit shared by the databases and the eviction policy
+func (d *Databases) MaxMemory() (int64, EvictionPolicy) {
+	return d.dbs[0].MaxMemory()
+}
+
+// FreeMemory evicts keys from the databases in turn until the memory used is back under the limit.
+// It reports false when the limit is still exceeded: nothing can be evicted under the policy.
+func (d *Databases) FreeMemory() bool {
+	for _, db := range d.dbs {
+		if db.FreeMemory() {
+			return true
+		}
+	}
+	return false
+}
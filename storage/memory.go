@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// EvictionPolicy selects the keys evicted once the memory limit is reached
+type EvictionPolicy string
+
+const (
+	// NoEviction evicts nothing, the commands adding data are refused instead
+	NoEviction EvictionPolicy = "noeviction"
+
+	// AllKeysRandom evicts random keys
+	AllKeysRandom EvictionPolicy = "allkeys-random"
+
+	// VolatileRandom evicts random keys among those with an expiry
+	VolatileRandom EvictionPolicy = "volatile-random"
+
+	// VolatileTTL evicts the keys closest to their expiry first
+	VolatileTTL EvictionPolicy = "volatile-ttl"
+)
+
+// EvictionPolicies lists the supported eviction policies
+var EvictionPolicies = []EvictionPolicy{NoEviction, AllKeysRandom, VolatileRandom, VolatileTTL}
+
+// ttlSamples is how many keys with an expiry VolatileTTL compares to pick the one to evict
+const ttlSamples = 5
+
+// Rough sizes used to estimate the memory used by a key, in bytes
+const (
+	keyOverhead    = 64 // map entry and header of the key
+	expiryOverhead = 32 // entry in the expires map
+	valueOverhead  = 16 // interface header of a value or element
+)
+
+// memoryUsage holds the estimated memory used by the keys and the limit enforced by FreeMemory.
+// It is shared by the databases of a Databases, each guarding the sizes of its own keys.
+type memoryUsage struct {
+	limit  atomic.Int64
+	policy atomic.Value // EvictionPolicy
+
+	// used is the sum of the estimated sizes of every key
+	used atomic.Int64
+}
+
+func newMemoryUsage() *memoryUsage {
+	m := &memoryUsage{}
+	m.policy.Store(NoEviction)
+	return m
+}
+
+// SetMaxMemory limits the memory used by the keys to limit bytes, 0 for no limit,
+// and sets how keys are evicted by FreeMemory once it is reached
+func (db *Database) SetMaxMemory(limit int64, policy EvictionPolicy) error {
+	if limit < 0 {
+		return fmt.Errorf("invalid memory limit %d", limit)
+	}
+	if !validPolicy(policy) {
+		return fmt.Errorf("unknown eviction policy %q", policy)
+	}
+
+	db.memory.policy.Store(policy)
+	db.memory.limit.Store(limit)
+	return nil
+}
+
+// UsedMemory returns an estimate of the memory used by the keys, in bytes.
+// The databases of a Databases report the memory they use together.
+func (db *Database) UsedMemory() int64 {
+	return db.memory.used.Load()
+}
+
+// MaxMemory returns the memory limit in bytes, 0 when there is none, and the eviction policy
+func (db *Database) MaxMemory() (int64, EvictionPolicy) {
+	return db.memory.limit.Load(), db.memory.policy.Load().(EvictionPolicy)
+}
+
+// overLimit reports whether the memory used exceeds the limit
+func (m *memoryUsage) overLimit() bool {
+	limit := m.limit.Load()
+	return limit > 0 && m.used.Load() > limit
+}
+
+// FreeMemory evicts keys of the database following the eviction policy until the memory used
+// is back under the limit. It reports false when the limit is still exceeded:
+// nothing can be evicted from the database under the policy.
+func (db *Database) FreeMemory() bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for db.memory.overLimit() {
+		key, found := db.evictionCandidate()
+		if !found {
+			return false
+		}
+		delete(db.data, key)
+		delete(db.setStorage, key)
+		delete(db.expires, key)
+		db.account(key)
+		db.stats.evicted.Add(1)
+		db.modified(key, ClassEvicted, "evicted")
+	}
+	return true
+}
+
+// evictionCandidate picks the next key to evict under the eviction policy.
+// Map iteration starts at a random entry, which makes the first key a random one.
+func (db *Database) evictionCandidate() (string, bool) {
+	switch db.memory.policy.Load().(EvictionPolicy) {
+	case AllKeysRandom:
+		for key := range db.data {
+			return key, true
+		}
+		for key := range db.setStorage {
+			return key, true
+		}
+
+	case VolatileRandom:
+		for key := range db.expires {
+			return key, true
+		}
+
+	case VolatileTTL:
+		candidate, soonest, sampled := "", time.Time{}, 0
+		for key, expiry := range db.expires {
+			if sampled == 0 || expiry.Before(soonest) {
+				candidate, soonest = key, expiry
+			}
+			if sampled++; sampled == ttlSamples {
+				break
+			}
+		}
+		return candidate, sampled > 0
+	}
+	return "", false
+}
+
+// account sets the estimated size of key from the value it holds, or forgets it once deleted.
+// It is called with the lock held by the writes storing a whole value or deleting a key,
+// those adding or removing elements of a value call resize instead.
+func (db *Database) account(key string) {
+	var size int64
+	if value, exists := db.data[key]; exists {
+		size = keyOverhead + int64(len(key)) + sizeOf(value)
+	} else if set, exists := db.setStorage[key]; exists {
+		size = keyOverhead + int64(len(key)) + sizeOf(set)
+	}
+	if size > 0 {
+		if _, hasExpiry := db.expires[key]; hasExpiry {
+			size += expiryOverhead
+		}
+	}
+
+	db.memory.used.Add(size - db.sizes[key])
+	if size == 0 {
+		delete(db.sizes, key)
+	} else {
+		db.sizes[key] = size
+	}
+}
+
+// resize adds delta to the estimated size of key after elements were added to or removed from
+// its value, it is called with the lock held. A key without a size yet was just created
+// and is sized from its whole value.
+func (db *Database) resize(key string, delta int64) {
+	if _, sized := db.sizes[key]; !sized {
+		db.account(key)
+		return
+	}
+	db.sizes[key] += delta
+	db.memory.used.Add(delta)
+}
+
+// sizeOf estimates the memory used by a value
+func sizeOf(value interface{}) int64 {
+	switch v := value.(type) {
+	case string:
+		return valueOverhead + int64(len(v))
+	case []interface{}:
+		size := int64(valueOverhead + 24)
+		return size + elementsSize(v)
+	case map[string]interface{}:
+		size := int64(valueOverhead + 48)
+		for field, element := range v {
+			size += fieldSize(field, element)
+		}
+		return size
+	case *SortedSet:
+		size := int64(valueOverhead + 24)
+		for _, item := range v.Items {
+			size += memberSize(item.Member)
+		}
+		return size
+	default:
+		return valueOverhead + 8
+	}
+}
+
+// elementsSize estimates the memory used by elements of a list
+func elementsSize(elements []interface{}) int64 {
+	var size int64
+	for _, element := range elements {
+		size += sizeOf(element)
+	}
+	return size
+}
+
+// fieldSize estimates the memory used by a field of a hash
+func fieldSize(field string, value interface{}) int64 {
+	return int64(len(field)) + valueOverhead + sizeOf(value)
+}
+
+// memberSize estimates the memory used by a member of a sorted set and its score
+func memberSize(member string) int64 {
+	return int64(len(member)) + valueOverhead + 8
+}
+
+func validPolicy(policy EvictionPolicy) bool {
+	for _, p := range EvictionPolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package storage
+
+import "time"
+
+// Classes of the events reported to the Notifier, named after the flags
+// selecting them in keyspace notifications
+const (
+	ClassGeneric = 'g' // commands working on any type, like DEL, EXPIRE and RENAME
+	ClassString  = '$'
+	ClassList    = 'l'
+	ClassHash    = 'h'
+	ClassZSet    = 'z'
+	ClassExpired = 'x' // a key was deleted because it expired
+	ClassEvicted = 'e' // a key was evicted to free memory
+)
+
+// Notifier is called after every change of a key, with the class of the event
+// and its name, like "set" or "expired". It runs with the database lock held
+// and must not use the database.
+type Notifier func(class byte, event string, key string)
+
+// SetNotifier sets the function called on every change of a key, nil to disable it
+func (db *Database) SetNotifier(notifier Notifier) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.notifier = notifier
+}
+
+// modified records a change of key: the watches on it are touched, the clients blocked on it
+// are served once the lock is released and the event is notified
+func (db *Database) modified(key string, class byte, event string) {
+	db.touch(key)
+	db.markReady(key)
+	if db.notifier != nil {
+		db.notifier(class, event, key)
+	}
+}
+
+// expireIfNeeded deletes key if its expiry has passed, and reports whether it did.
+// It must be called with the write lock held.
+func (db *Database) expireIfNeeded(key string) bool {
+	expiry, hasExpiry := db.expires[key]
+	if !hasExpiry || !time.Now().After(expiry) {
+		return false
+	}
+
+	delete(db.data, key)
+	delete(db.expires, key)
+	db.account(key)
+	db.stats.expired.Add(1)
+	db.modified(key, ClassExpired, "expired")
+	return true
+}
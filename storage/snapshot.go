@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"time"
+)
+
+func init() {
+	// The containers stored through interface{} values must be known to gob
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+}
+
+// snapshot is the content of a Database as written by Save
+type snapshot struct {
+	Data       map[string]interface{}
+	SetStorage map[string]*SortedSet
+	Expires    map[string]time.Time
+}
+
+// Save writes every key of the database to w, with its expiry.
+// Expired keys are left out.
+func (db *Database) Save(w io.Writer) error {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	snap := db.snapshot(time.Now())
+	return gob.NewEncoder(w).Encode(&snap)
+}
+
+// Load replaces the content of the database with the keys written by Save.
+// Keys whose expiry passed since are dropped.
+func (db *Database) Load(r io.Reader) error {
+	var snap snapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.restore(snap, time.Now())
+	return nil
+}
+
+// Save writes the keys of every database to w, like Database.Save,
+// at a single point in time
+func (d *Databases) Save(w io.Writer) error {
+	var err error
+	d.Atomic(func(tx *Databases) {
+		// The snapshots share the sorted sets of the databases, they are encoded before unlocking
+		snaps := make([]snapshot, len(tx.dbs))
+		now := time.Now()
+		for i, db := range tx.dbs {
+			snaps[i] = db.snapshot(now)
+		}
+		err = gob.NewEncoder(w).Encode(snaps)
+	})
+	return err
+}
+
+// Load replaces the content of every database with the keys written by Databases.Save.
+// It fails when keys were saved in a database beyond the last one.
+func (d *Databases) Load(r io.Reader) error {
+	var snaps []snapshot
+	if err := gob.NewDecoder(r).Decode(&snaps); err != nil {
+		return err
+	}
+	for i := len(d.dbs); i < len(snaps); i++ {
+		if len(snaps[i].Data) > 0 || len(snaps[i].SetStorage) > 0 {
+			return fmt.Errorf("keys saved in database %d, beyond the %d databases", i, len(d.dbs))
+		}
+	}
+
+	d.Atomic(func(tx *Databases) {
+		now := time.Now()
+		for i, db := range tx.dbs {
+			var snap snapshot
+			if i < len(snaps) {
+				snap = snaps[i]
+			}
+			db.restore(snap, now)
+		}
+	})
+	return nil
+}
+
+// snapshot returns the keys of the database not expired at now, it is called with the lock held
+func (db *Database) snapshot(now time.Time) snapshot {
+	snap := snapshot{
+		Data:       make(map[string]interface{}, len(db.data)),
+		SetStorage: db.setStorage,
+		Expires:    make(map[string]time.Time, len(db.expires)),
+	}
+	for key, value := range db.data {
+		if expiry, hasExpiry := db.expires[key]; hasExpiry {
+			if now.After(expiry) {
+				continue
+			}
+			snap.Expires[key] = expiry
+		}
+		snap.Data[key] = value
+	}
+	return snap
+}
+
+// restore replaces the keys of the database with those of snap not expired at now,
+// it is called with the lock held
+func (db *Database) restore(snap snapshot, now time.Time) {
+	// The maps are cleared in place, views created by Atomic share them
+	for key := range db.data {
+		delete(db.data, key)
+		db.touch(key)
+	}
+	for key := range db.setStorage {
+		delete(db.setStorage, key)
+		db.touch(key)
+	}
+	for key := range db.expires {
+		delete(db.expires, key)
+	}
+	for key := range db.sizes {
+		db.account(key)
+	}
+
+	for key, value := range snap.Data {
+		if expiry, hasExpiry := snap.Expires[key]; hasExpiry {
+			if now.After(expiry) {
+				continue
+			}
+			db.expires[key] = expiry
+		}
+		db.data[key] = value
+		db.touch(key)
+		db.account(key)
+	}
+	for key, set := range snap.SetStorage {
+		db.setStorage[key] = set
+		db.touch(key)
+		db.account(key)
+	}
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Stats counts the lookups and the keys removed by the server, for INFO.
+// The databases of a Databases count together.
+type Stats struct {
+	// KeyspaceHits and KeyspaceMisses count the lookups of Get that found the key or not
+	KeyspaceHits   int64
+	KeyspaceMisses int64
+
+	// ExpiredKeys counts the keys deleted because their expiry passed,
+	// EvictedKeys those deleted to free memory
+	ExpiredKeys int64
+	EvictedKeys int64
+}
+
+// statsCounters holds the counters reported by Stats, shared like memoryUsage
+type statsCounters struct {
+	hits, misses     atomic.Int64
+	expired, evicted atomic.Int64
+}
+
+// Stats returns the counters of the database
+func (db *Database) Stats() Stats {
+	return Stats{
+		KeyspaceHits:   db.stats.hits.Load(),
+		KeyspaceMisses: db.stats.misses.Load(),
+		ExpiredKeys:    db.stats.expired.Load(),
+		EvictedKeys:    db.stats.evicted.Load(),
+	}
+}
+
+// Stats returns the counters of every database together
+func (d *Databases) Stats() Stats {
+	return d.dbs[0].Stats()
+}
+
+// KeyspaceInfo describes the keys of a database, like the keyspace section of INFO
+type KeyspaceInfo struct {
+	// Keys is the number of keys, Expires how many of them have an expiry
+	Keys    int
+	Expires int
+
+	// AvgTTL is the average time left before the keys with an expiry expire
+	AvgTTL time.Duration
+}
+
+// KeyspaceInfo counts the keys of the database.
+// The expired keys not deleted yet are counted, as they are until accessed.
+func (db *Database) KeyspaceInfo() KeyspaceInfo {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	info := KeyspaceInfo{Keys: len(db.data) + len(db.setStorage), Expires: len(db.expires)}
+	if info.Expires == 0 {
+		return info
+	}
+
+	now := time.Now()
+	var total time.Duration
+	for _, expiry := range db.expires {
+		if ttl := expiry.Sub(now); ttl > 0 {
+			total += ttl
+		}
+	}
+	info.AvgTTL = total / time.Duration(info.Expires)
+	return info
+}
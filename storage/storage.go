@@ -1,7 +1,6 @@
 package storage
 
 import (
-	"sync"
 	"time"
 )
 
@@ -17,6 +16,7 @@ type DB interface {
 	Keys() []string
 	Get(key string) (interface{}, bool)
 	GETDEL(key string) (interface{}, bool)
+	Type(key string) string
 
 	// DEL Operations
 	Delete(key string) bool
@@ -30,13 +30,13 @@ type DB interface {
 	TTL(key string) (time.Duration, bool)
 
 	// Sorted Set Operations
-	ZADD(key string, scoreMembers map[float64]string) (int, error)
-	ZRANGE(key string, start, stop int, withScores bool) ([]interface{}, error)
+	ZADD(key string, scoreMembers map[string]float64) int
+	ZRANGE(key string, start, stop int, withScores bool) []interface{}
 	ZRANK(key, member string) (int, bool)
 
 	// Numeric Operations
-	Incr(key string, value int) (int, error)
-	Decr(key string, value int) (int, error)
+	Incr(key string) (int, error)
+	Decr(key string) (int, error)
 
 	// Hash Operations
 	HSET(key string, field string, value interface{}) (bool, error)
@@ -46,21 +46,55 @@ type DB interface {
 	HKEYS(key string) ([]string, bool)
 	HVALS(key string) ([]interface{}, bool)
 	HLEN(key string) (int, bool)
+	HMSET(key string, fields map[string]interface{}) (int, error)
+	HDELFields(key string, fields ...string) (int, error)
 }
 
+var _ DB = (*Database)(nil)
+
 // Database represents "in-memory" Redis-like database
 type Database struct {
 	data       map[string]interface{}
 	setStorage map[string]*SortedSet
-	mu         sync.RWMutex // Mutex for concurrent access
-	expires    map[string]time.Time
+	mu         locker // Mutex for concurrent access, a no-op inside Atomic
+
+	expires map[string]time.Time
+
+	// watchers holds the watches registered on each key, see Watch
+	watchers map[string]map[*Watch]struct{}
+
+	// notifier is told about every change of a key, see SetNotifier
+	notifier Notifier
+
+	// blocked holds the clients waiting for list elements, see BLPop
+	blocked *blockedLists
+
+	// sizes holds the estimated size of every key, memory their sum and the memory limit
+	sizes  map[string]int64
+	memory *memoryUsage
+
+	// stats holds the counters returned by Stats
+	stats *statsCounters
+
+	// index is the number of the database in its Databases
+	index int
+
+	// origin is the database a view created by Atomic was taken from, nil for the database itself
+	origin *Database
 }
 
 // NewDatabase creates a new "in-memory" database
 func NewDatabase() *Database {
-	return &Database{
+	db := &Database{
 		data:       make(map[string]interface{}),
 		setStorage: make(map[string]*SortedSet),
 		expires:    make(map[string]time.Time),
+		watchers:   make(map[string]map[*Watch]struct{}),
+		blocked:    newBlockedLists(),
+		sizes:      make(map[string]int64),
+		memory:     newMemoryUsage(),
+		stats:      &statsCounters{},
 	}
+	db.mu = &dbLock{db: db}
+	return db
 }
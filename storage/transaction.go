@@ -0,0 +1,37 @@
+package storage
+
+// locker is the lock guarding a Database,
+// a sync.RWMutex normally and noLock for the view passed to Atomic
+type locker interface {
+	Lock()
+	Unlock()
+	RLock()
+	RUnlock()
+}
+
+// noLock is used by the view of a Database whose lock is already held
+type noLock struct{}
+
+func (noLock) Lock()    {}
+func (noLock) Unlock()  {}
+func (noLock) RLock()   {}
+func (noLock) RUnlock() {}
+
+// Atomic runs fn with exclusive access to the database.
+// Every operation fn performs on tx applies to this database,
+// and no other operation can run until fn returns.
+// tx must not be used after fn returns, and fn must not use db itself or it deadlocks.
+func (db *Database) Atomic(fn func(tx *Database)) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	fn(db.view())
+}
+
+// view returns a view of the database for Atomic, to use while its lock is held.
+// It shares the maps of the database, only the lock differs.
+func (db *Database) view() *Database {
+	tx := *db
+	tx.mu = noLock{}
+	tx.origin = db
+	return &tx
+}
@@ -0,0 +1,84 @@
+package storage
+
+import "time"
+
+// Watch tracks the keys a client watches for optimistic locking.
+// Any write to a watched key, including its deletion or expiry, marks the watch as touched.
+// A Watch is used through the Database it was registered with, under its lock.
+type Watch struct {
+	// keys maps every watched key to the time it was due to expire when watched,
+	// the zero time if it had no expiry
+	keys map[string]time.Time
+
+	// touched is set when a watched key was written
+	touched bool
+}
+
+// touch marks every watch on key as touched, it is called by every write with the lock held
+func (db *Database) touch(key string) {
+	for w := range db.watchers[key] {
+		w.touched = true
+	}
+}
+
+// Watch starts watching keys with w
+func (db *Database) Watch(w *Watch, keys ...string) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if w.keys == nil {
+		w.keys = make(map[string]time.Time)
+	}
+
+	now := time.Now()
+	for _, key := range keys {
+		if _, watched := w.keys[key]; watched {
+			continue
+		}
+
+		// A key expiring while watched counts as a write, unless it had already expired
+		var deadline time.Time
+		if expiry, hasExpiry := db.expires[key]; hasExpiry && now.Before(expiry) {
+			deadline = expiry
+		}
+		w.keys[key] = deadline
+
+		if db.watchers[key] == nil {
+			db.watchers[key] = make(map[*Watch]struct{})
+		}
+		db.watchers[key][w] = struct{}{}
+	}
+}
+
+// Unwatch stops watching every key of w and clears its touched state
+func (db *Database) Unwatch(w *Watch) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for key := range w.keys {
+		delete(db.watchers[key], w)
+		if len(db.watchers[key]) == 0 {
+			delete(db.watchers, key)
+		}
+	}
+	w.keys = nil
+	w.touched = false
+}
+
+// Touched reports whether a key watched by w was written or expired since it was watched
+func (db *Database) Touched(w *Watch) bool {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	if w.touched {
+		return true
+	}
+
+	now := time.Now()
+	for _, deadline := range w.keys {
+		if !deadline.IsZero() && now.After(deadline) {
+			return true
+		}
+	}
+	return false
+}
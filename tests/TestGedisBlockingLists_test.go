@@ -0,0 +1,238 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/gedis"
+	redis "github.com/GedisCaching/Gedis/server"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// executeAsync runs a command in its own goroutine, the reply is sent on the returned channel
+func executeAsync(client *RESP.Client, command string, args ...string) <-chan string {
+	reply := make(chan string, 1)
+	go func() {
+		reply <- execute(client, command, args...)
+	}()
+	return reply
+}
+
+// waitReply returns the reply of a command run with executeAsync, or fails after a second
+func waitReply(t *testing.T, reply <-chan string) string {
+	t.Helper()
+	select {
+	case r := <-reply:
+		return r
+	case <-time.After(time.Second):
+		t.Fatal("Expected the blocked command to return")
+		return ""
+	}
+}
+
+// waitBlocked gives a command run with executeAsync the time to block
+func waitBlocked() {
+	time.Sleep(20 * time.Millisecond)
+}
+
+func TestRESPBlockingLists(t *testing.T) {
+	handler := RESP.NewHandler(storage.NewDatabase())
+	client := handler.NewClient()
+
+	// Test an element already in a list is returned without blocking
+	t.Run("Immediate", func(t *testing.T) {
+		execute(client, "RPUSH", "jobs", "a", "b")
+		if reply := execute(client, "BLPOP", "empty", "jobs", "0"); reply != "*2\r\n$4\r\njobs\r\n$1\r\na\r\n" {
+			t.Errorf("Expected jobs and a, got %q", reply)
+		}
+		if reply := execute(client, "BRPOP", "jobs", "0"); reply != "*2\r\n$4\r\njobs\r\n$1\r\nb\r\n" {
+			t.Errorf("Expected jobs and b, got %q", reply)
+		}
+	})
+
+	// Test timeouts and invalid arguments
+	t.Run("Timeout", func(t *testing.T) {
+		start := time.Now()
+		if reply := execute(client, "BLPOP", "empty", "0.05"); reply != "*-1\r\n" {
+			t.Errorf("Expected a null array, got %q", reply)
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Errorf("Expected BLPOP to wait for its timeout, returned after %v", elapsed)
+		}
+		if reply := execute(client, "BLMOVE", "empty", "dest", "LEFT", "RIGHT", "0.01"); reply != "$-1\r\n" {
+			t.Errorf("Expected a null reply, got %q", reply)
+		}
+		if reply := execute(client, "BLPOP", "empty", "-1"); reply != "-ERR timeout is negative\r\n" {
+			t.Errorf("Expected a negative timeout error, got %q", reply)
+		}
+		if reply := execute(client, "BLPOP", "empty", "soon"); reply != "-ERR timeout is not a float or out of range\r\n" {
+			t.Errorf("Expected an invalid timeout error, got %q", reply)
+		}
+		if reply := execute(client, "BLMOVE", "a", "b", "UP", "LEFT", "0"); reply != "-ERR syntax error\r\n" {
+			t.Errorf("Expected a syntax error, got %q", reply)
+		}
+	})
+
+	// Test blocked clients are served in the order they blocked
+	t.Run("FIFO", func(t *testing.T) {
+		first, second := handler.NewClient(), handler.NewClient()
+		firstReply := executeAsync(first, "BLPOP", "queue", "other", "0")
+		waitBlocked()
+		secondReply := executeAsync(second, "BLPOP", "queue", "0")
+		waitBlocked()
+
+		execute(client, "RPUSH", "queue", "1", "2")
+		if reply := waitReply(t, firstReply); reply != "*2\r\n$5\r\nqueue\r\n$1\r\n1\r\n" {
+			t.Errorf("Expected the first client to get 1, got %q", reply)
+		}
+		if reply := waitReply(t, secondReply); reply != "*2\r\n$5\r\nqueue\r\n$1\r\n2\r\n" {
+			t.Errorf("Expected the second client to get 2, got %q", reply)
+		}
+
+		// The first client no longer waits on its other key
+		execute(client, "RPUSH", "other", "x")
+		if reply := execute(client, "LLEN", "other"); reply != ":1\r\n" {
+			t.Errorf("Expected the element to stay in the list, got %q", reply)
+		}
+	})
+
+	// Test BLMOVE moves the element pushed to the source list
+	t.Run("BLMOVE", func(t *testing.T) {
+		blocked := handler.NewClient()
+		reply := executeAsync(blocked, "BLMOVE", "source", "dest", "RIGHT", "LEFT", "0")
+		waitBlocked()
+
+		execute(client, "LPUSH", "source", "item")
+		if r := waitReply(t, reply); r != "$4\r\nitem\r\n" {
+			t.Errorf("Expected item, got %q", r)
+		}
+		if r := execute(client, "LRANGE", "dest", "0", "-1"); r != "*1\r\n$4\r\nitem\r\n" {
+			t.Errorf("Expected item in dest, got %q", r)
+		}
+	})
+
+	// Test renaming a list onto the key wakes the client, while a string overwriting it does not
+	t.Run("RENAME And Overwrite", func(t *testing.T) {
+		blocked := handler.NewClient()
+		reply := executeAsync(blocked, "BLPOP", "target", "0")
+		waitBlocked()
+
+		execute(client, "SET", "target", "string")
+		execute(client, "DEL", "target")
+		select {
+		case r := <-reply:
+			t.Fatalf("Expected the client to stay blocked, got %q", r)
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		execute(client, "RPUSH", "staging", "renamed")
+		execute(client, "RENAME", "staging", "target")
+		if r := waitReply(t, reply); r != "*2\r\n$6\r\ntarget\r\n$7\r\nrenamed\r\n" {
+			t.Errorf("Expected the renamed element, got %q", r)
+		}
+	})
+
+	// Test the element pushed by a transaction is handed out once it is complete
+	t.Run("MULTI", func(t *testing.T) {
+		blocked := handler.NewClient()
+		reply := executeAsync(blocked, "BLPOP", "txlist", "0")
+		waitBlocked()
+
+		execute(client, "MULTI")
+		execute(client, "RPUSH", "txlist", "a")
+		execute(client, "LLEN", "txlist")
+		if r := execute(client, "EXEC"); r != "*2\r\n:1\r\n:1\r\n" {
+			t.Errorf("Expected the push to be visible within the transaction, got %q", r)
+		}
+		if r := waitReply(t, reply); r != "*2\r\n$6\r\ntxlist\r\n$1\r\na\r\n" {
+			t.Errorf("Expected a, got %q", r)
+		}
+
+		// Inside a transaction the commands don't block
+		execute(client, "MULTI")
+		execute(client, "BLPOP", "txlist", "0")
+		if r := execute(client, "EXEC"); r != "*1\r\n*-1\r\n" {
+			t.Errorf("Expected a null array, got %q", r)
+		}
+	})
+
+	// Test closing the connection releases the blocked command
+	t.Run("Close", func(t *testing.T) {
+		blocked := handler.NewClient()
+		reply := executeAsync(blocked, "BRPOP", "never", "0")
+		waitBlocked()
+		blocked.Kill()
+		waitReply(t, reply)
+
+		execute(client, "RPUSH", "never", "kept")
+		if r := execute(client, "LLEN", "never"); r != ":1\r\n" {
+			t.Errorf("Expected the element to stay in the list, got %q", r)
+		}
+	})
+}
+
+// Test a client hanging up while blocked stops waiting, instead of taking the next element pushed
+func TestBlockedHangup(t *testing.T) {
+	srv, err := redis.NewServer(&redis.Config{Address: "localhost:7605"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := serveTCP(t, srv)
+
+	blocked, _ := dialCommand(t, address, "BLPOP jobs 0\r\n")
+	waitBlocked()
+	blocked.Close()
+	waitBlocked()
+
+	conn, reader := dialCommand(t, address, "RPUSH jobs kept\r\nLLEN jobs\r\n")
+	defer conn.Close()
+	reader.ReadString('\n')
+	if line, _ := reader.ReadString('\n'); line != ":1\r\n" {
+		t.Errorf("Expected the element to stay in the list, got %q", line)
+	}
+}
+
+func TestBlockingListOperations(t *testing.T) {
+	g, err := gedis.NewGedis(gedis.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create Gedis instance: %v", err)
+	}
+
+	// Test the element pushed while blocked is returned
+	t.Run("BLPop", func(t *testing.T) {
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			g.RPush("blocking:list", "value")
+		}()
+
+		key, value, err := g.BLPop(context.Background(), "blocking:list")
+		if err != nil {
+			t.Fatalf("BLPop failed: %v", err)
+		}
+		if key != "blocking:list" || value != "value" {
+			t.Errorf("Expected blocking:list and value, got %v and %v", key, value)
+		}
+	})
+
+	// Test cancelling the context stops the wait
+	t.Run("Cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(20 * time.Millisecond)
+			cancel()
+		}()
+
+		if _, _, err := g.BRPop(ctx, "blocking:empty"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+
+		ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if _, err := g.BLMove(ctx, "blocking:empty", "blocking:dest", true, true); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestRESP3Negotiation(t *testing.T) {
+	client := RESP.NewHandler(storage.NewDatabase()).NewClient()
+
+	execute(client, "HSET", "user", "name", "John", "age", "30")
+	execute(client, "ZADD", "board", "1.5", "alice", "3", "bob")
+
+	// Test the connection speaks RESP2 until HELLO is sent
+	t.Run("RESP2 Defaults", func(t *testing.T) {
+		if reply := execute(client, "GET", "missing"); reply != "$-1\r\n" {
+			t.Errorf("Expected nil bulk string, got %q", reply)
+		}
+		expected := "*4\r\n$3\r\nage\r\n$2\r\n30\r\n$4\r\nname\r\n$4\r\nJohn\r\n"
+		if reply := execute(client, "HGETALL", "user"); reply != expected {
+			t.Errorf("Expected %q, got %q", expected, reply)
+		}
+	})
+
+	// Test HELLO validation
+	t.Run("HELLO Errors", func(t *testing.T) {
+		if reply := execute(client, "HELLO", "4"); !strings.HasPrefix(reply, "-NOPROTO") {
+			t.Errorf("Expected NOPROTO error, got %q", reply)
+		}
+		if reply := execute(client, "HELLO", "3", "BOGUS"); reply[0] != '-' {
+			t.Errorf("Expected an error for an unknown option, got %q", reply)
+		}
+		if client.Protocol() != 2 {
+			t.Errorf("Failed HELLO must not switch protocol, got %d", client.Protocol())
+		}
+	})
+
+	// Test HELLO 3 switches the connection and names it
+	t.Run("HELLO 3", func(t *testing.T) {
+		reply := execute(client, "HELLO", "3", "SETNAME", "worker-1")
+		if !strings.HasPrefix(reply, "%7\r\n") || !strings.Contains(reply, "$5\r\nproto\r\n:3\r\n") {
+			t.Errorf("Expected a map reply announcing proto 3, got %q", reply)
+		}
+		if client.Protocol() != 3 || client.Name() != "worker-1" {
+			t.Errorf("Expected protocol 3 and name worker-1, got %d and %q", client.Protocol(), client.Name())
+		}
+	})
+
+	// Test the replies use the RESP3 types
+	t.Run("RESP3 Replies", func(t *testing.T) {
+		if reply := execute(client, "GET", "missing"); reply != "_\r\n" {
+			t.Errorf("Expected null, got %q", reply)
+		}
+		expected := "%2\r\n$3\r\nage\r\n$2\r\n30\r\n$4\r\nname\r\n$4\r\nJohn\r\n"
+		if reply := execute(client, "HGETALL", "user"); reply != expected {
+			t.Errorf("Expected %q, got %q", expected, reply)
+		}
+		expected = "*2\r\n*2\r\n$5\r\nalice\r\n,1.5\r\n*2\r\n$3\r\nbob\r\n,3\r\n"
+		if reply := execute(client, "ZRANGE", "board", "0", "-1", "WITHSCORES"); reply != expected {
+			t.Errorf("Expected %q, got %q", expected, reply)
+		}
+	})
+}
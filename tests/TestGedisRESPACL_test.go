@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/glob"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		str     string
+		match   bool
+	}{
+		{"*", "anything", true},
+		{"user:*", "user:1", true},
+		{"user:*", "session:1", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{"h\\*llo", "h*llo", true},
+		{"h\\*llo", "hello", false},
+		{"a/*", "a/b/c", true},
+		{"*llo", "hello", true},
+		{"h*l*o", "hello", true},
+		{"a*b*c", "abxbxc", true},
+		{"a*b", "abc", false},
+		{"*[0-9]", "key9", true},
+		{"*\\*", "a*", true},
+		{"**", "", true},
+		{"", "a", false},
+		// Stars are matched without backtracking over earlier ones, this doesn't take exponential time
+		{strings.Repeat("a*", 20) + "b", strings.Repeat("a", 100), false},
+	}
+
+	for _, tt := range tests {
+		if got := glob.Match(tt.pattern, tt.str); got != tt.match {
+			t.Errorf("Match(%q, %q) = %v, expected %v", tt.pattern, tt.str, got, tt.match)
+		}
+	}
+}
+
+func TestRESPACL(t *testing.T) {
+	handler := RESP.NewHandler(storage.NewDatabase())
+	admin := handler.NewClient()
+
+	// Test the default user can run everything
+	t.Run("Default User", func(t *testing.T) {
+		if reply := execute(admin, "ACL", "WHOAMI"); reply != "$7\r\ndefault\r\n" {
+			t.Errorf("Expected default, got %q", reply)
+		}
+		expected := "*1\r\n$34\r\nuser default on nopass ~* &* +@all\r\n"
+		if reply := execute(admin, "ACL", "LIST"); reply != expected {
+			t.Errorf("Expected %q, got %q", expected, reply)
+		}
+	})
+
+	// Test invalid rules are rejected without modifying the user
+	t.Run("SETUSER Errors", func(t *testing.T) {
+		if reply := execute(admin, "ACL", "SETUSER", "bob", "on", "+nosuchcommand"); !strings.HasPrefix(reply, "-ERR Error in ACL SETUSER modifier '+nosuchcommand'") {
+			t.Errorf("Expected an error, got %q", reply)
+		}
+		if reply := execute(admin, "ACL", "GETUSER", "bob"); reply != "$-1\r\n" {
+			t.Errorf("Expected bob not to exist, got %q", reply)
+		}
+		if reply := execute(admin, "ACL", "DELUSER", "default"); !strings.HasPrefix(reply, "-ERR") {
+			t.Errorf("Expected an error deleting the default user, got %q", reply)
+		}
+	})
+
+	// Test command and key permissions of a restricted user
+	t.Run("Permissions", func(t *testing.T) {
+		reply := execute(admin, "ACL", "SETUSER", "reader", "on", ">pass", "~cache:*", "%W~logs:*", "+@read", "+lpush", "-hgetall")
+		if reply != "+OK\r\n" {
+			t.Fatalf("Expected +OK, got %q", reply)
+		}
+
+		client := handler.NewClient()
+		if reply := execute(client, "AUTH", "reader", "pass"); reply != "+OK\r\n" {
+			t.Fatalf("Expected +OK, got %q", reply)
+		}
+		if reply := execute(client, "ACL", "WHOAMI"); !strings.HasPrefix(reply, "-NOPERM") {
+			t.Errorf("Expected NOPERM for ACL, got %q", reply)
+		}
+		if reply := execute(client, "GET", "cache:1"); reply != "$-1\r\n" {
+			t.Errorf("Expected GET on an allowed key to run, got %q", reply)
+		}
+		if reply := execute(client, "SET", "cache:1", "x"); reply != "-NOPERM User reader has no permissions to run the 'set' command\r\n" {
+			t.Errorf("Expected NOPERM for SET, got %q", reply)
+		}
+		if reply := execute(client, "HGETALL", "cache:1"); !strings.HasPrefix(reply, "-NOPERM") {
+			t.Errorf("Expected NOPERM for HGETALL, got %q", reply)
+		}
+		if reply := execute(client, "GET", "other"); reply != "-NOPERM No permissions to access a key\r\n" {
+			t.Errorf("Expected NOPERM for the key, got %q", reply)
+		}
+		if reply := execute(client, "LPUSH", "logs:1", "line"); reply != ":1\r\n" {
+			t.Errorf("Expected LPUSH on a writable key to run, got %q", reply)
+		}
+		if reply := execute(client, "LRANGE", "logs:1", "0", "-1"); !strings.HasPrefix(reply, "-NOPERM") {
+			t.Errorf("Expected NOPERM reading a write only key, got %q", reply)
+		}
+	})
+
+	// Test subcommand rules
+	t.Run("Subcommands", func(t *testing.T) {
+		execute(admin, "ACL", "SETUSER", "auditor", "on", "nopass", "+acl|whoami")
+		client := handler.NewClient()
+		execute(client, "AUTH", "auditor", "anything")
+		if reply := execute(client, "ACL", "WHOAMI"); reply != "$7\r\nauditor\r\n" {
+			t.Errorf("Expected auditor, got %q", reply)
+		}
+		if reply := execute(client, "ACL", "LIST"); !strings.Contains(reply, "'acl|list'") {
+			t.Errorf("Expected NOPERM for ACL LIST, got %q", reply)
+		}
+	})
+
+	// Test the commands acting on whole databases are left out of -@dangerous users
+	t.Run("Dangerous", func(t *testing.T) {
+		execute(admin, "ACL", "SETUSER", "app", "on", "nopass", "~app:*", "+@all", "-@dangerous")
+		client := handler.NewClient()
+		execute(client, "AUTH", "app", "x")
+		for _, command := range [][]string{{"FLUSHALL"}, {"FLUSHDB"}, {"SWAPDB", "0", "1"}} {
+			if reply := execute(client, command[0], command[1:]...); !strings.HasPrefix(reply, "-NOPERM") {
+				t.Errorf("Expected NOPERM for %s, got %q", command[0], reply)
+			}
+		}
+		if reply := execute(client, "SET", "app:1", "x"); reply != "+OK\r\n" {
+			t.Errorf("Expected SET on an allowed key to run, got %q", reply)
+		}
+		if reply := execute(client, "ACL", "WHOAMI"); reply != "$3\r\napp\r\n" {
+			t.Errorf("Expected ACL WHOAMI to run, got %q", reply)
+		}
+		if reply := execute(client, "ACL", "LIST"); !strings.HasPrefix(reply, "-NOPERM") {
+			t.Errorf("Expected NOPERM for ACL LIST, got %q", reply)
+		}
+		execute(admin, "ACL", "DELUSER", "app")
+	})
+
+	// Test channel permissions
+	t.Run("Channels", func(t *testing.T) {
+		execute(admin, "ACL", "SETUSER", "listener", "on", "nopass", "resetchannels", "&news:*", "+@pubsub")
+		client := handler.NewClient()
+		execute(client, "AUTH", "listener", "x")
+		if reply := execute(client, "PUBLISH", "alerts", "x"); reply != "-NOPERM No permissions to access a channel\r\n" {
+			t.Errorf("Expected NOPERM for the channel, got %q", reply)
+		}
+		if reply := execute(client, "SUBSCRIBE", "news:sport"); reply != "" {
+			t.Errorf("Expected the subscription to be pushed, got %q", reply)
+		}
+		if reply := execute(client, "PSUBSCRIBE", "news:*"); reply != "" {
+			t.Errorf("Expected the pattern subscription to be pushed, got %q", reply)
+		}
+		client.Close()
+		execute(admin, "ACL", "DELUSER", "listener")
+
+		// A pattern must be one of the user's patterns, not merely match it
+		execute(admin, "ACL", "SETUSER", "narrow", "on", "nopass", "resetchannels", "&a?", "+@pubsub")
+		client = handler.NewClient()
+		execute(client, "AUTH", "narrow", "x")
+		if reply := execute(client, "PSUBSCRIBE", "a*"); reply != "-NOPERM No permissions to access a channel\r\n" {
+			t.Errorf("Expected NOPERM for the pattern, got %q", reply)
+		}
+		if reply := execute(client, "PSUBSCRIBE", "a?"); reply != "" {
+			t.Errorf("Expected the pattern subscription to be pushed, got %q", reply)
+		}
+		client.Close()
+		execute(admin, "ACL", "DELUSER", "narrow")
+	})
+
+	// Test denied requests are recorded in the ACL LOG
+	t.Run("LOG", func(t *testing.T) {
+		client := handler.NewClient()
+		execute(client, "AUTH", "reader", "wrong")
+
+		reply := execute(admin, "ACL", "LOG", "1")
+		if !strings.HasPrefix(reply, "*1\r\n") || !strings.Contains(reply, "$4\r\nauth\r\n") {
+			t.Errorf("Expected the failed AUTH in the log, got %q", reply)
+		}
+		reply = execute(admin, "ACL", "LOG")
+		if !strings.Contains(reply, "$5\r\nother\r\n") || !strings.Contains(reply, "acl|list") {
+			t.Errorf("Expected the key and command denials in the log, got %q", reply)
+		}
+
+		if reply := execute(admin, "ACL", "LOG", "RESET"); reply != "+OK\r\n" {
+			t.Errorf("Expected +OK, got %q", reply)
+		}
+		if reply := execute(admin, "ACL", "LOG"); reply != "*0\r\n" {
+			t.Errorf("Expected an empty log, got %q", reply)
+		}
+	})
+
+	// Test disabled and deleted users can no longer authenticate
+	t.Run("DELUSER", func(t *testing.T) {
+		execute(admin, "ACL", "SETUSER", "reader", "off")
+		client := handler.NewClient()
+		if reply := execute(client, "AUTH", "reader", "pass"); !strings.HasPrefix(reply, "-WRONGPASS") {
+			t.Errorf("Expected WRONGPASS for a disabled user, got %q", reply)
+		}
+		if reply := execute(admin, "ACL", "DELUSER", "reader", "auditor", "missing"); reply != ":2\r\n" {
+			t.Errorf("Expected :2, got %q", reply)
+		}
+		if reply := execute(admin, "ACL", "USERS"); reply != "*1\r\n$7\r\ndefault\r\n" {
+			t.Errorf("Expected only the default user, got %q", reply)
+		}
+	})
+
+	// Test the categories reported by COMMAND INFO
+	t.Run("Categories", func(t *testing.T) {
+		reply := execute(admin, "COMMAND", "INFO", "GET")
+		if !strings.Contains(reply, "$5\r\n@read\r\n") || !strings.Contains(reply, "$7\r\n@string\r\n") {
+			t.Errorf("Expected @read and @string, got %q", reply)
+		}
+		reply = execute(admin, "ACL", "CAT", "hash")
+		if !strings.Contains(reply, "hgetall") || strings.Contains(reply, "lpush") {
+			t.Errorf("Expected the hash commands, got %q", reply)
+		}
+	})
+}
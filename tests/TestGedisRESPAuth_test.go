@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestRESPAuth(t *testing.T) {
+	handler := RESP.NewHandler(storage.NewDatabase())
+
+	// Test AUTH is refused while no password is configured
+	t.Run("No Password Configured", func(t *testing.T) {
+		client := handler.NewClient()
+		if reply := execute(client, "SET", "key", "value"); reply != "+OK\r\n" {
+			t.Errorf("Expected +OK without authentication, got %q", reply)
+		}
+		if reply := execute(client, "AUTH", "secret"); !strings.HasPrefix(reply, "-ERR AUTH <password> called without") {
+			t.Errorf("Expected an error, got %q", reply)
+		}
+	})
+
+	handler.SetPassword("secret")
+
+	// Test commands are rejected until the client authenticated
+	t.Run("NOAUTH", func(t *testing.T) {
+		client := handler.NewClient()
+		if reply := execute(client, "GET", "key"); reply != "-NOAUTH Authentication required.\r\n" {
+			t.Errorf("Expected NOAUTH, got %q", reply)
+		}
+		if reply := execute(client, "PING"); reply != "+PONG\r\n" {
+			t.Errorf("Expected PING to be allowed, got %q", reply)
+		}
+		if reply := execute(client, "HELLO", "3"); !strings.HasPrefix(reply, "-NOAUTH") {
+			t.Errorf("Expected NOAUTH for HELLO without AUTH, got %q", reply)
+		}
+	})
+
+	// Test AUTH with wrong and right credentials
+	t.Run("AUTH", func(t *testing.T) {
+		client := handler.NewClient()
+		if reply := execute(client, "AUTH", "wrong"); !strings.HasPrefix(reply, "-WRONGPASS") {
+			t.Errorf("Expected WRONGPASS, got %q", reply)
+		}
+		if reply := execute(client, "AUTH", "admin", "secret"); !strings.HasPrefix(reply, "-WRONGPASS") {
+			t.Errorf("Expected WRONGPASS for an unknown user, got %q", reply)
+		}
+		if reply := execute(client, "AUTH", "secret"); reply != "+OK\r\n" {
+			t.Errorf("Expected +OK, got %q", reply)
+		}
+		if reply := execute(client, "GET", "key"); reply != "$5\r\nvalue\r\n" {
+			t.Errorf("Expected the value after AUTH, got %q", reply)
+		}
+	})
+
+	// Test HELLO can authenticate and switch protocol at once
+	t.Run("HELLO AUTH", func(t *testing.T) {
+		client := handler.NewClient()
+		reply := execute(client, "HELLO", "3", "AUTH", "default", "secret", "SETNAME", "app")
+		if !strings.HasPrefix(reply, "%7\r\n") {
+			t.Errorf("Expected the HELLO map, got %q", reply)
+		}
+		if reply := execute(client, "GET", "key"); reply != "$5\r\nvalue\r\n" {
+			t.Errorf("Expected the value after HELLO AUTH, got %q", reply)
+		}
+	})
+	// Test a HELLO refused for any of its options doesn't authenticate the connection
+	t.Run("HELLO AUTH Invalid Name", func(t *testing.T) {
+		client := handler.NewClient()
+		reply := execute(client, "HELLO", "3", "AUTH", "default", "secret", "SETNAME", "bad name")
+		if !strings.HasPrefix(reply, "-ERR Client names cannot contain spaces") {
+			t.Errorf("Expected an invalid name error, got %q", reply)
+		}
+		if reply := execute(client, "GET", "key"); reply != "-NOAUTH Authentication required.\r\n" {
+			t.Errorf("Expected the connection to stay unauthenticated, got %q", reply)
+		}
+		if client.Protocol() != 2 {
+			t.Errorf("Expected the protocol to stay RESP2, got %d", client.Protocol())
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	redis "github.com/GedisCaching/Gedis/server"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestRESPClient(t *testing.T) {
+	handler := RESP.NewHandler(storage.NewDatabase())
+	client := handler.NewClient()
+	other := handler.NewClient()
+
+	// Test the connection id and name
+	t.Run("ID And Name", func(t *testing.T) {
+		if reply := execute(client, "CLIENT", "ID"); reply != ":"+strconv.FormatInt(client.ID(), 10)+"\r\n" {
+			t.Errorf("Expected the client id, got %q", reply)
+		}
+		if reply := execute(client, "CLIENT", "GETNAME"); reply != "$-1\r\n" {
+			t.Errorf("Expected no name, got %q", reply)
+		}
+		if reply := execute(client, "CLIENT", "SETNAME", "worker 1"); !strings.HasPrefix(reply, "-ERR Client names cannot contain spaces") {
+			t.Errorf("Expected an invalid name error, got %q", reply)
+		}
+		if reply := execute(client, "CLIENT", "SETNAME", "worker-1"); reply != "+OK\r\n" {
+			t.Errorf("Expected OK, got %q", reply)
+		}
+		if reply := execute(client, "CLIENT", "GETNAME"); reply != "$8\r\nworker-1\r\n" {
+			t.Errorf("Expected worker-1, got %q", reply)
+		}
+	})
+
+	// Test CLIENT INFO describes the connection
+	t.Run("INFO", func(t *testing.T) {
+		reply := execute(client, "CLIENT", "INFO")
+		pattern := `^\$\d+\r\nid=` + strconv.FormatInt(client.ID(), 10) +
+			` addr= laddr= name=worker-1 age=\d+ idle=0 flags=N db=0 sub=0 psub=0 oll=0 cmd=client\|info user=default resp=2\n\r\n$`
+		if !regexp.MustCompile(pattern).MatchString(reply) {
+			t.Errorf("Expected the client info, got %q", reply)
+		}
+	})
+
+	// Test CLIENT LIST shows every connection, with filters
+	t.Run("LIST", func(t *testing.T) {
+		execute(other, "SUBSCRIBE", "news")
+		nextOutput(t, other)
+
+		reply := execute(client, "CLIENT", "LIST")
+		if strings.Count(reply, "id=") != 2 || !strings.Contains(reply, "id="+strconv.FormatInt(other.ID(), 10)+" ") {
+			t.Errorf("Expected both clients, got %q", reply)
+		}
+
+		reply = execute(client, "CLIENT", "LIST", "TYPE", "pubsub")
+		if strings.Count(reply, "id=") != 1 || !strings.Contains(reply, "flags=P ") || !strings.Contains(reply, "sub=1 ") {
+			t.Errorf("Expected the subscriber, got %q", reply)
+		}
+
+		reply = execute(client, "CLIENT", "LIST", "ID", strconv.FormatInt(client.ID(), 10))
+		if strings.Count(reply, "id=") != 1 || !strings.Contains(reply, "name=worker-1 ") {
+			t.Errorf("Expected the client itself, got %q", reply)
+		}
+		if reply := execute(client, "CLIENT", "LIST", "TYPE", "replica"); reply != "-ERR Unknown client type 'replica'\r\n" {
+			t.Errorf("Expected an unknown type error, got %q", reply)
+		}
+	})
+
+	// Test CLIENT KILL by id and user
+	t.Run("KILL", func(t *testing.T) {
+		if reply := execute(client, "CLIENT", "KILL", "ID", strconv.FormatInt(other.ID(), 10)); reply != ":1\r\n" {
+			t.Errorf("Expected one client killed, got %q", reply)
+		}
+		if !other.Killed() {
+			t.Error("Expected the client to be killed")
+		}
+
+		// The killer itself is skipped unless SKIPME no
+		if reply := execute(client, "CLIENT", "KILL", "USER", "default"); reply != ":0\r\n" {
+			t.Errorf("Expected no client killed, got %q", reply)
+		}
+		if reply := execute(client, "CLIENT", "KILL", "USER", "nobody"); reply != "-ERR No such user 'nobody'\r\n" {
+			t.Errorf("Expected an unknown user error, got %q", reply)
+		}
+		if reply := execute(client, "CLIENT", "KILL", "10.0.0.1:1234"); reply != "-ERR No such client\r\n" {
+			t.Errorf("Expected no such client, got %q", reply)
+		}
+
+		// Closed connections leave the list
+		other.Close()
+		if reply := execute(client, "CLIENT", "LIST"); strings.Count(reply, "id=") != 1 {
+			t.Errorf("Expected only the client itself, got %q", reply)
+		}
+	})
+
+	// Test KILL and LIST are administrative, unlike the operations on the connection itself
+	t.Run("Permissions", func(t *testing.T) {
+		execute(client, "ACL", "SETUSER", "app", "on", "nopass", "+@all", "-@dangerous")
+		restricted := handler.NewClient()
+		execute(restricted, "AUTH", "app", "x")
+
+		for _, subcommand := range [][]string{{"KILL", "ID", "1"}, {"LIST"}} {
+			if reply := execute(restricted, "CLIENT", subcommand...); !strings.HasPrefix(reply, "-NOPERM") {
+				t.Errorf("Expected NOPERM for CLIENT %s, got %q", subcommand[0], reply)
+			}
+		}
+		if reply := execute(restricted, "CLIENT", "SETNAME", "worker"); reply != "+OK\r\n" {
+			t.Errorf("Expected CLIENT SETNAME to run, got %q", reply)
+		}
+		if reply := execute(restricted, "CLIENT", "INFO"); !strings.Contains(reply, "name=worker") {
+			t.Errorf("Expected CLIENT INFO to run, got %q", reply)
+		}
+		if reply := execute(client, "ACL", "CAT", "dangerous"); !strings.Contains(reply, "client|kill") {
+			t.Errorf("Expected client|kill in the dangerous category, got %q", reply)
+		}
+	})
+}
+
+func TestClientKillOverNetwork(t *testing.T) {
+	srv, err := redis.NewServer(&redis.Config{Address: "localhost:7501"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(l)
+	defer srv.Close(context.Background())
+
+	admin, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+	victim, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer victim.Close()
+
+	// The victim runs a command so it is registered before it is killed
+	victim.Write([]byte("PING\r\n"))
+	victim.SetReadDeadline(time.Now().Add(time.Second))
+	victimReader := bufio.NewReader(victim)
+	if line, _ := victimReader.ReadString('\n'); line != "+PONG\r\n" {
+		t.Fatalf("Expected PONG, got %q", line)
+	}
+
+	admin.Write([]byte("CLIENT KILL ADDR " + victim.LocalAddr().String() + "\r\n"))
+	admin.SetReadDeadline(time.Now().Add(time.Second))
+	if line, _ := bufio.NewReader(admin).ReadString('\n'); line != ":1\r\n" {
+		t.Errorf("Expected one client killed, got %q", line)
+	}
+
+	// The killed connection is closed by the server
+	if _, err := victimReader.ReadByte(); err != io.EOF {
+		t.Errorf("Expected the connection to be closed, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestRESPCommandTable(t *testing.T) {
+	client := RESP.NewHandler(storage.NewDatabase()).NewClient()
+
+	// Test the arity is checked before the handler runs
+	t.Run("Arity Checks", func(t *testing.T) {
+		expected := "-ERR wrong number of arguments for 'get' command\r\n"
+		if reply := execute(client, "GET"); reply != expected {
+			t.Errorf("Expected %q, got %q", expected, reply)
+		}
+		if reply := execute(client, "get", "a", "b"); reply != expected {
+			t.Errorf("Expected %q, got %q", expected, reply)
+		}
+		if reply := execute(client, "LPUSH", "list"); !strings.Contains(reply, "'lpush'") {
+			t.Errorf("Expected an arity error for LPUSH, got %q", reply)
+		}
+	})
+
+	// Test the key positions declared in the table
+	t.Run("Key Positions", func(t *testing.T) {
+		keys := RESP.LookupCommand("del").Keys([]string{"a", "b", "c"})
+		if !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+			t.Errorf("Expected [a b c], got %v", keys)
+		}
+		keys = RESP.LookupCommand("SET").Keys([]string{"a", "value", "EX", "10"})
+		if !reflect.DeepEqual(keys, []string{"a"}) {
+			t.Errorf("Expected [a], got %v", keys)
+		}
+		if keys := RESP.LookupCommand("PING").Keys(nil); keys != nil {
+			t.Errorf("Expected no keys, got %v", keys)
+		}
+	})
+
+	// Test COMMAND COUNT and COMMAND LIST
+	t.Run("COMMAND COUNT", func(t *testing.T) {
+		count := len(RESP.Commands())
+		if reply := execute(client, "COMMAND", "COUNT"); reply != ":"+strconv.Itoa(count)+"\r\n" {
+			t.Errorf("Expected :%d, got %q", count, reply)
+		}
+		if reply := execute(client, "COMMAND", "LIST"); !strings.HasPrefix(reply, "*"+strconv.Itoa(count)+"\r\n") {
+			t.Errorf("Expected %d command names, got %q", count, reply)
+		}
+	})
+
+	// Test COMMAND INFO
+	t.Run("COMMAND INFO", func(t *testing.T) {
+		reply := execute(client, "COMMAND", "INFO", "get", "nope")
+		expected := "*2\r\n*10\r\n$3\r\nget\r\n:2\r\n*2\r\n$8\r\nreadonly\r\n$4\r\nfast\r\n:1\r\n:1\r\n:1\r\n"
+		if !strings.HasPrefix(reply, expected) {
+			t.Errorf("Expected reply starting with %q, got %q", expected, reply)
+		}
+		if !strings.HasSuffix(reply, "$-1\r\n") {
+			t.Errorf("Expected a nil entry for an unknown command, got %q", reply)
+		}
+	})
+
+	// Test COMMAND DOCS
+	t.Run("COMMAND DOCS", func(t *testing.T) {
+		reply := execute(client, "COMMAND", "DOCS", "hset")
+		if !strings.HasPrefix(reply, "*2\r\n$4\r\nhset\r\n") || !strings.Contains(reply, RESP.LookupCommand("HSET").Summary) {
+			t.Errorf("Expected the HSET documentation, got %q", reply)
+		}
+		if reply := execute(client, "COMMAND", "BOGUS"); reply[0] != '-' {
+			t.Errorf("Expected an error for an unknown subcommand, got %q", reply)
+		}
+	})
+}
+
+func TestRESPHelp(t *testing.T) {
+	client := RESP.NewHandler(storage.NewDatabase()).NewClient()
+
+	if reply := execute(client, "HELP", "set"); reply != "$"+strconv.Itoa(len(RESP.HelpSET))+"\r\n"+RESP.HelpSET+"\r\n" {
+		t.Errorf("Expected the SET help text, got %q", reply)
+	}
+	if reply := execute(client, "HELP", "LPUSH"); reply != "$46\r\nLPUSH: Prepends one or more elements to a list\r\n" {
+		t.Errorf("Expected the LPUSH summary, got %q", reply)
+	}
+	if reply := execute(client, "HELP", "nosuch"); !strings.HasPrefix(reply, "-ERR unknown command") {
+		t.Errorf("Expected an error, got %q", reply)
+	}
+	if reply := execute(client, "HELP"); !strings.Contains(reply, "WATCH - Monitors changes to keys") {
+		t.Errorf("Expected the summary of every command, got %q", reply)
+	}
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/gedis"
+	redis "github.com/GedisCaching/Gedis/server"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestRESPCommands(t *testing.T) {
+	const address = "localhost:7101"
+
+	// The network clients and the embedded API resolve to the same server
+	g, err := gedis.NewGedis(gedis.Config{Address: address})
+	if err != nil {
+		t.Fatalf("Failed to create Gedis instance: %v", err)
+	}
+	srv, err := redis.NewServer(&redis.Config{Address: address})
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	client := RESP.NewHandler(srv.GetDB()).NewClient()
+
+	// Test keys written through the Go API are visible over the protocol
+	t.Run("Shared Datastore", func(t *testing.T) {
+		g.Set("shared", "from-api")
+		if reply := RESP.ParseCommand(client, "GET", []string{"shared"}); reply != "$8\r\nfrom-api\r\n" {
+			t.Errorf("Expected from-api, got %q", reply)
+		}
+
+		RESP.ParseCommand(client, "SET", []string{"network", "from-tcp"})
+		val, exists := g.Get("network")
+		if !exists || val != "from-tcp" {
+			t.Errorf("Expected from-tcp, got %v", val)
+		}
+	})
+
+	// Test the storage type checks apply to the network path
+	t.Run("Type Checks", func(t *testing.T) {
+		g.RPush("list", "a", "b")
+		reply := RESP.ParseCommand(client, "GET", []string{"list"})
+		if reply[0] != '-' {
+			t.Errorf("Expected an error for GET on a list, got %q", reply)
+		}
+	})
+
+	// Test expiry set over the protocol is honoured by the Go API
+	t.Run("Expiry", func(t *testing.T) {
+		RESP.ParseCommand(client, "SET", []string{"temp", "value", "EX", "100"})
+		ttl, exists := g.TTL("temp")
+		if !exists || ttl <= 0 {
+			t.Errorf("Expected positive TTL, got %v", ttl)
+		}
+
+		// SET without EX discards the previous expiry
+		RESP.ParseCommand(client, "SET", []string{"temp", "value"})
+		ttl, _ = g.TTL("temp")
+		if ttl != 0 {
+			t.Errorf("Expected no TTL after overwrite, got %v", ttl)
+		}
+	})
+}
+
+func TestRESPReplyTypes(t *testing.T) {
+	client := RESP.NewHandler(storage.NewDatabase()).NewClient()
+
+	cases := []struct {
+		name     string
+		command  []string
+		expected string
+	}{
+		{"PING", []string{"PING"}, "+PONG\r\n"},
+		{"PING message", []string{"PING", "hello"}, "$5\r\nhello\r\n"},
+		{"SET", []string{"SET", "a", "1"}, "+OK\r\n"},
+		{"GET existing", []string{"GET", "a"}, "$1\r\n1\r\n"},
+		{"GET missing", []string{"GET", "missing"}, "$-1\r\n"},
+		{"EXISTS several", []string{"EXISTS", "a", "missing", "a"}, ":2\r\n"},
+		{"TTL no expiry", []string{"TTL", "a"}, ":-1\r\n"},
+		{"TTL missing", []string{"TTL", "missing"}, ":-2\r\n"},
+		{"EXPIRE existing", []string{"EXPIRE", "a", "100"}, ":1\r\n"},
+		{"EXPIRE missing", []string{"EXPIRE", "missing", "100"}, ":0\r\n"},
+		{"TTL with expiry", []string{"TTL", "a"}, ":100\r\n"},
+		{"RENAME missing", []string{"RENAME", "missing", "b"}, "-ERR no such key\r\n"},
+		{"GETDEL", []string{"GETDEL", "a"}, "$1\r\n1\r\n"},
+		{"GETDEL missing", []string{"GETDEL", "a"}, "$-1\r\n"},
+		{"RPUSH", []string{"RPUSH", "list", "x"}, ":1\r\n"},
+		{"GET wrong type", []string{"GET", "list"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"DEL several", []string{"DEL", "list", "missing"}, ":1\r\n"},
+		{"ZADD", []string{"ZADD", "board", "1", "alice"}, ":1\r\n"},
+		{"SET over sorted set", []string{"SET", "board", "top"}, "+OK\r\n"},
+		{"ZRANK replaced", []string{"ZRANK", "board", "alice"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"GET replaced", []string{"GET", "board"}, "$3\r\ntop\r\n"},
+		{"ZADD again", []string{"ZADD", "board", "1", "alice"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"DEL replaced", []string{"DEL", "board"}, ":1\r\n"},
+		{"EXISTS replaced", []string{"EXISTS", "board"}, ":0\r\n"},
+		{"ZADD expiring", []string{"ZADD", "board", "1", "alice"}, ":1\r\n"},
+		{"SET EX over sorted set", []string{"SET", "board", "top", "EX", "100"}, "+OK\r\n"},
+		{"ZRANGE replaced", []string{"ZRANGE", "board", "0", "-1"}, "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n"},
+		{"SET EX overflow", []string{"SET", "big", "v", "EX", "9223372037"}, "-ERR invalid expire time in 'set' command\r\n"},
+		{"SET PX overflow", []string{"SET", "big", "v", "PX", "9223372036855"}, "-ERR invalid expire time in 'set' command\r\n"},
+		{"SET EX largest", []string{"SET", "big", "v", "EX", "9223372036"}, "+OK\r\n"},
+		{"GET largest expiry", []string{"GET", "big"}, "$1\r\nv\r\n"},
+		{"EXPIRE overflow", []string{"EXPIRE", "big", "9223372037"}, "-ERR invalid expire time in 'expire' command\r\n"},
+		{"Unknown command", []string{"NOPE"}, "-ERR unknown command 'NOPE'\r\n"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reply := execute(client, tc.command[0], tc.command[1:]...)
+			if reply != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, reply)
+			}
+		})
+	}
+}
+
+// execute runs a command for the client and returns the raw reply
+func execute(client *RESP.Client, command string, args ...string) string {
+	return RESP.ParseCommand(client, command, args)
+}
@@ -0,0 +1,315 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	redis "github.com/GedisCaching/Gedis/server"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// Test the parameters, comments and quoted values of a configuration file
+	t.Run("Load", func(t *testing.T) {
+		path := filepath.Join(dir, "gedis.conf")
+		writeFile(t, path, []byte("# Network\naddress localhost:7700\n\n  timeout 1m30s\nrequirepass \"secret word\"\n"+
+			"MAXMEMORY 2mb\nmaxmemory-policy volatile-ttl\nnotify-keyspace-events KEA\ndisable-tcp no\n"))
+
+		config := &redis.Config{}
+		if err := redis.LoadConfigFile(path, config); err != nil {
+			t.Fatalf("LoadConfigFile failed: %v", err)
+		}
+		if config.Address != "localhost:7700" || config.Timeout != 90*time.Second || config.Password != "secret word" {
+			t.Errorf("Unexpected configuration %+v", config)
+		}
+		if config.MaxMemory != 2<<20 || config.MaxMemoryPolicy != storage.VolatileTTL || config.NotifyKeyspaceEvents != "AKE" {
+			t.Errorf("Unexpected configuration %+v", config)
+		}
+		if config.ConfigFile != path {
+			t.Errorf("Expected the file to be recorded, got %q", config.ConfigFile)
+		}
+	})
+
+	// Test errors report the line they were found on
+	t.Run("Errors", func(t *testing.T) {
+		for content, want := range map[string]string{
+			"address localhost:7700\nport 7000\n": ":2: unknown parameter 'port'",
+			"maxmemory lots\n":                    ":1: invalid value for 'maxmemory'",
+			"databases 0\n":                       ":1: invalid value for 'databases'",
+			"address a b\n":                       ":1: wrong number of arguments for 'address'",
+			"requirepass \"secret\n":              ":1: unbalanced quotes",
+		} {
+			path := filepath.Join(dir, "invalid.conf")
+			writeFile(t, path, []byte(content))
+			if err := redis.LoadConfigFile(path, &redis.Config{}); err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("Expected an error containing %q, got %v", want, err)
+			}
+		}
+	})
+}
+
+func TestRESPConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gedis.conf")
+	writeFile(t, path, []byte("# Managed by the tests\naddress localhost:7701\ntimeout 0\n"))
+	config := &redis.Config{}
+	if err := redis.LoadConfigFile(path, config); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := redis.NewServer(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := srv.GetHandler().NewClient()
+
+	// Test reading parameters by pattern, with their defaults
+	t.Run("GET", func(t *testing.T) {
+		want := "*4\r\n$9\r\ndatabases\r\n$2\r\n16\r\n$16\r\nshutdown-timeout\r\n$2\r\n10\r\n"
+		if reply := execute(client, "CONFIG", "GET", "databases", "SHUTDOWN-*"); reply != want {
+			t.Errorf("Expected %q, got %q", want, reply)
+		}
+		if reply := execute(client, "CONFIG", "GET", "nothing*"); reply != "*0\r\n" {
+			t.Errorf("Expected no parameter, got %q", reply)
+		}
+	})
+
+	// Test changing parameters, all of them or none
+	t.Run("SET", func(t *testing.T) {
+		if reply := execute(client, "CONFIG", "SET", "maxmemory", "1kb", "notify-keyspace-events", "Kg"); reply != "+OK\r\n" {
+			t.Errorf("Expected OK, got %q", reply)
+		}
+		if reply := execute(client, "CONFIG", "GET", "maxmemory"); reply != "*2\r\n$9\r\nmaxmemory\r\n$4\r\n1024\r\n" {
+			t.Errorf("Expected the new limit, got %q", reply)
+		}
+		if settings := srv.Settings(); settings.NotifyKeyspaceEvents != "gK" || srv.GetHandler().KeyspaceEvents() != "gK" {
+			t.Errorf("Expected the keyspace events to be applied, got %q", settings.NotifyKeyspaceEvents)
+		}
+
+		reply := execute(client, "CONFIG", "SET", "timeout", "5", "maxmemory-policy", "lru")
+		if !strings.HasPrefix(reply, "-ERR CONFIG SET failed - invalid value for 'maxmemory-policy'") {
+			t.Errorf("Expected an invalid value error, got %q", reply)
+		}
+		if settings := srv.Settings(); settings.Timeout != 0 {
+			t.Errorf("Expected the timeout to be left unchanged, got %v", settings.Timeout)
+		}
+
+		if reply := execute(client, "CONFIG", "SET", "databases", "4"); reply != "-ERR CONFIG SET failed - can't set immutable config 'databases'\r\n" {
+			t.Errorf("Expected an immutable config error, got %q", reply)
+		}
+		if reply := execute(client, "CONFIG", "SET", "port", "7000"); reply != "-ERR CONFIG SET failed - unknown parameter 'port'\r\n" {
+			t.Errorf("Expected an unknown parameter error, got %q", reply)
+		}
+		if reply := execute(client, "CONFIG", "SET", "timeout", "1", "TIMEOUT", "2"); reply != "-ERR CONFIG SET failed - duplicate parameter 'timeout'\r\n" {
+			t.Errorf("Expected a duplicate parameter error, got %q", reply)
+		}
+		if reply := execute(client, "CONFIG", "SET", "timeout"); reply != "-ERR wrong number of arguments for 'config|set' command\r\n" {
+			t.Errorf("Expected an arity error, got %q", reply)
+		}
+	})
+
+	// Test CONFIG is refused in a transaction, whose EXEC holds the locks a new limit needs to evict keys
+	t.Run("MULTI", func(t *testing.T) {
+		tx := srv.GetHandler().NewClient()
+		execute(tx, "MULTI")
+		if reply := execute(tx, "CONFIG", "SET", "maxmemory", "1mb"); reply != "-ERR Command not allowed inside a transaction\r\n" {
+			t.Errorf("Expected CONFIG to be refused, got %q", reply)
+		}
+		if reply := waitReply(t, executeAsync(tx, "EXEC")); !strings.HasPrefix(reply, "-EXECABORT") {
+			t.Errorf("Expected the transaction to be aborted, got %q", reply)
+		}
+	})
+
+	// Test a new password is required from the next connections
+	t.Run("requirepass", func(t *testing.T) {
+		execute(client, "CONFIG", "SET", "requirepass", "secret")
+		defer execute(client, "CONFIG", "SET", "requirepass", "")
+
+		other := srv.GetHandler().NewClient()
+		if reply := execute(other, "GET", "key"); !strings.HasPrefix(reply, "-NOAUTH") {
+			t.Errorf("Expected authentication to be required, got %q", reply)
+		}
+		if reply := execute(other, "AUTH", "secret"); reply != "+OK\r\n" {
+			t.Errorf("Expected OK, got %q", reply)
+		}
+	})
+
+	// Test the changes are saved to the configuration file, keeping its comments
+	t.Run("REWRITE", func(t *testing.T) {
+		if reply := execute(client, "CONFIG", "REWRITE"); reply != "+OK\r\n" {
+			t.Fatalf("Expected OK, got %q", reply)
+		}
+		content, _ := os.ReadFile(path)
+		if !strings.HasPrefix(string(content), "# Managed by the tests\naddress localhost:7701\ntimeout 0\n") ||
+			!strings.Contains(string(content), "\nmaxmemory 1024\n") || !strings.Contains(string(content), "\nnotify-keyspace-events gK\n") {
+			t.Errorf("Unexpected configuration file %q", content)
+		}
+
+		reloaded := &redis.Config{}
+		if err := redis.LoadConfigFile(path, reloaded); err != nil {
+			t.Fatal(err)
+		}
+		if reloaded.MaxMemory != 1024 || reloaded.NotifyKeyspaceEvents != "gK" || reloaded.Address != "localhost:7701" {
+			t.Errorf("Expected the changes to be read back, got %+v", reloaded)
+		}
+	})
+
+	// Test CONFIG needs a server, and REWRITE a configuration file
+	t.Run("Unavailable", func(t *testing.T) {
+		standalone := RESP.NewHandler(storage.NewDatabase()).NewClient()
+		if reply := execute(standalone, "CONFIG", "GET", "*"); reply != "-ERR CONFIG is not available without a server\r\n" {
+			t.Errorf("Expected CONFIG to be unavailable, got %q", reply)
+		}
+
+		srv, err := redis.NewServer(&redis.Config{Address: "localhost:7702"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		reply := execute(srv.GetHandler().NewClient(), "CONFIG", "REWRITE")
+		if reply != "-ERR The server is running without a config file\r\n" {
+			t.Errorf("Expected a missing config file error, got %q", reply)
+		}
+	})
+}
+
+func TestMaxMemory(t *testing.T) {
+	// Test commands adding data are refused once the limit is reached, unlike those removing it
+	t.Run("noeviction", func(t *testing.T) {
+		db := storage.NewDatabase()
+		client := RESP.NewHandler(db).NewClient()
+		db.SetMaxMemory(1000, storage.NoEviction)
+
+		// The limit is checked before a command runs, so the one crossing it succeeds
+		for i := 0; db.UsedMemory() <= 1000; i++ {
+			execute(client, "SET", "key"+strconv.Itoa(i), "value")
+		}
+		reply := execute(client, "RPUSH", "list", "a")
+		if reply != "-OOM command not allowed when used memory > 'maxmemory'.\r\n" {
+			t.Fatalf("Expected an OOM error, got %q", reply)
+		}
+		if reply := execute(client, "GET", "key0"); reply != "$5\r\nvalue\r\n" {
+			t.Errorf("Expected reads to be allowed, got %q", reply)
+		}
+		if reply := execute(client, "DEL", "key0"); reply != ":1\r\n" {
+			t.Errorf("Expected deletes to be allowed, got %q", reply)
+		}
+	})
+
+	// Test random keys are evicted to make room
+	t.Run("allkeys-random", func(t *testing.T) {
+		db := storage.NewDatabase()
+		client := RESP.NewHandler(db).NewClient()
+		db.SetMaxMemory(2000, storage.AllKeysRandom)
+
+		for i := 0; i < 100; i++ {
+			if reply := execute(client, "SET", "key:"+strings.Repeat("x", i), "value"); reply != "+OK\r\n" {
+				t.Fatalf("Expected OK, got %q", reply)
+			}
+		}
+		if len(db.Keys()) >= 100 {
+			t.Error("Expected keys to be evicted")
+		}
+		// The limit is checked before a command runs, so the last one may exceed it
+		if used := db.UsedMemory(); used > 2000+200 {
+			t.Errorf("Expected the memory used to stay close to the limit, got %d", used)
+		}
+	})
+
+	// Test the keys closest to their expiry are evicted first, keys without expiry are kept
+	t.Run("volatile-ttl", func(t *testing.T) {
+		db := storage.NewDatabase()
+		db.Set("persistent", strings.Repeat("x", 500))
+		db.SetWithExpiry("soon", "value", time.Minute)
+		db.SetWithExpiry("later", "value", time.Hour)
+
+		db.SetMaxMemory(db.UsedMemory()-1, storage.VolatileTTL)
+		if !db.FreeMemory() {
+			t.Fatal("Expected the limit to be met")
+		}
+		if _, exists := db.Get("soon"); exists {
+			t.Error("Expected the key expiring first to be evicted")
+		}
+		if _, exists := db.Get("later"); !exists {
+			t.Error("Expected the other key to be kept")
+		}
+
+		db.SetMaxMemory(1, storage.VolatileTTL)
+		if db.FreeMemory() {
+			t.Error("Expected the keys without expiry to be kept")
+		}
+		if _, exists := db.Get("persistent"); !exists {
+			t.Error("Expected the persistent key to be kept")
+		}
+	})
+
+	// Test the size tracked element by element matches the one of the same keys written at once
+	t.Run("accounting", func(t *testing.T) {
+		edited := storage.NewDatabase()
+		client := RESP.NewHandler(edited).NewClient()
+		execute(client, "RPUSH", "list", "a", "b", "c")
+		execute(client, "LPOP", "list")
+		execute(client, "LPUSH", "list", "longer")
+		execute(client, "LSET", "list", "1", "bb")
+		execute(client, "HSET", "hash", "f1", "v1", "f2", "v2")
+		execute(client, "HSET", "hash", "f1", "value")
+		execute(client, "HDEL", "hash", "f2")
+		execute(client, "ZADD", "board", "1", "alice", "2", "bob")
+		execute(client, "ZADD", "board", "3", "alice")
+		execute(client, "EXPIRE", "board", "100")
+		execute(client, "SET", "name", "gedis")
+		execute(client, "RENAME", "name", "title")
+
+		written := storage.NewDatabase()
+		client = RESP.NewHandler(written).NewClient()
+		execute(client, "RPUSH", "list", "longer", "bb", "c")
+		execute(client, "HSET", "hash", "f1", "value")
+		execute(client, "ZADD", "board", "3", "alice", "2", "bob")
+		execute(client, "EXPIRE", "board", "100")
+		execute(client, "SET", "title", "gedis")
+
+		if edited.UsedMemory() != written.UsedMemory() {
+			t.Errorf("Expected %d bytes used, got %d", written.UsedMemory(), edited.UsedMemory())
+		}
+
+		client = RESP.NewHandler(edited).NewClient()
+		execute(client, "DEL", "list", "hash", "board", "title")
+		if used := edited.UsedMemory(); used != 0 {
+			t.Errorf("Expected no memory used once every key is deleted, got %d", used)
+		}
+	})
+}
+
+func TestIdleTimeout(t *testing.T) {
+	srv, err := redis.NewServer(&redis.Config{Address: "localhost:7703", Timeout: 100 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := serveTCP(t, srv)
+	defer srv.Close(context.Background())
+
+	idle, idleReader := dialCommand(t, address, "PING\r\n")
+	defer idle.Close()
+	subscriber, subscriberReader := dialCommand(t, address, "SUBSCRIBE news\r\n")
+	defer subscriber.Close()
+	idleReader.ReadString('\n')
+	for i := 0; i < 6; i++ {
+		subscriberReader.ReadString('\n')
+	}
+
+	// The idle connection is closed, the subscriber is kept
+	if _, err := idleReader.ReadByte(); err != io.EOF {
+		t.Errorf("Expected the idle connection to be closed, got %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	subscriber.Write([]byte("PING\r\n"))
+	if line, _ := subscriberReader.ReadString('\n'); line != "*2\r\n" {
+		t.Errorf("Expected the subscriber to be served, got %q", line)
+	}
+}
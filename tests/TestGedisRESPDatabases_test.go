@@ -0,0 +1,268 @@
+package tests
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/gedis"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestRESPDatabases(t *testing.T) {
+	handler := RESP.NewHandlerWithDatabases(storage.NewDatabases(4))
+	client := handler.NewClient()
+	other := handler.NewClient()
+
+	// Test each connection selects its own database
+	t.Run("SELECT", func(t *testing.T) {
+		execute(client, "SET", "env", "production")
+		if reply := execute(client, "SELECT", "1"); reply != "+OK\r\n" {
+			t.Fatalf("Expected +OK, got %q", reply)
+		}
+		if reply := execute(client, "GET", "env"); reply != "$-1\r\n" {
+			t.Errorf("Expected database 1 to be empty, got %q", reply)
+		}
+		execute(client, "SET", "env", "staging")
+		if reply := execute(other, "GET", "env"); reply != "$10\r\nproduction\r\n" {
+			t.Errorf("Expected the other connection to stay on database 0, got %q", reply)
+		}
+		if info := execute(client, "CLIENT", "INFO"); !strings.Contains(info, " db=1 ") {
+			t.Errorf("Expected CLIENT INFO to show db=1, got %q", info)
+		}
+
+		if reply := execute(client, "SELECT", "4"); reply != "-ERR DB index is out of range\r\n" {
+			t.Errorf("Expected an out of range error, got %q", reply)
+		}
+		if reply := execute(client, "SELECT", "one"); reply != "-ERR value is not an integer or out of range\r\n" {
+			t.Errorf("Expected an integer error, got %q", reply)
+		}
+	})
+
+	// Test MOVE keeps the expiry and refuses to overwrite a key
+	t.Run("MOVE", func(t *testing.T) {
+		execute(other, "SET", "session", "abc", "EX", "100")
+		if reply := execute(other, "MOVE", "session", "2"); reply != ":1\r\n" {
+			t.Fatalf("Expected :1, got %q", reply)
+		}
+		if reply := execute(other, "EXISTS", "session"); reply != ":0\r\n" {
+			t.Errorf("Expected the key to leave database 0, got %q", reply)
+		}
+		execute(client, "SELECT", "2")
+		if reply := execute(client, "TTL", "session"); reply == ":-1\r\n" || reply == ":-2\r\n" {
+			t.Errorf("Expected the expiry to move with the key, got %q", reply)
+		}
+
+		if reply := execute(other, "MOVE", "env", "1"); reply != ":0\r\n" {
+			t.Errorf("Expected :0 when the destination holds the key, got %q", reply)
+		}
+		if reply := execute(other, "MOVE", "missing", "1"); reply != ":0\r\n" {
+			t.Errorf("Expected :0 for a missing key, got %q", reply)
+		}
+		if reply := execute(other, "MOVE", "env", "0"); reply != "-ERR source and destination objects are the same\r\n" {
+			t.Errorf("Expected a same database error, got %q", reply)
+		}
+		if reply := execute(other, "MOVE", "env", "9"); reply != "-ERR DB index is out of range\r\n" {
+			t.Errorf("Expected an out of range error, got %q", reply)
+		}
+	})
+
+	// Test SWAPDB switches the keys seen by the connections of both databases
+	t.Run("SWAPDB", func(t *testing.T) {
+		execute(client, "SELECT", "1")
+		if reply := execute(client, "SWAPDB", "0", "1"); reply != "+OK\r\n" {
+			t.Fatalf("Expected +OK, got %q", reply)
+		}
+		if reply := execute(other, "GET", "env"); reply != "$7\r\nstaging\r\n" {
+			t.Errorf("Expected database 0 to hold the staging keys, got %q", reply)
+		}
+		if reply := execute(client, "GET", "env"); reply != "$10\r\nproduction\r\n" {
+			t.Errorf("Expected database 1 to hold the production keys, got %q", reply)
+		}
+
+		if reply := execute(client, "SWAPDB", "0", "4"); reply != "-ERR invalid second DB index\r\n" {
+			t.Errorf("Expected an invalid index error, got %q", reply)
+		}
+		if reply := execute(client, "SWAPDB", "x", "1"); reply != "-ERR invalid first DB index\r\n" {
+			t.Errorf("Expected an invalid index error, got %q", reply)
+		}
+		execute(client, "SWAPDB", "1", "0")
+	})
+
+	// Test a swap aborts the transactions watching the swapped keys
+	// and serves the clients blocked on a list of the new keys
+	t.Run("SWAPDB Watch And Block", func(t *testing.T) {
+		execute(client, "SELECT", "3")
+		execute(client, "RPUSH", "jobs", "job:1")
+
+		execute(other, "WATCH", "env")
+		execute(other, "MULTI")
+		execute(other, "SET", "env", "changed")
+
+		blocked := handler.NewClient()
+		reply := executeAsync(blocked, "BLPOP", "jobs", "0")
+		waitBlocked()
+
+		execute(client, "SWAPDB", "0", "3")
+		if r := waitReply(t, reply); r != "*2\r\n$4\r\njobs\r\n$5\r\njob:1\r\n" {
+			t.Errorf("Expected the blocked client to pop job:1, got %q", r)
+		}
+		if r := execute(other, "EXEC"); r != "*-1\r\n" {
+			t.Errorf("Expected the watched transaction to abort, got %q", r)
+		}
+		execute(client, "SWAPDB", "0", "3")
+	})
+
+	// Test each WATCH watches its keys in the database selected when it runs
+	t.Run("WATCH Per Database", func(t *testing.T) {
+		execute(other, "WATCH", "env")
+		execute(other, "SELECT", "1")
+		execute(other, "WATCH", "counter")
+		execute(other, "SELECT", "0")
+
+		execute(client, "SELECT", "1")
+		execute(client, "SET", "counter", "1")
+
+		execute(other, "MULTI")
+		execute(other, "GET", "env")
+		if reply := execute(other, "EXEC"); reply != "*-1\r\n" {
+			t.Errorf("Expected the write to counter in database 1 to abort, got %q", reply)
+		}
+	})
+
+	// Test a transaction runs against the database selected inside it
+	t.Run("Transactions", func(t *testing.T) {
+		execute(other, "MULTI")
+		execute(other, "SELECT", "2")
+		execute(other, "SET", "queued", "yes")
+		execute(other, "MOVE", "queued", "3")
+		if reply := execute(other, "EXEC"); reply != "*3\r\n+OK\r\n+OK\r\n:1\r\n" {
+			t.Errorf("Expected +OK, +OK and :1, got %q", reply)
+		}
+		if reply := execute(other, "SELECT", "3"); reply != "+OK\r\n" {
+			t.Fatalf("Expected +OK, got %q", reply)
+		}
+		if reply := execute(other, "GET", "queued"); reply != "$3\r\nyes\r\n" {
+			t.Errorf("Expected the key to be moved to database 3, got %q", reply)
+		}
+		execute(other, "SELECT", "0")
+	})
+
+	// Test FLUSHDB only empties the selected database and FLUSHALL every one
+	t.Run("FLUSHDB FLUSHALL", func(t *testing.T) {
+		execute(client, "SELECT", "1")
+		if reply := execute(client, "FLUSHDB", "ASYNC"); reply != "+OK\r\n" {
+			t.Fatalf("Expected +OK, got %q", reply)
+		}
+		if reply := execute(client, "EXISTS", "env"); reply != ":0\r\n" {
+			t.Errorf("Expected database 1 to be empty, got %q", reply)
+		}
+		if reply := execute(other, "EXISTS", "env"); reply != ":1\r\n" {
+			t.Errorf("Expected database 0 to keep its keys, got %q", reply)
+		}
+
+		if reply := execute(client, "FLUSHALL", "LATER"); reply != "-ERR syntax error\r\n" {
+			t.Errorf("Expected a syntax error, got %q", reply)
+		}
+		if reply := execute(client, "FLUSHALL", "SYNC"); reply != "+OK\r\n" {
+			t.Fatalf("Expected +OK, got %q", reply)
+		}
+		for i := 0; i < 4; i++ {
+			if keys := handler.Databases().DB(i).Keys(); len(keys) != 0 {
+				t.Errorf("Expected database %d to be empty, got %v", i, keys)
+			}
+		}
+		if used := handler.Databases().UsedMemory(); used != 0 {
+			t.Errorf("Expected no memory used after FLUSHALL, got %d", used)
+		}
+	})
+}
+
+func TestRESPDatabaseNotifications(t *testing.T) {
+	handler := RESP.NewHandlerWithDatabases(storage.NewDatabases(2))
+	subscriber := handler.NewClient()
+	client := handler.NewClient()
+	handler.SetKeyspaceEvents("Kg")
+
+	execute(subscriber, "PSUBSCRIBE", "__keyspace@*")
+	nextOutput(t, subscriber)
+
+	// Test the channels carry the index of the database the event happened in
+	execute(client, "SET", "key", "value")
+	execute(client, "MOVE", "key", "1")
+	for _, channel := range []string{"__keyspace@0__:key", "__keyspace@1__:key"} {
+		if frame := nextOutput(t, subscriber); !strings.Contains(frame, channel) {
+			t.Errorf("Expected a notification on %s, got %q", channel, frame)
+		}
+	}
+}
+
+func TestDatabasesSaveLoad(t *testing.T) {
+	dbs := storage.NewDatabases(3)
+	dbs.DB(0).Set("config", "blue")
+	dbs.DB(2).SetWithExpiry("cache", "green", time.Hour)
+
+	var buf bytes.Buffer
+	if err := dbs.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	saved := buf.Bytes()
+
+	// Test every database is restored at its own index
+	loaded := storage.NewDatabases(3)
+	if err := loaded.Load(bytes.NewReader(saved)); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if value, _ := loaded.DB(0).Get("config"); value != "blue" {
+		t.Errorf("Expected blue in database 0, got %v", value)
+	}
+	if _, hasTTL := loaded.DB(2).TTL("cache"); !hasTTL {
+		t.Error("Expected the key of database 2 to keep its expiry")
+	}
+
+	// Test keys saved beyond the number of databases are refused
+	if err := storage.NewDatabases(2).Load(bytes.NewReader(saved)); err == nil {
+		t.Error("Expected an error loading 3 databases into 2")
+	}
+}
+
+func TestGedisSelect(t *testing.T) {
+	g, err := gedis.NewGedis(gedis.Config{Address: "localhost:7711"})
+	if err != nil {
+		t.Fatalf("Failed to create Gedis instance: %v", err)
+	}
+
+	staging, err := g.Select(1)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if _, err := g.Select(16); !errors.Is(err, storage.ErrIndexOutOfRange) {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+
+	// Test the two instances address different databases of the same server
+	g.Set("color", "blue")
+	staging.Set("color", "green")
+	if value, _ := g.Get("color"); value != "blue" {
+		t.Errorf("Expected blue in database 0, got %v", value)
+	}
+
+	// Test SWAPDB exchanges them for both instances
+	if err := g.SwapDB(0, 1); err != nil {
+		t.Fatalf("SwapDB failed: %v", err)
+	}
+	if value, _ := g.Get("color"); value != "green" {
+		t.Errorf("Expected green after SwapDB, got %v", value)
+	}
+
+	if moved, err := staging.Move("color", 2); err != nil || !moved {
+		t.Errorf("Expected the key to be moved, got %v, %v", moved, err)
+	}
+	g.FlushAll()
+	if keys := g.Keys(); len(keys) != 0 {
+		t.Errorf("Expected no key after FlushAll, got %v", keys)
+	}
+}
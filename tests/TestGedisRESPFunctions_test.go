@@ -0,0 +1,217 @@
+package tests
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/gedis"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// reserveSeat books a seat of the hash at keys[0] for args[1], unless it is already taken
+func reserveSeat(db *storage.Database, keys []string, args []string) (interface{}, error) {
+	if len(keys) != 1 || len(args) != 2 {
+		return nil, errors.New("reserve_seat expects a key and two arguments")
+	}
+	if holder, taken := db.HGET(keys[0], args[0]); taken {
+		return nil, errors.New("seat " + args[0] + " is already reserved by " + holder.(string))
+	}
+	if _, err := db.HSET(keys[0], args[0], args[1]); err != nil {
+		return nil, err
+	}
+	length, _ := db.HLEN(keys[0])
+	return length, nil
+}
+
+func TestRESPFunctions(t *testing.T) {
+	handler := RESP.NewHandler(storage.NewDatabase())
+	client := handler.NewClient()
+
+	err := handler.RegisterFunction(RESP.Function{
+		Name:        "reserve_seat",
+		Library:     "booking",
+		Description: "Reserves a seat",
+		Handler:     reserveSeat,
+	})
+	if err != nil {
+		t.Fatalf("RegisterFunction failed: %v", err)
+	}
+	err = handler.RegisterFunction(RESP.Function{
+		Name:  "seats",
+		Flags: []string{RESP.FunctionNoWrites},
+		Handler: func(db *storage.Database, keys []string, args []string) (interface{}, error) {
+			fields, _ := db.HKEYS(keys[0])
+			return []interface{}{len(fields), nil, "done"}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegisterFunction failed: %v", err)
+	}
+
+	// Test registration errors
+	t.Run("Register", func(t *testing.T) {
+		if err := handler.RegisterFunction(RESP.Function{Name: "seats", Handler: reserveSeat}); err == nil {
+			t.Error("Expected registering a name twice to fail")
+		}
+		if err := handler.RegisterFunction(RESP.Function{Name: "other", Flags: []string{"fast"}, Handler: reserveSeat}); err == nil {
+			t.Error("Expected an unknown flag to be rejected")
+		}
+	})
+
+	// Test calling a function and its errors
+	t.Run("FCALL", func(t *testing.T) {
+		if reply := execute(client, "FCALL", "reserve_seat", "1", "concert", "A1", "alice"); reply != ":1\r\n" {
+			t.Errorf("Expected :1, got %q", reply)
+		}
+		if reply := execute(client, "FCALL", "reserve_seat", "1", "concert", "A1", "bob"); reply != "-ERR seat A1 is already reserved by alice\r\n" {
+			t.Errorf("Expected the function error, got %q", reply)
+		}
+		if reply := execute(client, "FCALL", "seats", "1", "concert"); reply != "*3\r\n:1\r\n$-1\r\n$4\r\ndone\r\n" {
+			t.Errorf("Expected the converted array, got %q", reply)
+		}
+		if reply := execute(client, "FCALL", "missing", "0"); reply != "-ERR Function not found\r\n" {
+			t.Errorf("Expected an unknown function error, got %q", reply)
+		}
+		if reply := execute(client, "FCALL", "seats", "2", "concert"); reply != "-ERR Number of keys can't be greater than number of args\r\n" {
+			t.Errorf("Expected a numkeys error, got %q", reply)
+		}
+		if reply := execute(client, "FCALL", "seats", "-1"); reply != "-ERR Number of keys can't be negative\r\n" {
+			t.Errorf("Expected a numkeys error, got %q", reply)
+		}
+	})
+
+	// Test FCALL_RO only runs no-writes functions
+	t.Run("FCALL_RO", func(t *testing.T) {
+		if reply := execute(client, "FCALL_RO", "seats", "1", "concert"); !strings.HasPrefix(reply, "*3\r\n") {
+			t.Errorf("Expected the read-only function to run, got %q", reply)
+		}
+		if reply := execute(client, "FCALL_RO", "reserve_seat", "1", "concert", "B2", "bob"); reply != "-ERR Can not execute a script with write flag using *_ro command.\r\n" {
+			t.Errorf("Expected the write function to be refused, got %q", reply)
+		}
+	})
+
+	// Test the declared keys are checked against the ACL, for writing unless the function is read-only
+	t.Run("ACL", func(t *testing.T) {
+		handler.ACL().SetUser("reader", "on", "nopass", "+@all", "%R~concert")
+		restricted := handler.NewClient()
+		execute(restricted, "AUTH", "reader", "any")
+
+		if reply := execute(restricted, "FCALL", "reserve_seat", "1", "concert", "C3", "carol"); reply != "-NOPERM No permissions to access a key\r\n" {
+			t.Errorf("Expected the write to be refused, got %q", reply)
+		}
+		if reply := execute(restricted, "FCALL", "seats", "1", "concert"); !strings.HasPrefix(reply, "*3\r\n") {
+			t.Errorf("Expected the read to be allowed, got %q", reply)
+		}
+
+		// FCALL is a write command, users without @write only run functions through FCALL_RO
+		handler.ACL().SetUser("scripter", "on", "nopass", "allkeys", "+@scripting", "-@write")
+		scripter := handler.NewClient()
+		execute(scripter, "AUTH", "scripter", "any")
+		if reply := execute(scripter, "FCALL", "reserve_seat", "1", "concert", "C3", "carol"); !strings.HasPrefix(reply, "-NOPERM") {
+			t.Errorf("Expected FCALL to be refused, got %q", reply)
+		}
+		if reply := execute(scripter, "FCALL_RO", "seats", "1", "concert"); !strings.HasPrefix(reply, "*3\r\n") {
+			t.Errorf("Expected FCALL_RO to be allowed, got %q", reply)
+		}
+	})
+
+	// Test FCALL is refused once the memory limit is reached, FCALL_RO isn't
+	t.Run("maxmemory", func(t *testing.T) {
+		db := handler.Databases().DB(0)
+		db.SetMaxMemory(1, storage.NoEviction)
+		defer db.SetMaxMemory(0, storage.NoEviction)
+
+		if reply := execute(client, "FCALL", "reserve_seat", "1", "concert", "E5", "eve"); !strings.HasPrefix(reply, "-OOM") {
+			t.Errorf("Expected an OOM error, got %q", reply)
+		}
+		if reply := execute(client, "FCALL_RO", "seats", "1", "concert"); !strings.HasPrefix(reply, "*3\r\n") {
+			t.Errorf("Expected FCALL_RO to run, got %q", reply)
+		}
+	})
+
+	// Test functions run inside a transaction
+	t.Run("MULTI", func(t *testing.T) {
+		execute(client, "MULTI")
+		execute(client, "FCALL", "reserve_seat", "1", "concert", "D4", "dave")
+		if reply := execute(client, "EXEC"); reply != "*1\r\n:2\r\n" {
+			t.Errorf("Expected the function reply, got %q", reply)
+		}
+	})
+
+	// Test FUNCTION LIST groups the functions by library
+	t.Run("FUNCTION LIST", func(t *testing.T) {
+		reply := execute(client, "FUNCTION", "LIST", "LIBRARYNAME", "book*")
+		want := "*1\r\n*6\r\n$12\r\nlibrary_name\r\n$7\r\nbooking\r\n$6\r\nengine\r\n$2\r\nGO\r\n" +
+			"$9\r\nfunctions\r\n*1\r\n*6\r\n$4\r\nname\r\n$12\r\nreserve_seat\r\n" +
+			"$11\r\ndescription\r\n$15\r\nReserves a seat\r\n$5\r\nflags\r\n*0\r\n"
+		if reply != want {
+			t.Errorf("Expected %q, got %q", want, reply)
+		}
+
+		reply = execute(client, "FUNCTION", "LIST")
+		if !strings.HasPrefix(reply, "*2\r\n") || !strings.Contains(reply, "$9\r\nno-writes\r\n") {
+			t.Errorf("Expected both libraries, got %q", reply)
+		}
+	})
+}
+
+func TestFunctionOperations(t *testing.T) {
+	g, err := gedis.NewGedis(gedis.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create Gedis instance: %v", err)
+	}
+
+	err = g.RegisterFunction(RESP.Function{Name: "embedded_reserve_seat", Handler: reserveSeat})
+	if err != nil {
+		t.Fatalf("RegisterFunction failed: %v", err)
+	}
+
+	result, err := g.FCALL("embedded_reserve_seat", []string{"functions:seats"}, "A1", "alice")
+	if err != nil || result != 1 {
+		t.Errorf("Expected 1, got %v, %v", result, err)
+	}
+	if _, err := g.FCALL("embedded_reserve_seat", []string{"functions:seats"}, "A1", "bob"); err == nil {
+		t.Error("Expected the seat to be taken")
+	}
+	if _, err := g.FCALL("missing", nil); err != RESP.ErrUnknownFunction {
+		t.Errorf("Expected ErrUnknownFunction, got %v", err)
+	}
+
+	// Functions run against the database selected by the Gedis they are called from
+	selected, err := g.Select(2)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	result, err = selected.FCALL("embedded_reserve_seat", []string{"functions:seats"}, "A1", "bob")
+	if err != nil || result != 1 {
+		t.Errorf("Expected the seat to be free in database 2, got %v, %v", result, err)
+	}
+	if holder, _ := g.HGET("functions:seats", "A1"); holder != "alice" {
+		t.Errorf("Expected database 0 to be left alone, got %v", holder)
+	}
+}
+
+func TestRESPFunctionsSelect(t *testing.T) {
+	handler := RESP.NewHandlerWithDatabases(storage.NewDatabases(4))
+	if err := handler.RegisterFunction(RESP.Function{Name: "reserve_seat", Handler: reserveSeat}); err != nil {
+		t.Fatalf("RegisterFunction failed: %v", err)
+	}
+	client := handler.NewClient()
+
+	// Test FCALL runs against the database selected by the client
+	execute(client, "SELECT", "3")
+	if reply := execute(client, "FCALL", "reserve_seat", "1", "concert", "A1", "alice"); reply != ":1\r\n" {
+		t.Errorf("Expected :1, got %q", reply)
+	}
+	if length, _ := handler.Databases().DB(3).HLEN("concert"); length != 1 {
+		t.Errorf("Expected the seat in database 3, got %d fields", length)
+	}
+	if length, _ := handler.Databases().DB(0).HLEN("concert"); length != 0 {
+		t.Errorf("Expected database 0 to be left alone, got %d fields", length)
+	}
+	if _, err := handler.CallFunction(4, "reserve_seat", []string{"concert"}, "A1", "bob"); err != storage.ErrIndexOutOfRange {
+		t.Errorf("Expected ErrIndexOutOfRange, got %v", err)
+	}
+}
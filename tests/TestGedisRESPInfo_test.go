@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// infoFields parses the "field:value" lines of an INFO reply
+func infoFields(reply string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(reply, "\r\n") {
+		if field, value, found := strings.Cut(line, ":"); found && !strings.HasPrefix(line, "#") {
+			fields[field] = value
+		}
+	}
+	return fields
+}
+
+func TestRESPInfo(t *testing.T) {
+	dbs := storage.NewDatabases(4)
+	handler := RESP.NewHandlerWithDatabases(dbs)
+	client := handler.NewClient()
+	handler.NewClient()
+
+	execute(client, "SET", "hit", "value")
+	execute(client, "SET", "short", "value", "PX", "1")
+	execute(client, "GET", "hit")
+	execute(client, "GET", "miss")
+	execute(client, "GET")
+	execute(client, "SELECT", "2")
+	execute(client, "SET", "session", "abc", "EX", "100")
+	execute(client, "RPUSH", "session", "x")
+	time.Sleep(5 * time.Millisecond)
+	dbs.DB(0).Get("short")
+
+	// Test the default sections are returned in the Redis layout
+	t.Run("Default", func(t *testing.T) {
+		reply := handler.Info()
+		for _, header := range []string{"# Server\r\n", "\r\n\r\n# Clients\r\n", "# Memory\r\n", "# Stats\r\n", "# Keyspace\r\n"} {
+			if !strings.Contains(reply, header) {
+				t.Errorf("Expected the reply to contain %q, got %q", header, reply)
+			}
+		}
+		if strings.Contains(reply, "# Commandstats") {
+			t.Error("Expected commandstats to be left out by default")
+		}
+
+		fields := infoFields(reply)
+		for field, want := range map[string]string{
+			"redis_version":              RESP.Version,
+			"connected_clients":          "2",
+			"total_connections_received": "2",
+			"total_commands_processed":   "7",
+			"keyspace_hits":              "1",
+			"keyspace_misses":            "2",
+			"expired_keys":               "1",
+			"evicted_keys":               "0",
+			"maxmemory_policy":           "noeviction",
+		} {
+			if fields[field] != want {
+				t.Errorf("Expected %s:%s, got %q", field, want, fields[field])
+			}
+		}
+		if fields["uptime_in_seconds"] == "" || fields["used_memory"] == "0" {
+			t.Errorf("Expected the uptime and the memory used, got %v", fields)
+		}
+		if !strings.HasPrefix(fields["db0"], "keys=1,expires=0,") || !strings.HasPrefix(fields["db2"], "keys=1,expires=1,avg_ttl=") {
+			t.Errorf("Expected db0 and db2 to be listed, got %q and %q", fields["db0"], fields["db2"])
+		}
+		if _, listed := fields["db1"]; listed {
+			t.Error("Expected the empty db1 to be left out")
+		}
+	})
+
+	// Test the sections can be selected, in any case
+	t.Run("Sections", func(t *testing.T) {
+		reply := execute(client, "INFO", "CLIENTS", "keyspace", "unknown")
+		if !strings.HasPrefix(reply, "$") || !strings.Contains(reply, "# Clients\r\n") || !strings.Contains(reply, "# Keyspace\r\n") {
+			t.Errorf("Expected the clients and keyspace sections, got %q", reply)
+		}
+		if strings.Contains(reply, "# Server") {
+			t.Errorf("Expected the other sections to be left out, got %q", reply)
+		}
+		if reply := handler.Info("everything"); !strings.Contains(reply, "# Commandstats\r\n") {
+			t.Errorf("Expected everything to include commandstats, got %q", reply)
+		}
+	})
+
+	// Test the calls, rejected calls and failed calls of every command are counted
+	t.Run("Commandstats", func(t *testing.T) {
+		fields := infoFields(handler.Info("commandstats"))
+		if !strings.HasPrefix(fields["cmdstat_get"], "calls=2,usec=") || !strings.HasSuffix(fields["cmdstat_get"], ",rejected_calls=1,failed_calls=0") {
+			t.Errorf("Expected 2 calls and a rejected one for GET, got %q", fields["cmdstat_get"])
+		}
+		if !strings.HasSuffix(fields["cmdstat_rpush"], ",rejected_calls=0,failed_calls=1") {
+			t.Errorf("Expected a failed call for RPUSH, got %q", fields["cmdstat_rpush"])
+		}
+		if _, listed := fields["cmdstat_del"]; listed {
+			t.Error("Expected the commands never called to be left out")
+		}
+	})
+}
+
+func TestRESPInfoInTransaction(t *testing.T) {
+	handler := RESP.NewHandlerWithDatabases(storage.NewDatabases(2))
+	client := handler.NewClient()
+	other := handler.NewClient()
+	execute(client, "SET", "key", "value")
+
+	// Test INFO reads the databases EXEC locked instead of waiting for their locks
+	execute(client, "MULTI")
+	execute(client, "INFO", "keyspace")
+	reply := waitReply(t, executeAsync(client, "EXEC"))
+	if !strings.Contains(reply, "db0:keys=1,") {
+		t.Errorf("Expected the keyspace of db0, got %q", reply)
+	}
+
+	// Test the databases are released for the other clients
+	if reply := waitReply(t, executeAsync(other, "GET", "key")); reply != "$5\r\nvalue\r\n" {
+		t.Errorf("Expected value, got %q", reply)
+	}
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestRESPListCommands(t *testing.T) {
+	client := RESP.NewHandler(storage.NewDatabase()).NewClient()
+
+	// Test LPUSH and RPUSH with several values
+	t.Run("LPUSH and RPUSH", func(t *testing.T) {
+		if reply := execute(client, "RPUSH", "queue", "b", "c"); reply != ":2\r\n" {
+			t.Errorf("Expected :2, got %q", reply)
+		}
+		if reply := execute(client, "LPUSH", "queue", "a"); reply != ":3\r\n" {
+			t.Errorf("Expected :3, got %q", reply)
+		}
+	})
+
+	// Test LRANGE, LLEN and LSET
+	t.Run("LRANGE, LLEN and LSET", func(t *testing.T) {
+		expected := "*3\r\n$1\r\na\r\n$1\r\nb\r\n$1\r\nc\r\n"
+		if reply := execute(client, "LRANGE", "queue", "0", "-1"); reply != expected {
+			t.Errorf("Expected %q, got %q", expected, reply)
+		}
+		if reply := execute(client, "LLEN", "queue"); reply != ":3\r\n" {
+			t.Errorf("Expected :3, got %q", reply)
+		}
+		if reply := execute(client, "LLEN", "missing"); reply != ":0\r\n" {
+			t.Errorf("Expected :0, got %q", reply)
+		}
+		if reply := execute(client, "LSET", "queue", "-1", "z"); reply != "+OK\r\n" {
+			t.Errorf("Expected +OK, got %q", reply)
+		}
+	})
+
+	// Test LSET resolves negative indexes and LRANGE returns a copy of the list, under the lock of the list
+	t.Run("Storage", func(t *testing.T) {
+		db := storage.NewDatabase()
+		db.RPush("letters", "a", "b", "c")
+		values, _ := db.LRange("letters", 0, -1)
+		if err := db.LSet("letters", -3, "x"); err != nil {
+			t.Fatalf("LSet failed: %v", err)
+		}
+		if values[0] != "a" {
+			t.Errorf("Expected the range read before LSET to be kept, got %v", values[0])
+		}
+		if values, _ := db.LRange("letters", 0, 0); values[0] != "x" {
+			t.Errorf("Expected the first element to be set, got %v", values[0])
+		}
+		if err := db.LSet("letters", -4, "y"); err == nil {
+			t.Error("Expected an index before the head of the list to be refused")
+		}
+	})
+
+	// Test LPOP and RPOP
+	t.Run("LPOP and RPOP", func(t *testing.T) {
+		if reply := execute(client, "LPOP", "queue"); reply != "$1\r\na\r\n" {
+			t.Errorf("Expected a, got %q", reply)
+		}
+		if reply := execute(client, "RPOP", "queue"); reply != "$1\r\nz\r\n" {
+			t.Errorf("Expected z, got %q", reply)
+		}
+		if reply := execute(client, "LPOP", "missing"); reply != "$-1\r\n" {
+			t.Errorf("Expected nil reply, got %q", reply)
+		}
+	})
+
+	// Test list commands against a key of another type
+	t.Run("Wrong Type", func(t *testing.T) {
+		execute(client, "SET", "plain", "value")
+		if reply := execute(client, "LPUSH", "plain", "a"); reply[0] != '-' {
+			t.Errorf("Expected an error, got %q", reply)
+		}
+	})
+}
+
+func TestRESPHashCommands(t *testing.T) {
+	client := RESP.NewHandler(storage.NewDatabase()).NewClient()
+
+	// Test HSET with several field-value pairs
+	t.Run("HSET", func(t *testing.T) {
+		if reply := execute(client, "HSET", "user", "name", "John", "age", "30"); reply != ":2\r\n" {
+			t.Errorf("Expected :2, got %q", reply)
+		}
+		if reply := execute(client, "HSET", "user", "age", "31", "city", "Paris"); reply != ":1\r\n" {
+			t.Errorf("Expected :1, got %q", reply)
+		}
+		if reply := execute(client, "HSET", "user", "name"); reply[0] != '-' {
+			t.Errorf("Expected an error for a missing value, got %q", reply)
+		}
+	})
+
+	// Test HGET, HLEN, HKEYS, HVALS and HGETALL
+	t.Run("Read Commands", func(t *testing.T) {
+		if reply := execute(client, "HGET", "user", "age"); reply != "$2\r\n31\r\n" {
+			t.Errorf("Expected 31, got %q", reply)
+		}
+		if reply := execute(client, "HGET", "user", "missing"); reply != "$-1\r\n" {
+			t.Errorf("Expected nil reply, got %q", reply)
+		}
+		if reply := execute(client, "HLEN", "user"); reply != ":3\r\n" {
+			t.Errorf("Expected :3, got %q", reply)
+		}
+
+		keys := "*3\r\n$3\r\nage\r\n$4\r\ncity\r\n$4\r\nname\r\n"
+		if reply := execute(client, "HKEYS", "user"); reply != keys {
+			t.Errorf("Expected %q, got %q", keys, reply)
+		}
+		vals := "*3\r\n$2\r\n31\r\n$5\r\nParis\r\n$4\r\nJohn\r\n"
+		if reply := execute(client, "HVALS", "user"); reply != vals {
+			t.Errorf("Expected %q, got %q", vals, reply)
+		}
+		all := "*6\r\n$3\r\nage\r\n$2\r\n31\r\n$4\r\ncity\r\n$5\r\nParis\r\n$4\r\nname\r\n$4\r\nJohn\r\n"
+		if reply := execute(client, "HGETALL", "user"); reply != all {
+			t.Errorf("Expected %q, got %q", all, reply)
+		}
+	})
+
+	// Test HDEL with several fields
+	t.Run("HDEL", func(t *testing.T) {
+		if reply := execute(client, "HDEL", "user", "age", "city", "missing"); reply != ":2\r\n" {
+			t.Errorf("Expected :2, got %q", reply)
+		}
+		if reply := execute(client, "HDEL", "nohash", "field"); reply != ":0\r\n" {
+			t.Errorf("Expected :0, got %q", reply)
+		}
+	})
+}
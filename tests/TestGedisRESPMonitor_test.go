@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestRESPMonitor(t *testing.T) {
+	handler := RESP.NewHandlerWithDatabases(storage.NewDatabases(2))
+	monitor := handler.NewClient()
+	client := handler.NewClient()
+	client.SetAddr("10.0.0.1:5000", "10.0.0.2:6379")
+
+	if reply := execute(monitor, "MONITOR"); reply != "+OK\r\n" {
+		t.Fatalf("Expected +OK, got %q", reply)
+	}
+
+	// expect checks the next line of the feed ends with the database, address and arguments
+	line := regexp.MustCompile(`^\+\d+\.\d{6} (.*)\r\n$`)
+	expect := func(t *testing.T, want string) {
+		t.Helper()
+		frame := nextOutput(t, monitor)
+		if match := line.FindStringSubmatch(frame); match == nil || match[1] != want {
+			t.Errorf("Expected a line ending with %q, got %q", want, frame)
+		}
+	}
+
+	// Test the commands are fed with their database and quoted arguments
+	t.Run("Feed", func(t *testing.T) {
+		execute(client, "SET", "greeting", "say \"hi\"\n")
+		expect(t, `[0 10.0.0.1:5000] "SET" "greeting" "say \"hi\"\n"`)
+
+		execute(client, "SELECT", "1")
+		expect(t, `[0 10.0.0.1:5000] "SELECT" "1"`)
+		execute(client, "GET", "caf\xc3\xa9")
+		expect(t, `[1 10.0.0.1:5000] "GET" "caf\xc3\xa9"`)
+	})
+
+	// Test the commands of a transaction are fed as they run, passwords are redacted
+	// and administrative commands left out
+	t.Run("Filtering", func(t *testing.T) {
+		execute(client, "MULTI")
+		execute(client, "INCR", "counter")
+		execute(client, "EXEC")
+		execute(client, "CONFIG", "GET", "maxmemory")
+		execute(client, "AUTH", "secret")
+		expect(t, `[1 10.0.0.1:5000] "MULTI"`)
+		expect(t, `[1 10.0.0.1:5000] "EXEC"`)
+		expect(t, `[1 10.0.0.1:5000] "INCR" "counter"`)
+		expect(t, `[1 10.0.0.1:5000] "AUTH" "(redacted)"`)
+
+		if info := execute(monitor, "CLIENT", "INFO"); !strings.Contains(info, " flags=O ") {
+			t.Errorf("Expected the monitor flag, got %q", info)
+		}
+		execute(client, "MULTI")
+		if reply := execute(client, "MONITOR"); reply != "-ERR Command not allowed inside a transaction\r\n" {
+			t.Errorf("Expected MONITOR to be refused in a transaction, got %q", reply)
+		}
+		execute(client, "DISCARD")
+		for len(monitor.Output()) > 0 {
+			<-monitor.Output()
+		}
+	})
+
+	// Test a monitor that stops reading is disconnected instead of slowing the other clients
+	t.Run("Slow Monitor", func(t *testing.T) {
+		for i := 0; i < 2000; i++ {
+			execute(client, "PING")
+		}
+		if !monitor.Killed() {
+			t.Error("Expected the slow monitor to be disconnected")
+		}
+
+		monitor.Close()
+		if reply := execute(client, "PING"); reply != "+PONG\r\n" {
+			t.Errorf("Expected +PONG once the monitor is gone, got %q", reply)
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	responses "github.com/GedisCaching/Gedis/responses"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestRESPKeyspaceNotifications(t *testing.T) {
+	db := storage.NewDatabase()
+	handler := RESP.NewHandler(db)
+	subscriber := handler.NewClient()
+	client := handler.NewClient()
+
+	execute(subscriber, "PSUBSCRIBE", "__key*__:*")
+	nextOutput(t, subscriber)
+
+	// expect checks the next push to be a notification on channel with payload
+	expect := func(t *testing.T, channel, payload string) {
+		t.Helper()
+		want := "*4\r\n$8\r\npmessage\r\n$10\r\n__key*__:*\r\n" +
+			responses.BulkStringMsg(channel) + responses.BulkStringMsg(payload)
+		if frame := nextOutput(t, subscriber); frame != want {
+			t.Errorf("Expected %q, got %q", want, frame)
+		}
+	}
+
+	// Test nothing is published until the events are enabled
+	t.Run("Disabled", func(t *testing.T) {
+		if handler.KeyspaceEvents() != "" {
+			t.Errorf("Expected notifications to be disabled, got %q", handler.KeyspaceEvents())
+		}
+		execute(client, "SET", "key", "value")
+		select {
+		case frame := <-subscriber.Output():
+			t.Errorf("Expected no notification, got %q", frame)
+		default:
+		}
+	})
+
+	// Test the flags are validated and normalized
+	t.Run("Flags", func(t *testing.T) {
+		if err := handler.SetKeyspaceEvents("Kq"); err == nil {
+			t.Error("Expected an unknown flag to be rejected")
+		}
+		if err := handler.SetKeyspaceEvents("Eg$lhzxeK"); err != nil {
+			t.Fatalf("SetKeyspaceEvents failed: %v", err)
+		}
+		if flags := handler.KeyspaceEvents(); flags != "AKE" {
+			t.Errorf("Expected AKE, got %q", flags)
+		}
+	})
+
+	// Test both the keyspace and keyevent channels receive a change
+	t.Run("Keyspace And Keyevent", func(t *testing.T) {
+		execute(client, "SET", "key", "value")
+		expect(t, "__keyspace@0__:key", "set")
+		expect(t, "__keyevent@0__:set", "key")
+
+		execute(client, "LPUSH", "list", "a")
+		expect(t, "__keyspace@0__:list", "lpush")
+		expect(t, "__keyevent@0__:lpush", "list")
+
+		execute(client, "RENAME", "key", "other")
+		expect(t, "__keyspace@0__:key", "rename_from")
+		expect(t, "__keyevent@0__:rename_from", "key")
+		expect(t, "__keyspace@0__:other", "rename_to")
+		expect(t, "__keyevent@0__:rename_to", "other")
+	})
+
+	// Test the classes filter the events
+	t.Run("Classes", func(t *testing.T) {
+		if err := handler.SetKeyspaceEvents("Kh"); err != nil {
+			t.Fatalf("SetKeyspaceEvents failed: %v", err)
+		}
+		execute(client, "SET", "key", "value")
+		execute(client, "HSET", "hash", "field", "value")
+		expect(t, "__keyspace@0__:hash", "hset")
+
+		execute(client, "DEL", "hash")
+		execute(client, "HDEL", "missing", "field")
+		select {
+		case frame := <-subscriber.Output():
+			t.Errorf("Expected no notification, got %q", frame)
+		default:
+		}
+	})
+
+	// Test lazily expired keys fire the expired event
+	t.Run("Expired", func(t *testing.T) {
+		if err := handler.SetKeyspaceEvents("Ex"); err != nil {
+			t.Fatalf("SetKeyspaceEvents failed: %v", err)
+		}
+		db.SetWithExpiry("temp", "value", 10*time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		if reply := execute(client, "GET", "temp"); reply != "$-1\r\n" {
+			t.Errorf("Expected the key to be expired, got %q", reply)
+		}
+		expect(t, "__keyevent@0__:expired", "temp")
+	})
+	// Test emptying a list or a hash deletes the key, after the event of the command
+	t.Run("Emptied", func(t *testing.T) {
+		if err := handler.SetKeyspaceEvents("Kgl"); err != nil {
+			t.Fatalf("SetKeyspaceEvents failed: %v", err)
+		}
+		execute(client, "RPUSH", "jobs", "a")
+		expect(t, "__keyspace@0__:jobs", "rpush")
+		execute(client, "LPOP", "jobs")
+		expect(t, "__keyspace@0__:jobs", "lpop")
+		expect(t, "__keyspace@0__:jobs", "del")
+
+		execute(client, "HSET", "hash", "field", "value")
+		execute(client, "HDEL", "hash", "field")
+		expect(t, "__keyspace@0__:hash", "del")
+
+		for _, key := range []string{"jobs", "hash"} {
+			if reply := execute(client, "EXISTS", key); reply != ":0\r\n" {
+				t.Errorf("Expected %s to be deleted, got %q", key, reply)
+			}
+		}
+	})
+
+	// Test a zero or negative EXPIRE deletes the key right away
+	t.Run("Expire In The Past", func(t *testing.T) {
+		if err := handler.SetKeyspaceEvents("Kg"); err != nil {
+			t.Fatalf("SetKeyspaceEvents failed: %v", err)
+		}
+		for _, seconds := range []string{"0", "-10"} {
+			execute(client, "SET", "session", "abc")
+			if reply := execute(client, "EXPIRE", "session", seconds); reply != ":1\r\n" {
+				t.Errorf("Expected :1 for EXPIRE %s, got %q", seconds, reply)
+			}
+			expect(t, "__keyspace@0__:session", "del")
+			if reply := execute(client, "EXISTS", "session"); reply != ":0\r\n" {
+				t.Errorf("Expected session to be deleted by EXPIRE %s, got %q", seconds, reply)
+			}
+		}
+	})
+}
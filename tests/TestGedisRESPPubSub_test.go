@@ -0,0 +1,207 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/gedis"
+	redis "github.com/GedisCaching/Gedis/server"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+// nextOutput returns the next frame pushed to a client, or fails after a second
+func nextOutput(t *testing.T, client *RESP.Client) string {
+	t.Helper()
+	select {
+	case frame := <-client.Output():
+		return frame
+	case <-time.After(time.Second):
+		t.Fatal("Expected a push message")
+		return ""
+	}
+}
+
+func TestRESPPubSub(t *testing.T) {
+	handler := RESP.NewHandler(storage.NewDatabase())
+	subscriber := handler.NewClient()
+	publisher := handler.NewClient()
+
+	// Test subscriptions are confirmed with push messages
+	t.Run("SUBSCRIBE", func(t *testing.T) {
+		if reply := execute(subscriber, "SUBSCRIBE", "news", "sports"); reply != "" {
+			t.Errorf("Expected no direct reply, got %q", reply)
+		}
+		expected := []string{
+			"*3\r\n$9\r\nsubscribe\r\n$4\r\nnews\r\n:1\r\n",
+			"*3\r\n$9\r\nsubscribe\r\n$6\r\nsports\r\n:2\r\n",
+		}
+		for _, want := range expected {
+			if frame := nextOutput(t, subscriber); frame != want {
+				t.Errorf("Expected %q, got %q", want, frame)
+			}
+		}
+
+		execute(subscriber, "PSUBSCRIBE", "n*")
+		if frame := nextOutput(t, subscriber); frame != "*3\r\n$10\r\npsubscribe\r\n$2\r\nn*\r\n:3\r\n" {
+			t.Errorf("Expected the psubscribe confirmation, got %q", frame)
+		}
+	})
+
+	// Test messages reach channel and pattern subscribers
+	t.Run("PUBLISH", func(t *testing.T) {
+		if reply := execute(publisher, "PUBLISH", "news", "hello"); reply != ":2\r\n" {
+			t.Errorf("Expected :2, got %q", reply)
+		}
+		if frame := nextOutput(t, subscriber); frame != "*3\r\n$7\r\nmessage\r\n$4\r\nnews\r\n$5\r\nhello\r\n" {
+			t.Errorf("Expected the message, got %q", frame)
+		}
+		if frame := nextOutput(t, subscriber); frame != "*4\r\n$8\r\npmessage\r\n$2\r\nn*\r\n$4\r\nnews\r\n$5\r\nhello\r\n" {
+			t.Errorf("Expected the pmessage, got %q", frame)
+		}
+		if reply := execute(publisher, "PUBLISH", "weather", "rain"); reply != ":0\r\n" {
+			t.Errorf("Expected :0, got %q", reply)
+		}
+	})
+
+	// Test RESP2 subscribers are limited to the subscribe family and PING
+	t.Run("Subscribe Mode", func(t *testing.T) {
+		if reply := execute(subscriber, "GET", "key"); !strings.HasPrefix(reply, "-ERR Can't execute 'get'") {
+			t.Errorf("Expected GET to be refused, got %q", reply)
+		}
+		if reply := execute(subscriber, "PING"); reply != "*2\r\n$4\r\npong\r\n$0\r\n\r\n" {
+			t.Errorf("Expected the pong message, got %q", reply)
+		}
+
+		client := handler.NewClient()
+		execute(client, "HELLO", "3")
+		execute(client, "SUBSCRIBE", "news")
+		if frame := nextOutput(t, client); frame != ">3\r\n$9\r\nsubscribe\r\n$4\r\nnews\r\n:1\r\n" {
+			t.Errorf("Expected a RESP3 push, got %q", frame)
+		}
+		if reply := execute(client, "GET", "key"); reply != "_\r\n" {
+			t.Errorf("Expected RESP3 subscribers to run any command, got %q", reply)
+		}
+		client.Close()
+	})
+
+	// Test the state reported by PUBSUB
+	t.Run("PUBSUB", func(t *testing.T) {
+		if reply := execute(publisher, "PUBSUB", "CHANNELS"); reply != "*2\r\n$4\r\nnews\r\n$6\r\nsports\r\n" {
+			t.Errorf("Expected news and sports, got %q", reply)
+		}
+		if reply := execute(publisher, "PUBSUB", "CHANNELS", "s*"); reply != "*1\r\n$6\r\nsports\r\n" {
+			t.Errorf("Expected sports, got %q", reply)
+		}
+		if reply := execute(publisher, "PUBSUB", "NUMSUB", "news", "none"); reply != "*4\r\n$4\r\nnews\r\n:1\r\n$4\r\nnone\r\n:0\r\n" {
+			t.Errorf("Expected the subscriber counts, got %q", reply)
+		}
+		if reply := execute(publisher, "PUBSUB", "NUMPAT"); reply != ":1\r\n" {
+			t.Errorf("Expected :1, got %q", reply)
+		}
+	})
+
+	// Test unsubscribing from every channel and pattern leaves subscribe mode
+	t.Run("UNSUBSCRIBE", func(t *testing.T) {
+		execute(subscriber, "UNSUBSCRIBE")
+		expected := []string{
+			"*3\r\n$11\r\nunsubscribe\r\n$4\r\nnews\r\n:2\r\n",
+			"*3\r\n$11\r\nunsubscribe\r\n$6\r\nsports\r\n:1\r\n",
+		}
+		for _, want := range expected {
+			if frame := nextOutput(t, subscriber); frame != want {
+				t.Errorf("Expected %q, got %q", want, frame)
+			}
+		}
+		execute(subscriber, "PUNSUBSCRIBE", "n*")
+		if frame := nextOutput(t, subscriber); frame != "*3\r\n$12\r\npunsubscribe\r\n$2\r\nn*\r\n:0\r\n" {
+			t.Errorf("Expected the punsubscribe confirmation, got %q", frame)
+		}
+		if reply := execute(subscriber, "GET", "key"); reply != "$-1\r\n" {
+			t.Errorf("Expected GET to run after unsubscribing, got %q", reply)
+		}
+		if reply := execute(publisher, "PUBSUB", "NUMPAT"); reply != ":0\r\n" {
+			t.Errorf("Expected :0, got %q", reply)
+		}
+	})
+
+	// Test subscriptions cannot be queued in a transaction
+	t.Run("MULTI", func(t *testing.T) {
+		execute(publisher, "MULTI")
+		if reply := execute(publisher, "SUBSCRIBE", "news"); reply != "-ERR Command not allowed inside a transaction\r\n" {
+			t.Errorf("Expected SUBSCRIBE to be refused, got %q", reply)
+		}
+		if reply := execute(publisher, "EXEC"); !strings.HasPrefix(reply, "-EXECABORT") {
+			t.Errorf("Expected EXECABORT, got %q", reply)
+		}
+	})
+
+	// Test subscribers that don't read their messages are disconnected
+	t.Run("Slow Subscriber", func(t *testing.T) {
+		slow := handler.NewClient()
+		execute(slow, "SUBSCRIBE", "flood")
+		for i := 0; i < 2000 && !slow.Killed(); i++ {
+			execute(publisher, "PUBLISH", "flood", "message")
+		}
+		if !slow.Killed() {
+			t.Error("Expected the slow subscriber to be killed")
+		}
+		slow.Close()
+	})
+}
+
+func TestPubSubOverNetwork(t *testing.T) {
+	g, err := gedis.NewGedis(gedis.Config{Address: "localhost:7401"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv, err := redis.NewServer(&redis.Config{Address: "localhost:7401"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(l)
+	defer srv.Close(context.Background())
+
+	conn, err := net.Dial("tcp", l.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	readFrame := func(size int) string {
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(reader, buf); err != nil {
+			t.Fatalf("Failed to read: %v", err)
+		}
+		return string(buf)
+	}
+
+	// The confirmation and the reply of a pipelined PING arrive in order
+	conn.Write([]byte("SUBSCRIBE invalidations\r\nPING\r\n"))
+	confirmation := "*3\r\n$9\r\nsubscribe\r\n$13\r\ninvalidations\r\n:1\r\n"
+	pong := "*2\r\n$4\r\npong\r\n$0\r\n\r\n"
+	if frame := readFrame(len(confirmation) + len(pong)); frame != confirmation+pong {
+		t.Errorf("Expected %q, got %q", confirmation+pong, frame)
+	}
+
+	// Messages published through the embedded API are pushed asynchronously
+	if receivers := g.PUBLISH("invalidations", "user:1"); receivers != 1 {
+		t.Errorf("Expected 1 receiver, got %d", receivers)
+	}
+	message := "*3\r\n$7\r\nmessage\r\n$13\r\ninvalidations\r\n$6\r\nuser:1\r\n"
+	if frame := readFrame(len(message)); frame != message {
+		t.Errorf("Expected %q, got %q", message, frame)
+	}
+}
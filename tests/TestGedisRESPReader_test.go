@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/GedisCaching/Gedis/RESP"
+)
+
+func TestRESPReader(t *testing.T) {
+	// Test several pipelined commands arriving in one segment
+	t.Run("Pipelined Commands", func(t *testing.T) {
+		input := "*3\r\n$3\r\nSET\r\n$1\r\na\r\n$1\r\n1\r\n" +
+			"*2\r\n$3\r\nGET\r\n$1\r\na\r\n" +
+			"PING\r\n"
+		reader := RESP.NewReader(strings.NewReader(input))
+
+		expected := [][]string{
+			{"SET", "a", "1"},
+			{"GET", "a"},
+			{"PING"},
+		}
+		for _, want := range expected {
+			args, err := reader.ReadCommand()
+			if err != nil {
+				t.Fatalf("ReadCommand failed: %v", err)
+			}
+			if !reflect.DeepEqual(args, want) {
+				t.Errorf("Expected %v, got %v", want, args)
+			}
+		}
+
+		if _, err := reader.ReadCommand(); err != io.EOF {
+			t.Errorf("Expected io.EOF after last command, got %v", err)
+		}
+	})
+
+	// Test a command larger than a single read, delivered one byte at a time
+	t.Run("Large Command", func(t *testing.T) {
+		value := strings.Repeat("x", 100000)
+		input := "*3\r\n$3\r\nSET\r\n$3\r\nbig\r\n$100000\r\n" + value + "\r\n"
+		reader := RESP.NewReader(iotest.OneByteReader(strings.NewReader(input)))
+
+		args, err := reader.ReadCommand()
+		if err != nil {
+			t.Fatalf("ReadCommand failed: %v", err)
+		}
+		if len(args) != 3 || args[2] != value {
+			t.Errorf("Large value was not reassembled, got %d arguments", len(args))
+		}
+	})
+
+	// Test binary-safe bulk strings containing CRLF
+	t.Run("Binary Safe Values", func(t *testing.T) {
+		reader := RESP.NewReader(strings.NewReader("*2\r\n$4\r\nECHO\r\n$4\r\na\r\nb\r\n"))
+		args, err := reader.ReadCommand()
+		if err != nil {
+			t.Fatalf("ReadCommand failed: %v", err)
+		}
+		if args[1] != "a\r\nb" {
+			t.Errorf("Expected %q, got %q", "a\r\nb", args[1])
+		}
+	})
+
+	// Test inline commands and empty lines
+	t.Run("Inline Commands", func(t *testing.T) {
+		reader := RESP.NewReader(strings.NewReader("\r\nSET key value EX 30\n"))
+		args, err := reader.ReadCommand()
+		if err != nil {
+			t.Fatalf("ReadCommand failed: %v", err)
+		}
+		want := []string{"SET", "key", "value", "EX", "30"}
+		if !reflect.DeepEqual(args, want) {
+			t.Errorf("Expected %v, got %v", want, args)
+		}
+	})
+
+	// Test quoted inline arguments
+	t.Run("Quoted Inline Commands", func(t *testing.T) {
+		reader := RESP.NewReader(strings.NewReader("SET greeting \"hello world\"\r\n"))
+		args, err := reader.ReadCommand()
+		if err != nil {
+			t.Fatalf("ReadCommand failed: %v", err)
+		}
+		want := []string{"SET", "greeting", "hello world"}
+		if !reflect.DeepEqual(args, want) {
+			t.Errorf("Expected %v, got %v", want, args)
+		}
+
+		var protoErr *RESP.ProtocolError
+		_, err = RESP.NewReader(strings.NewReader("SET key \"value\r\n")).ReadCommand()
+		if !errors.As(err, &protoErr) || err.Error() != "Protocol error: unbalanced quotes in request" {
+			t.Errorf("Expected an unbalanced quotes protocol error, got %v", err)
+		}
+	})
+
+	// Test protocol errors and truncated commands
+	t.Run("Malformed Input", func(t *testing.T) {
+		var protoErr *RESP.ProtocolError
+
+		_, err := RESP.NewReader(strings.NewReader("*1\r\n+PING\r\n")).ReadCommand()
+		if !errors.As(err, &protoErr) {
+			t.Errorf("Expected protocol error, got %v", err)
+		}
+
+		_, err = RESP.NewReader(strings.NewReader("*2\r\n$3\r\nGET\r\n")).ReadCommand()
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("Expected io.ErrUnexpectedEOF, got %v", err)
+		}
+	})
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{"", nil},
+		{"  GET   key  ", []string{"GET", "key"}},
+		{`SET key "hello world"`, []string{"SET", "key", "hello world"}},
+		{`SET key "a\nb\r\tc"`, []string{"SET", "key", "a\nb\r\tc"}},
+		{`SET key "\x00\x41\xff"`, []string{"SET", "key", "\x00A\xff"}},
+		{`SET key "say \"hi\""`, []string{"SET", "key", `say "hi"`}},
+		{`SET key 'it\'s \n literal'`, []string{"SET", "key", `it's \n literal`}},
+		{`SET key ""`, []string{"SET", "key", ""}},
+		{`SET key pre"fix"`, []string{"SET", "key", "prefix"}},
+	}
+
+	for _, tt := range tests {
+		got, err := RESP.SplitArgs(tt.line)
+		if err != nil {
+			t.Errorf("SplitArgs(%q) failed: %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SplitArgs(%q) = %q, expected %q", tt.line, got, tt.want)
+		}
+	}
+
+	for _, line := range []string{`SET key "value`, `SET key 'value`, `SET key "a"b`, `SET key 'a'b`} {
+		if _, err := RESP.SplitArgs(line); err != RESP.ErrUnbalancedQuotes {
+			t.Errorf("SplitArgs(%q): expected ErrUnbalancedQuotes, got %v", line, err)
+		}
+	}
+}
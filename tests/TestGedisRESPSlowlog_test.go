@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	redis "github.com/GedisCaching/Gedis/server"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestRESPSlowlog(t *testing.T) {
+	handler := RESP.NewHandler(storage.NewDatabase())
+	client := handler.NewClient()
+	client.SetAddr("10.0.0.1:5000", "10.0.0.2:6379")
+	execute(client, "CLIENT", "SETNAME", "worker")
+
+	// Test nothing is logged under the default threshold
+	t.Run("Threshold", func(t *testing.T) {
+		execute(client, "SET", "key", "value")
+		if reply := execute(client, "SLOWLOG", "LEN"); reply != ":0\r\n" {
+			t.Errorf("Expected an empty slow log, got %q", reply)
+		}
+	})
+
+	// Test the entries hold the command, its client and its duration, the newest first
+	t.Run("GET", func(t *testing.T) {
+		handler.SetSlowlog(0, 3)
+		execute(client, "SET", "key", "value")
+		execute(client, "GET", "key")
+
+		entries := handler.Slowlog(-1)
+		if len(entries) < 2 {
+			t.Fatalf("Expected the commands to be logged, got %v", entries)
+		}
+		if args := strings.Join(entries[1].Args, " "); args != "SET key value" {
+			t.Errorf("Expected SET key value, got %q", args)
+		}
+		entry := entries[0]
+		if entry.ClientAddr != "10.0.0.1:5000" || entry.ClientName != "worker" || entry.ID <= entries[1].ID {
+			t.Errorf("Unexpected entry %+v", entry)
+		}
+		if time.Since(entry.Time) > time.Minute || entry.Duration < 0 {
+			t.Errorf("Unexpected time %v and duration %v", entry.Time, entry.Duration)
+		}
+
+		reply := execute(client, "SLOWLOG", "GET", "1")
+		if !strings.HasPrefix(reply, "*1\r\n*6\r\n:") || !strings.Contains(reply, "*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n$13\r\n10.0.0.1:5000\r\n$6\r\nworker\r\n") {
+			t.Errorf("Expected the last entry, got %q", reply)
+		}
+		if reply := execute(client, "SLOWLOG", "GET", "-2"); reply != "-ERR count should be greater than or equal to -1\r\n" {
+			t.Errorf("Expected a count error, got %q", reply)
+		}
+	})
+
+	// Test the log keeps its last entries and can be reset
+	t.Run("LEN RESET", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			execute(client, "PING")
+		}
+		if reply := execute(client, "SLOWLOG", "LEN"); reply != ":3\r\n" {
+			t.Errorf("Expected 3 entries, got %q", reply)
+		}
+		if reply := execute(client, "SLOWLOG", "RESET"); reply != "+OK\r\n" {
+			t.Errorf("Expected +OK, got %q", reply)
+		}
+		// The reset itself is logged once it ran
+		if entries := handler.Slowlog(-1); len(entries) != 1 || strings.Join(entries[0].Args, " ") != "SLOWLOG RESET" {
+			t.Errorf("Expected only SLOWLOG RESET to be left, got %v", entries)
+		}
+	})
+
+	// Test long arguments are truncated and passwords redacted
+	t.Run("Arguments", func(t *testing.T) {
+		handler.SetSlowlog(0, 10)
+		execute(client, "SET", "big", strings.Repeat("x", 200))
+		args := make([]string, 40)
+		for i := range args {
+			args[i] = "k"
+		}
+		execute(client, "DEL", args...)
+		execute(client, "AUTH", "secret")
+
+		entries := handler.Slowlog(3)
+		if args := entries[2].Args; args[2] != strings.Repeat("x", 128)+"... (72 more bytes)" {
+			t.Errorf("Expected the value to be truncated, got %q", args[2])
+		}
+		if args := entries[1].Args; len(args) != 32 || args[31] != "... (10 more arguments)" {
+			t.Errorf("Expected 32 arguments, got %d ending with %q", len(args), args[len(args)-1])
+		}
+		if args := strings.Join(entries[0].Args, " "); args != "AUTH (redacted)" {
+			t.Errorf("Expected the password to be redacted, got %q", args)
+		}
+	})
+
+	// Test a negative threshold disables the log
+	t.Run("Disabled", func(t *testing.T) {
+		handler.SetSlowlog(-1, 10)
+		execute(client, "SLOWLOG", "RESET")
+		execute(client, "PING")
+		if reply := execute(client, "SLOWLOG", "LEN"); reply != ":0\r\n" {
+			t.Errorf("Expected nothing to be logged, got %q", reply)
+		}
+	})
+}
+
+func TestSlowlogConfig(t *testing.T) {
+	config := &redis.Config{}
+	for value, want := range map[string]time.Duration{"0": time.Nanosecond, "2500": 2500 * time.Microsecond, "-1": -1} {
+		if err := config.Set("slowlog-log-slower-than", value); err != nil {
+			t.Fatalf("Set %s failed: %v", value, err)
+		}
+		if config.SlowlogLogSlowerThan != want {
+			t.Errorf("Expected %v for %s, got %v", want, value, config.SlowlogLogSlowerThan)
+		}
+	}
+	if err := config.Set("slowlog-max-len", "0"); err == nil {
+		t.Error("Expected a length of 0 to be refused")
+	}
+}
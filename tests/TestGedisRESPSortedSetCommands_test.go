@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestRESPSortedSetCommands(t *testing.T) {
+	client := RESP.NewHandler(storage.NewDatabase()).NewClient()
+
+	// Test ZADD with several score-member pairs
+	t.Run("ZADD", func(t *testing.T) {
+		if reply := execute(client, "ZADD", "board", "10", "alice", "20", "bob", "15", "carol"); reply != ":3\r\n" {
+			t.Errorf("Expected :3, got %q", reply)
+		}
+		if reply := execute(client, "ZADD", "board", "25.5", "alice", "5", "dave"); reply != ":1\r\n" {
+			t.Errorf("Expected :1, got %q", reply)
+		}
+		if reply := execute(client, "ZADD", "board", "high", "eve"); reply[0] != '-' {
+			t.Errorf("Expected an error for an invalid score, got %q", reply)
+		}
+	})
+
+	// Test ZRANGE with and without scores
+	t.Run("ZRANGE", func(t *testing.T) {
+		expected := "*2\r\n$4\r\ndave\r\n$5\r\ncarol\r\n"
+		if reply := execute(client, "ZRANGE", "board", "0", "1"); reply != expected {
+			t.Errorf("Expected %q, got %q", expected, reply)
+		}
+
+		expected = "*4\r\n$3\r\nbob\r\n$2\r\n20\r\n$5\r\nalice\r\n$4\r\n25.5\r\n"
+		if reply := execute(client, "ZRANGE", "board", "-2", "-1", "WITHSCORES"); reply != expected {
+			t.Errorf("Expected %q, got %q", expected, reply)
+		}
+	})
+
+	// Test ZRANK
+	t.Run("ZRANK", func(t *testing.T) {
+		if reply := execute(client, "ZRANK", "board", "alice"); reply != ":3\r\n" {
+			t.Errorf("Expected :3, got %q", reply)
+		}
+		if reply := execute(client, "ZRANK", "board", "nobody"); reply != "$-1\r\n" {
+			t.Errorf("Expected nil reply, got %q", reply)
+		}
+	})
+
+	// Test ZADD refuses a key of another type, leaving it untouched
+	t.Run("Wrong Type", func(t *testing.T) {
+		execute(client, "SET", "greeting", "hello")
+		if reply := execute(client, "ZADD", "greeting", "1", "alice"); reply != "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n" {
+			t.Errorf("Expected WRONGTYPE, got %q", reply)
+		}
+		if reply := execute(client, "GET", "greeting"); reply != "$5\r\nhello\r\n" {
+			t.Errorf("Expected the string to be kept, got %q", reply)
+		}
+		if reply := execute(client, "ZRANGE", "greeting", "0", "-1"); reply[0] != '-' {
+			t.Errorf("Expected WRONGTYPE, got %q", reply)
+		}
+	})
+
+	// Test sorted sets are removed by DEL
+	t.Run("DEL", func(t *testing.T) {
+		execute(client, "DEL", "board")
+		if reply := execute(client, "EXISTS", "board"); reply != ":0\r\n" {
+			t.Errorf("Expected the sorted set to be deleted, got %q", reply)
+		}
+	})
+}
+
+func TestRESPNumericCommands(t *testing.T) {
+	handler := RESP.NewHandler(storage.NewDatabase())
+	client := handler.NewClient()
+
+	// Test INCR and DECR
+	t.Run("INCR and DECR", func(t *testing.T) {
+		if reply := execute(client, "INCR", "visits"); reply != ":1\r\n" {
+			t.Errorf("Expected :1, got %q", reply)
+		}
+		execute(client, "SET", "stock", "10")
+		if reply := execute(client, "DECR", "stock"); reply != ":9\r\n" {
+			t.Errorf("Expected :9, got %q", reply)
+		}
+		execute(client, "SET", "name", "gedis")
+		if reply := execute(client, "INCR", "name"); reply[0] != '-' {
+			t.Errorf("Expected an error for a non-integer value, got %q", reply)
+		}
+	})
+
+	// Test INCR refuses a key of another type
+	t.Run("Wrong Type", func(t *testing.T) {
+		execute(client, "ZADD", "ranking", "1", "alice")
+		if reply := execute(client, "INCR", "ranking"); reply != "-WRONGTYPE Operation against a key holding the wrong kind of value\r\n" {
+			t.Errorf("Expected WRONGTYPE, got %q", reply)
+		}
+		if reply := execute(client, "ZRANK", "ranking", "alice"); reply != ":0\r\n" {
+			t.Errorf("Expected the sorted set to be kept, got %q", reply)
+		}
+	})
+
+	// Test an increment leaving the range of a 64 bit integer is refused, keeping the value
+	t.Run("Overflow", func(t *testing.T) {
+		execute(client, "SET", "max", "9223372036854775807")
+		if reply := execute(client, "INCR", "max"); reply != "-ERR increment or decrement would overflow\r\n" {
+			t.Errorf("Expected an overflow error, got %q", reply)
+		}
+		if reply := execute(client, "GET", "max"); reply != "$19\r\n9223372036854775807\r\n" {
+			t.Errorf("Expected the value to be kept, got %q", reply)
+		}
+		execute(client, "SET", "min", "-9223372036854775808")
+		if reply := execute(client, "DECR", "min"); reply != "-ERR increment or decrement would overflow\r\n" {
+			t.Errorf("Expected an overflow error, got %q", reply)
+		}
+	})
+
+	// Test concurrent increments are never lost
+	t.Run("Concurrent INCR", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				execute(handler.NewClient(), "INCR", "hits")
+			}()
+		}
+		wg.Wait()
+
+		if reply := execute(client, "GET", "hits"); reply != "$2\r\n50\r\n" {
+			t.Errorf("Expected 50, got %q", reply)
+		}
+	})
+}
@@ -0,0 +1,224 @@
+package tests
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/GedisCaching/Gedis/RESP"
+	"github.com/GedisCaching/Gedis/storage"
+)
+
+func TestRESPTransactions(t *testing.T) {
+	handler := RESP.NewHandler(storage.NewDatabase())
+	client := handler.NewClient()
+
+	// Test queued commands run on EXEC and return their replies in order
+	t.Run("MULTI EXEC", func(t *testing.T) {
+		execute(client, "SET", "stock", "10")
+		if reply := execute(client, "MULTI"); reply != "+OK\r\n" {
+			t.Fatalf("Expected +OK, got %q", reply)
+		}
+		for _, cmd := range [][]string{{"DECR", "stock"}, {"RPUSH", "orders", "order:1"}, {"GET", "stock"}} {
+			if reply := execute(client, cmd[0], cmd[1:]...); reply != "+QUEUED\r\n" {
+				t.Errorf("Expected +QUEUED for %v, got %q", cmd, reply)
+			}
+		}
+		if reply := execute(client, "GET", "stock"); reply != "+QUEUED\r\n" {
+			t.Errorf("Expected the read to be queued, got %q", reply)
+		}
+
+		expected := "*4\r\n:9\r\n:1\r\n$1\r\n9\r\n$1\r\n9\r\n"
+		if reply := execute(client, "EXEC"); reply != expected {
+			t.Errorf("Expected %q, got %q", expected, reply)
+		}
+	})
+
+	// Test runtime errors don't stop the other commands of the transaction
+	t.Run("Runtime Errors", func(t *testing.T) {
+		execute(client, "MULTI")
+		execute(client, "LPUSH", "stock", "x")
+		execute(client, "INCR", "stock")
+		reply := execute(client, "EXEC")
+		if !strings.HasPrefix(reply, "*2\r\n-WRONGTYPE") || !strings.HasSuffix(reply, ":10\r\n") {
+			t.Errorf("Expected WRONGTYPE then :10, got %q", reply)
+		}
+	})
+
+	// Test commands that cannot be queued abort the transaction
+	t.Run("EXECABORT", func(t *testing.T) {
+		execute(client, "MULTI")
+		execute(client, "INCR", "stock")
+		if reply := execute(client, "GET"); !strings.HasPrefix(reply, "-ERR wrong number of arguments") {
+			t.Errorf("Expected an arity error at queue time, got %q", reply)
+		}
+		if reply := execute(client, "NOSUCHCOMMAND"); !strings.HasPrefix(reply, "-ERR unknown command") {
+			t.Errorf("Expected an unknown command error, got %q", reply)
+		}
+		expected := "-EXECABORT Transaction discarded because of previous errors.\r\n"
+		if reply := execute(client, "EXEC"); reply != expected {
+			t.Errorf("Expected %q, got %q", expected, reply)
+		}
+		if reply := execute(client, "GET", "stock"); reply != "$2\r\n10\r\n" {
+			t.Errorf("Expected the queued INCR not to run, got %q", reply)
+		}
+	})
+
+	// Test DISCARD and misplaced transaction commands
+	t.Run("DISCARD", func(t *testing.T) {
+		if reply := execute(client, "EXEC"); reply != "-ERR EXEC without MULTI\r\n" {
+			t.Errorf("Expected EXEC without MULTI, got %q", reply)
+		}
+		if reply := execute(client, "DISCARD"); reply != "-ERR DISCARD without MULTI\r\n" {
+			t.Errorf("Expected DISCARD without MULTI, got %q", reply)
+		}
+
+		execute(client, "MULTI")
+		if reply := execute(client, "MULTI"); reply != "-ERR MULTI calls can not be nested\r\n" {
+			t.Errorf("Expected a nesting error, got %q", reply)
+		}
+		execute(client, "DEL", "stock")
+		if reply := execute(client, "DISCARD"); reply != "+OK\r\n" {
+			t.Errorf("Expected +OK, got %q", reply)
+		}
+		if reply := execute(client, "GET", "stock"); reply != "$2\r\n10\r\n" {
+			t.Errorf("Expected the discarded DEL not to run, got %q", reply)
+		}
+	})
+
+	// Test no other client interleaves with a running transaction
+	t.Run("Atomicity", func(t *testing.T) {
+		const workers = 20
+		const rounds = 50
+		execute(client, "SET", "stock", strconv.Itoa(workers*rounds))
+		execute(client, "DEL", "orders")
+
+		var wg sync.WaitGroup
+		errs := make(chan string, workers)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				c := handler.NewClient()
+				for r := 0; r < rounds; r++ {
+					id := strconv.Itoa(w*rounds + r)
+					execute(c, "MULTI")
+					execute(c, "SET", "last", id)
+					execute(c, "DECR", "stock")
+					execute(c, "RPUSH", "orders", id)
+					execute(c, "GET", "last")
+					reply := execute(c, "EXEC")
+					if !strings.HasSuffix(reply, "\r\n"+id+"\r\n") {
+						errs <- reply
+						return
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+		close(errs)
+
+		for reply := range errs {
+			t.Errorf("Another client interleaved with the transaction: %q", reply)
+		}
+		if reply := execute(client, "GET", "stock"); reply != "$1\r\n0\r\n" {
+			t.Errorf("Expected the stock to be 0, got %q", reply)
+		}
+		if reply := execute(client, "LLEN", "orders"); reply != ":"+strconv.Itoa(workers*rounds)+"\r\n" {
+			t.Errorf("Expected %d orders, got %q", workers*rounds, reply)
+		}
+	})
+}
+
+func TestRESPWatch(t *testing.T) {
+	handler := RESP.NewHandler(storage.NewDatabase())
+	client := handler.NewClient()
+	other := handler.NewClient()
+
+	// Test EXEC runs when the watched keys didn't change
+	t.Run("Unchanged Keys", func(t *testing.T) {
+		execute(client, "SET", "stock", "10")
+		if reply := execute(client, "WATCH", "stock", "missing"); reply != "+OK\r\n" {
+			t.Fatalf("Expected +OK, got %q", reply)
+		}
+		execute(other, "GET", "stock")
+		execute(client, "MULTI")
+		execute(client, "DECR", "stock")
+		if reply := execute(client, "EXEC"); reply != "*1\r\n:9\r\n" {
+			t.Errorf("Expected *1 :9, got %q", reply)
+		}
+	})
+
+	// Test writes, deletions and expiry of a watched key abort EXEC
+	t.Run("Changed Keys", func(t *testing.T) {
+		writes := [][]string{
+			{"SET", "stock", "20"},
+			{"INCR", "stock"},
+			{"DEL", "stock"},
+			{"RPUSH", "missing", "x"},
+			{"EXPIRE", "stock", "100"},
+		}
+		for _, write := range writes {
+			execute(client, "SET", "stock", "10")
+			execute(client, "WATCH", "stock", "missing")
+			execute(other, write[0], write[1:]...)
+
+			execute(client, "MULTI")
+			execute(client, "SET", "stock", "0")
+			if reply := execute(client, "EXEC"); reply != "*-1\r\n" {
+				t.Errorf("Expected a nil reply after %v, got %q", write, reply)
+			}
+			execute(client, "DEL", "missing")
+		}
+
+		execute(client, "SET", "ephemeral", "x", "PX", "20")
+		execute(client, "WATCH", "ephemeral")
+		time.Sleep(30 * time.Millisecond)
+		execute(client, "MULTI")
+		execute(client, "GET", "stock")
+		if reply := execute(client, "EXEC"); reply != "*-1\r\n" {
+			t.Errorf("Expected a nil reply after the key expired, got %q", reply)
+		}
+	})
+
+	// Test the watches are cleared by EXEC, DISCARD and UNWATCH
+	t.Run("UNWATCH", func(t *testing.T) {
+		execute(client, "WATCH", "stock")
+		execute(other, "SET", "stock", "1")
+		if reply := execute(client, "UNWATCH"); reply != "+OK\r\n" {
+			t.Errorf("Expected +OK, got %q", reply)
+		}
+		execute(client, "MULTI")
+		execute(client, "GET", "stock")
+		if reply := execute(client, "EXEC"); reply != "*1\r\n$1\r\n1\r\n" {
+			t.Errorf("Expected EXEC to run after UNWATCH, got %q", reply)
+		}
+
+		// A failed EXEC clears the watches too
+		execute(client, "WATCH", "stock")
+		execute(other, "SET", "stock", "2")
+		execute(client, "MULTI")
+		execute(client, "EXEC")
+		execute(client, "MULTI")
+		if reply := execute(client, "WATCH", "stock"); reply != "-ERR WATCH inside MULTI is not allowed\r\n" {
+			t.Errorf("Expected WATCH to be refused inside MULTI, got %q", reply)
+		}
+		execute(client, "GET", "stock")
+		if reply := execute(client, "EXEC"); reply != "*1\r\n$1\r\n2\r\n" {
+			t.Errorf("Expected EXEC to run with no watched key, got %q", reply)
+		}
+	})
+
+	// Test RESP3 clients get a null for an aborted transaction
+	t.Run("RESP3", func(t *testing.T) {
+		execute(client, "HELLO", "3")
+		execute(client, "WATCH", "stock")
+		execute(other, "DEL", "stock")
+		execute(client, "MULTI")
+		if reply := execute(client, "EXEC"); reply != "_\r\n" {
+			t.Errorf("Expected a null reply, got %q", reply)
+		}
+	})
+}
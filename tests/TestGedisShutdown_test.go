@@ -0,0 +1,143 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	redis "github.com/GedisCaching/Gedis/server"
+)
+
+// serveTCP serves srv on a random local port and returns its address
+func serveTCP(t *testing.T, srv *redis.Server) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(l)
+	return l.Addr().String()
+}
+
+// dialCommand connects to address and sends a command, returning the connection and its reader
+func dialCommand(t *testing.T, address, command string) (net.Conn, *bufio.Reader) {
+	t.Helper()
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetDeadline(time.Now().Add(2 * time.Second))
+	conn.Write([]byte(command))
+	return conn, bufio.NewReader(conn)
+}
+
+func TestShutdown(t *testing.T) {
+	// Test Close lets blocked and idle clients finish, then refuses new connections
+	t.Run("Close", func(t *testing.T) {
+		srv, err := redis.NewServer(&redis.Config{Address: "localhost:7601"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		address := serveTCP(t, srv)
+
+		blocked, blockedReader := dialCommand(t, address, "BLPOP never 0\r\n")
+		defer blocked.Close()
+		idle, idleReader := dialCommand(t, address, "PING\r\n")
+		defer idle.Close()
+		if line, _ := idleReader.ReadString('\n'); line != "+PONG\r\n" {
+			t.Fatalf("Expected PONG, got %q", line)
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := srv.Close(ctx); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+
+		// The blocked command replies as if it timed out before the connection is closed
+		if line, _ := blockedReader.ReadString('\n'); line != "*-1\r\n" {
+			t.Errorf("Expected a null array, got %q", line)
+		}
+		if _, err := blockedReader.ReadByte(); err != io.EOF {
+			t.Errorf("Expected the connection to be closed, got %v", err)
+		}
+		if _, err := idleReader.ReadByte(); err != io.EOF {
+			t.Errorf("Expected the connection to be closed, got %v", err)
+		}
+
+		select {
+		case <-srv.Done():
+		default:
+			t.Error("Expected Done to be closed")
+		}
+		if _, err := net.Dial("tcp", address); err == nil {
+			t.Error("Expected new connections to be refused")
+		}
+	})
+
+	// Test SHUTDOWN saves the database, which the next server loads
+	t.Run("SHUTDOWN", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "dump.gdb")
+		srv, err := redis.NewServer(&redis.Config{Address: "localhost:7602", DBFilename: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		address := serveTCP(t, srv)
+
+		conn, reader := dialCommand(t, address, "SHUTDOWN NOSAVE SAVE\r\nRPUSH list a b\r\nSET key value\r\nSHUTDOWN\r\n")
+		defer conn.Close()
+		for _, expected := range []string{"-ERR syntax error\r\n", ":2\r\n", "+OK\r\n"} {
+			if line, _ := reader.ReadString('\n'); line != expected {
+				t.Errorf("Expected %q, got %q", expected, line)
+			}
+		}
+		if _, err := reader.ReadByte(); err != io.EOF {
+			t.Errorf("Expected the connection to be closed without a reply, got %v", err)
+		}
+
+		select {
+		case <-srv.Done():
+		case <-time.After(time.Second):
+			t.Fatal("Expected the server to shut down")
+		}
+
+		restored, err := redis.NewServer(&redis.Config{Address: "localhost:7603", DBFilename: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value, _ := restored.GetDB().Get("key"); value != "value" {
+			t.Errorf("Expected the key to be restored, got %v", value)
+		}
+		if values, _ := restored.GetDB().LRange("list", 0, -1); len(values) != 2 || values[1] != "b" {
+			t.Errorf("Expected the list to be restored, got %v", values)
+		}
+	})
+
+	// Test a failed save aborts the shutdown, unless it is forced
+	t.Run("Save Error", func(t *testing.T) {
+		srv, err := redis.NewServer(&redis.Config{Address: "localhost:7604"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		address := serveTCP(t, srv)
+
+		conn, reader := dialCommand(t, address, "SHUTDOWN SAVE\r\nPING\r\n")
+		defer conn.Close()
+		for _, expected := range []string{"-ERR Errors trying to SHUTDOWN. Check logs.\r\n", "+PONG\r\n"} {
+			if line, _ := reader.ReadString('\n'); line != expected {
+				t.Errorf("Expected %q, got %q", expected, line)
+			}
+		}
+
+		// NOW drops the connections without waiting, so nothing is pipelined after it
+		conn.Write([]byte("SHUTDOWN SAVE FORCE NOW\r\n"))
+		if _, err := reader.ReadByte(); err != io.EOF {
+			t.Errorf("Expected the connection to be closed, got %v", err)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/GedisCaching/Gedis/gedis"
+	"github.com/GedisCaching/Gedis/storage"
 )
 
 func TestSortedSetOperations(t *testing.T) {
@@ -111,3 +112,26 @@ func TestSortedSetOperations(t *testing.T) {
 		}
 	})
 }
+
+func TestSortedSetWrongType(t *testing.T) {
+	g, err := gedis.NewGedis(gedis.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create Gedis instance: %v", err)
+	}
+
+	// ZAdd leaves a key of another type alone, ZAddChecked reports it
+	g.Set("zset:string", "value")
+	if added := g.ZAdd("zset:string", map[string]float64{"member": 1}); added != 0 {
+		t.Errorf("Expected nothing added, got %d", added)
+	}
+	if _, err := g.ZAddChecked("zset:string", map[string]float64{"member": 1}); err != storage.ErrWrongType {
+		t.Errorf("Expected ErrWrongType, got %v", err)
+	}
+	if value, _ := g.Get("zset:string"); value != "value" {
+		t.Errorf("Expected the string to be kept, got %v", value)
+	}
+
+	if added, err := g.ZAddChecked("zset:checked", map[string]float64{"a": 1, "b": 2}); err != nil || added != 2 {
+		t.Errorf("Expected 2 members added, got %d, %v", added, err)
+	}
+}
@@ -0,0 +1,237 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	redis "github.com/GedisCaching/Gedis/server"
+)
+
+// testCA is a self-signed certificate authority generated for a test
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Gedis Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue signs a new certificate, returning the PEM certificate and private key
+func (ca *testCA) issue(t *testing.T, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// clientTLS returns a client configuration trusting ca, with an optional client certificate
+func (ca *testCA) clientTLS(t *testing.T, certPEM, keyPEM []byte) *tls.Config {
+	t.Helper()
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.pem)
+	config := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+	if certPEM != nil {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// serveTLS serves srv on a random local port and returns its address
+func serveTLS(t *testing.T, srv *redis.Server) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go srv.Serve(tls.NewListener(l, srv.TLSConfig()))
+	return l.Addr().String()
+}
+
+// pingTLS sends PING over a TLS connection and returns the reply
+func pingTLS(address string, config *tls.Config) (string, error) {
+	conn, err := tls.Dial("tcp", address, config)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("PING\r\n")); err != nil {
+		return "", err
+	}
+	return bufio.NewReader(conn).ReadString('\n')
+}
+
+func TestTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+	serverCert, serverKey := ca.issue(t, x509.ExtKeyUsageServerAuth)
+
+	certFile := filepath.Join(dir, "server.crt")
+	keyFile := filepath.Join(dir, "server.key")
+	caFile := filepath.Join(dir, "ca.crt")
+	writeFile(t, certFile, serverCert)
+	writeFile(t, keyFile, serverKey)
+	writeFile(t, caFile, ca.pem)
+
+	// Test invalid TLS options are rejected
+	t.Run("Config Validation", func(t *testing.T) {
+		configs := []*redis.Config{
+			{Address: "localhost:7201", TLSCertFile: certFile},
+			{Address: "localhost:7201", TLSAddress: "localhost:7202"},
+			{Address: "localhost:7201", TLSCertFile: certFile, TLSKeyFile: keyFile, TLSAuthClients: true},
+			{Address: "localhost:7201", TLSCertFile: filepath.Join(dir, "missing.crt"), TLSKeyFile: keyFile},
+		}
+		for _, config := range configs {
+			if _, err := redis.NewServer(config); err == nil {
+				t.Errorf("Expected an error for %+v", config)
+			}
+		}
+	})
+
+	// Test commands are served over TLS
+	t.Run("TLS Connection", func(t *testing.T) {
+		srv, err := redis.NewServer(&redis.Config{Address: "localhost:7203", TLSCertFile: certFile, TLSKeyFile: keyFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer srv.Close(context.Background())
+		address := serveTLS(t, srv)
+
+		reply, err := pingTLS(address, ca.clientTLS(t, nil, nil))
+		if err != nil || reply != "+PONG\r\n" {
+			t.Errorf("Expected +PONG, got %q, %v", reply, err)
+		}
+	})
+
+	// Test client certificates are required and verified with mutual TLS
+	t.Run("Mutual TLS", func(t *testing.T) {
+		srv, err := redis.NewServer(&redis.Config{Address: "localhost:7204", TLSCertFile: certFile, TLSKeyFile: keyFile,
+			TLSCACertFile: caFile, TLSAuthClients: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer srv.Close(context.Background())
+		address := serveTLS(t, srv)
+
+		if reply, err := pingTLS(address, ca.clientTLS(t, nil, nil)); err == nil {
+			t.Errorf("Expected the connection to fail without a client certificate, got %q", reply)
+		}
+
+		otherCert, otherKey := newTestCA(t).issue(t, x509.ExtKeyUsageClientAuth)
+		if reply, err := pingTLS(address, ca.clientTLS(t, otherCert, otherKey)); err == nil {
+			t.Errorf("Expected the connection to fail with an untrusted certificate, got %q", reply)
+		}
+
+		clientCert, clientKey := ca.issue(t, x509.ExtKeyUsageClientAuth)
+		reply, err := pingTLS(address, ca.clientTLS(t, clientCert, clientKey))
+		if err != nil || reply != "+PONG\r\n" {
+			t.Errorf("Expected +PONG with a client certificate, got %q, %v", reply, err)
+		}
+	})
+
+	// Test certificates are reloaded without restarting the server
+	t.Run("Reload", func(t *testing.T) {
+		srv, err := redis.NewServer(&redis.Config{Address: "localhost:7205", TLSCertFile: certFile, TLSKeyFile: keyFile})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer srv.Close(context.Background())
+		address := serveTLS(t, srv)
+
+		renewedCA := newTestCA(t)
+		renewedCert, renewedKey := renewedCA.issue(t, x509.ExtKeyUsageServerAuth)
+		writeFile(t, certFile, renewedCert)
+		writeFile(t, keyFile, renewedKey)
+
+		// The previous certificate stays in use until the reload
+		if _, err := pingTLS(address, ca.clientTLS(t, nil, nil)); err != nil {
+			t.Errorf("Expected the previous certificate before the reload, got %v", err)
+		}
+
+		if err := srv.ReloadTLS(); err != nil {
+			t.Fatalf("Failed to reload: %v", err)
+		}
+		reply, err := pingTLS(address, renewedCA.clientTLS(t, nil, nil))
+		if err != nil || reply != "+PONG\r\n" {
+			t.Errorf("Expected +PONG with the renewed certificate, got %q, %v", reply, err)
+		}
+
+		// An invalid file keeps the current certificate
+		writeFile(t, keyFile, []byte("not a key"))
+		if err := srv.ReloadTLS(); err == nil {
+			t.Error("Expected an error reloading an invalid key")
+		}
+		if _, err := pingTLS(address, renewedCA.clientTLS(t, nil, nil)); err != nil {
+			t.Errorf("Expected the renewed certificate after a failed reload, got %v", err)
+		}
+	})
+}
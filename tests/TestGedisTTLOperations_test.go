@@ -76,4 +76,22 @@ func TestTTLOperations(t *testing.T) {
 			t.Error("Key should have expired after DEXPIRE")
 		}
 	})
+	// Test hashes and lists are deleted once expired, like strings
+	t.Run("Expired Hashes And Lists", func(t *testing.T) {
+		g.HSET("profile", "name", "ada")
+		g.RPush("queue", "stale")
+		g.DEXPIRE("profile", 10*time.Millisecond)
+		g.DEXPIRE("queue", 10*time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+
+		if value, exists := g.HGET("profile", "name"); exists {
+			t.Errorf("Expected the expired hash to be gone, got %v", value)
+		}
+		if length, _ := g.RPush("queue", "fresh"); length != 1 {
+			t.Errorf("Expected RPush to start a new list, got length %d", length)
+		}
+		if ttl, exists := g.TTL("queue"); !exists || ttl != 0 {
+			t.Errorf("Expected the new list to have no expiry, got %v, %v", ttl, exists)
+		}
+	})
 }
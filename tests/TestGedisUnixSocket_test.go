@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	redis "github.com/GedisCaching/Gedis/server"
+)
+
+func TestUnixSocket(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gedis.sock")
+
+	// Test the configuration needs a way to accept connections
+	t.Run("Config Validation", func(t *testing.T) {
+		if _, err := redis.NewServer(&redis.Config{Address: "localhost:7301", DisableTCP: true}); err == nil {
+			t.Error("Expected an error disabling TCP without a Unix socket")
+		}
+		if _, err := redis.NewServer(&redis.Config{Address: "localhost:7301", UnixSocketPerm: 0700}); err == nil {
+			t.Error("Expected an error setting permissions without a Unix socket")
+		}
+	})
+
+	// Test a regular file is never replaced by the socket
+	t.Run("Existing File", func(t *testing.T) {
+		file := filepath.Join(dir, "regular")
+		writeFile(t, file, []byte("data"))
+		srv, err := redis.NewServer(&redis.Config{Address: "localhost:7302", UnixSocket: file, DisableTCP: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := srv.ListenAndServe(); err == nil {
+			t.Error("Expected an error listening on a regular file")
+		}
+	})
+
+	// Test commands are served over the socket and the file is removed on Close
+	t.Run("Serve", func(t *testing.T) {
+		// A socket left by a previous run is replaced
+		stale, err := net.Listen("unix", path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		stale.Close()
+
+		srv, err := redis.NewServer(&redis.Config{Address: "localhost:7303", UnixSocket: path, UnixSocketPerm: 0660, DisableTCP: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		done := make(chan error, 1)
+		go func() { done <- srv.ListenAndServe() }()
+
+		var conn net.Conn
+		for i := 0; i < 100; i++ {
+			if conn, err = net.Dial("unix", path); err == nil {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		defer conn.Close()
+
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0660 {
+			t.Errorf("Expected permissions 0660, got %v, %v", info.Mode().Perm(), err)
+		}
+
+		conn.Write([]byte("SET key value\r\nGET key\r\n"))
+		reader := bufio.NewReader(conn)
+		for _, expected := range []string{"+OK\r\n", "$5\r\n", "value\r\n"} {
+			if line, err := reader.ReadString('\n'); err != nil || line != expected {
+				t.Errorf("Expected %q, got %q, %v", expected, line, err)
+			}
+		}
+
+		srv.Close(context.Background())
+		select {
+		case err := <-done:
+			if !errors.Is(err, redis.ErrServerClosed) {
+				t.Errorf("Expected ErrServerClosed, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("ListenAndServe didn't return after Close")
+		}
+
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("Expected the socket file to be removed, got %v", err)
+		}
+	})
+}